@@ -0,0 +1,163 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var (
+	_ datasource.DataSource                   = &ADSPDataSource{}
+	_ datasource.DataSourceWithValidateConfig = &ADSPDataSource{}
+	_ datasource.DataSourceWithConfigure      = &ADSPDataSource{}
+)
+
+// adspDMARCPolicies maps each ADSP outbound signing practice to the DMARC
+// policy closest to it, which is suggested when retiring the record.
+var adspDMARCPolicies = map[string]string{
+	"unknown":     "none",
+	"all":         "quarantine",
+	"discardable": "reject",
+}
+
+func NewADSPDataSource() datasource.DataSource {
+	return &ADSPDataSource{}
+}
+
+// ADSPDataSource defines the data source implementation.
+type ADSPDataSource struct {
+	providerData *providerData
+}
+
+// ADSPDataSourceModel describes the data source data model.
+type ADSPDataSourceModel struct {
+	Record       types.String `tfsdk:"record"`
+	ChangeTicket types.String `tfsdk:"change_ticket"`
+	Practice     types.String `tfsdk:"practice"`
+}
+
+func (d *ADSPDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_adsp"
+}
+
+func (d *ADSPDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Validates a legacy DKIM Author Domain Signing Practices (RFC 5617) TXT record published at `_adsp._domainkey.<domain>`. " +
+			"ADSP is obsolete and superseded by DMARC, so every record produces a warning; use this data source to find records to retire.",
+
+		Attributes: map[string]schema.Attribute{
+			"record": schema.StringAttribute{
+				MarkdownDescription: "The ADSP TXT record content to validate (e.g., `dkim=discardable`)",
+				Required:            true,
+			},
+			"change_ticket": changeTicketAttribute(),
+			"practice": schema.StringAttribute{
+				MarkdownDescription: "The outbound signing practice (dkim tag): `unknown`, `all`, or `discardable`",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (d *ADSPDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	d.providerData = configureProviderData(req, resp)
+}
+
+func (d *ADSPDataSource) ValidateConfig(ctx context.Context, req datasource.ValidateConfigRequest, resp *datasource.ValidateConfigResponse) {
+	defer d.providerData.applyWarningSettings(&resp.Diagnostics)
+
+	var data ADSPDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Skip validation if record is unknown (e.g., depends on another resource)
+	if data.Record.IsUnknown() {
+		return
+	}
+
+	if _, err := parseADSPRecord(data.Record.ValueString()); err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("record"),
+			summaryADSPInvalidRecord,
+			fmt.Sprintf("The ADSP record is malformed: %s\n\nRecord: %s", err.Error(), data.Record.ValueString()),
+		)
+	}
+}
+
+func (d *ADSPDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	defer d.providerData.applyWarningSettings(&resp.Diagnostics)
+
+	var data ADSPDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data.ChangeTicket = d.providerData.checkChangeTicket(data.ChangeTicket, &resp.Diagnostics)
+
+	practice, err := parseADSPRecord(data.Record.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			summaryADSPInvalidRecord,
+			fmt.Sprintf("The ADSP record is malformed: %s", err.Error()),
+		)
+		return
+	}
+	data.Practice = types.StringValue(practice)
+
+	resp.Diagnostics.AddWarning(
+		summaryADSPObsolete,
+		fmt.Sprintf("ADSP (RFC 5617) was moved to Historic status in 2013 and receivers no longer act on it. "+
+			"Publish a DMARC record instead (dkim=%s corresponds most closely to p=%s) and remove the _adsp._domainkey record.",
+			practice, adspDMARCPolicies[practice]),
+	)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// parseADSPRecord parses an ADSP record as described in RFC 5617 section 4.2.1
+// and returns its outbound signing practice, lowercased. Unknown tags are
+// ignored, as the RFC requires.
+func parseADSPRecord(record string) (string, error) {
+	practice := ""
+	seen := map[string]bool{}
+	for _, field := range strings.Split(record, ";") {
+		field = strings.TrimSpace(field)
+		// A trailing separator is allowed
+		if field == "" {
+			continue
+		}
+		name, value, found := strings.Cut(field, "=")
+		if !found {
+			return "", fmt.Errorf("invalid tag %q (missing '=')", field)
+		}
+		name = strings.TrimSpace(name)
+		if seen[name] {
+			return "", fmt.Errorf("duplicate %s tag", name)
+		}
+		seen[name] = true
+		if name != "dkim" {
+			continue
+		}
+
+		practice = strings.ToLower(strings.TrimSpace(value))
+		if _, ok := adspDMARCPolicies[practice]; !ok {
+			return "", fmt.Errorf("invalid dkim value %q (expected unknown, all, or discardable)", strings.TrimSpace(value))
+		}
+	}
+
+	if practice == "" {
+		return "", fmt.Errorf("missing required dkim tag")
+	}
+	return practice, nil
+}
@@ -0,0 +1,35 @@
+package provider
+
+import "testing"
+
+func TestParseADSPRecord(t *testing.T) {
+	tests := []struct {
+		name    string
+		record  string
+		want    string
+		wantErr bool
+	}{
+		{name: "all", record: "dkim=all", want: "all"},
+		{name: "discardable", record: "dkim=discardable;", want: "discardable"},
+		{name: "unknown", record: "dkim=unknown", want: "unknown"},
+		{name: "uppercase value", record: "dkim=ALL", want: "all"},
+		{name: "extension tag", record: "x=1; dkim = discardable", want: "discardable"},
+		{name: "missing dkim tag", record: "x=1", wantErr: true},
+		{name: "invalid practice", record: "dkim=strict", wantErr: true},
+		{name: "duplicate dkim tag", record: "dkim=all; dkim=unknown", wantErr: true},
+		{name: "missing separator", record: "dkim", wantErr: true},
+		{name: "empty", record: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseADSPRecord(tt.record)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseADSPRecord() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseADSPRecord() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
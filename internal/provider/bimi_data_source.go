@@ -0,0 +1,339 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/emersion/go-msgauth/dmarc"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var (
+	_ datasource.DataSource                   = &BIMIDataSource{}
+	_ datasource.DataSourceWithValidateConfig = &BIMIDataSource{}
+	_ datasource.DataSourceWithConfigure      = &BIMIDataSource{}
+)
+
+func NewBIMIDataSource() datasource.DataSource {
+	return &BIMIDataSource{}
+}
+
+// BIMIDataSource defines the data source implementation.
+type BIMIDataSource struct {
+	providerData *providerData
+}
+
+// BIMIDataSourceModel describes the data source data model.
+type BIMIDataSourceModel struct {
+	Record        types.String `tfsdk:"record"`
+	DMARCRecord   types.String `tfsdk:"dmarc_record"`
+	Domain        types.String `tfsdk:"domain"`
+	AllowedHosts  types.List   `tfsdk:"allowed_hosts"`
+	ChangeTicket  types.String `tfsdk:"change_ticket"`
+	LogoURL       types.String `tfsdk:"logo_url"`
+	AuthorityURL  types.String `tfsdk:"authority_url"`
+	LogoHost      types.String `tfsdk:"logo_host"`
+	AuthorityHost types.String `tfsdk:"authority_host"`
+	IsDeclined    types.Bool   `tfsdk:"is_declined"`
+}
+
+// bimiRecord holds a parsed BIMI assertion record. Empty URLs mean the tag is
+// absent or empty.
+type bimiRecord struct {
+	LogoURL      string
+	AuthorityURL string
+}
+
+func (d *BIMIDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_bimi"
+}
+
+func (d *BIMIDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Validates a BIMI (Brand Indicators for Message Identification) TXT record published at `<selector>._bimi.<domain>`. " +
+			"If the record is invalid, terraform plan will fail with a specific error message.",
+
+		Attributes: map[string]schema.Attribute{
+			"record": schema.StringAttribute{
+				MarkdownDescription: "The BIMI TXT record content to validate (e.g., `v=BIMI1; l=https://example.com/logo.svg; a=https://example.com/vmc.pem`)",
+				Required:            true,
+			},
+			"dmarc_record": schema.StringAttribute{
+				MarkdownDescription: "The DMARC record of the same domain. When set, an error is raised unless it enforces a policy strong enough for BIMI",
+				Optional:            true,
+			},
+			"domain": schema.StringAttribute{
+				MarkdownDescription: "The domain the record is published for (e.g., `example.com`). When set, a warning is raised for logo and certificate URLs hosted outside it and outside `allowed_hosts`",
+				Optional:            true,
+			},
+			"allowed_hosts": schema.ListAttribute{
+				MarkdownDescription: "Other domains trusted to host the logo and certificate, such as a brand CDN (e.g., `[\"brand-cdn.example.net\"]`). Their subdomains are trusted too",
+				Optional:            true,
+				ElementType:         types.StringType,
+			},
+			"change_ticket": changeTicketAttribute(),
+			"logo_url": schema.StringAttribute{
+				MarkdownDescription: "The SVG logo URL (l tag). Null when the tag is absent or empty",
+				Computed:            true,
+			},
+			"authority_url": schema.StringAttribute{
+				MarkdownDescription: "The Verified Mark Certificate URL (a tag). Null when the tag is absent or empty",
+				Computed:            true,
+			},
+			"logo_host": schema.StringAttribute{
+				MarkdownDescription: "The lowercased host of `logo_url`. Null when the tag is absent or empty",
+				Computed:            true,
+			},
+			"authority_host": schema.StringAttribute{
+				MarkdownDescription: "The lowercased host of `authority_url`. Null when the tag is absent or empty",
+				Computed:            true,
+			},
+			"is_declined": schema.BoolAttribute{
+				MarkdownDescription: "True if the record has no logo, declining to take part in BIMI",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (d *BIMIDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	d.providerData = configureProviderData(req, resp)
+}
+
+func (d *BIMIDataSource) ValidateConfig(ctx context.Context, req datasource.ValidateConfigRequest, resp *datasource.ValidateConfigResponse) {
+	defer d.providerData.applyWarningSettings(&resp.Diagnostics)
+
+	var data BIMIDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Values that are unknown (e.g., depend on another resource) are skipped
+	if !data.Record.IsUnknown() {
+		if _, err := parseBIMIRecord(data.Record.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("record"),
+				summaryBIMIInvalidRecord,
+				fmt.Sprintf("The BIMI record is malformed: %s\n\nRecord: %s", err.Error(), data.Record.ValueString()),
+			)
+		}
+	}
+
+	if !data.DMARCRecord.IsUnknown() && !data.DMARCRecord.IsNull() {
+		if _, err := dmarc.Parse(data.DMARCRecord.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("dmarc_record"),
+				summaryDMARCInvalidRecord,
+				fmt.Sprintf("The DMARC record is malformed: %s", err.Error()),
+			)
+		}
+	}
+
+	if !data.Domain.IsUnknown() && !data.Domain.IsNull() {
+		if err := checkHostname(data.Domain.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("domain"),
+				summaryDomainInvalid,
+				fmt.Sprintf("The domain is invalid: %s", err.Error()),
+			)
+		}
+	}
+	if data.AllowedHosts.IsUnknown() || data.AllowedHosts.IsNull() {
+		return
+	}
+	for i, element := range data.AllowedHosts.Elements() {
+		host, ok := element.(types.String)
+		if !ok || host.IsUnknown() || host.IsNull() {
+			continue
+		}
+		if err := checkHostname(host.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("allowed_hosts").AtListIndex(i),
+				summaryDomainInvalid,
+				fmt.Sprintf("The allowed host is invalid: %s", err.Error()),
+			)
+		}
+	}
+}
+
+func (d *BIMIDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	defer d.providerData.applyWarningSettings(&resp.Diagnostics)
+
+	var data BIMIDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data.ChangeTicket = d.providerData.checkChangeTicket(data.ChangeTicket, &resp.Diagnostics)
+
+	rec, err := parseBIMIRecord(data.Record.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			summaryBIMIInvalidRecord,
+			fmt.Sprintf("The BIMI record is malformed: %s", err.Error()),
+		)
+		return
+	}
+
+	data.LogoURL = types.StringNull()
+	if rec.LogoURL != "" {
+		data.LogoURL = types.StringValue(rec.LogoURL)
+	}
+	data.AuthorityURL = types.StringNull()
+	if rec.AuthorityURL != "" {
+		data.AuthorityURL = types.StringValue(rec.AuthorityURL)
+	}
+	data.LogoHost = bimiURLHost(rec.LogoURL)
+	data.AuthorityHost = bimiURLHost(rec.AuthorityURL)
+	data.IsDeclined = types.BoolValue(rec.LogoURL == "")
+
+	var allowed []string
+	if !data.AllowedHosts.IsNull() {
+		resp.Diagnostics.Append(data.AllowedHosts.ElementsAs(ctx, &allowed, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+	if !data.Domain.IsNull() {
+		allowed = append(allowed, data.Domain.ValueString())
+	}
+	// Without a domain or allow-list there is nothing to compare against
+	if len(allowed) > 0 {
+		for _, u := range []struct {
+			tag  string
+			host types.String
+		}{{tag: "l", host: data.LogoHost}, {tag: "a", host: data.AuthorityHost}} {
+			if !u.host.IsNull() && !isAllowedBIMIHost(u.host.ValueString(), allowed) {
+				resp.Diagnostics.AddWarning(
+					summaryBIMIHostOutsideDomain,
+					fmt.Sprintf("The %s= URL is hosted on %s, which is neither within %s nor an allowed host. "+
+						"Whoever controls that host controls the logo shown next to your mail; host it on a domain you control, or add it to allowed_hosts.",
+						u.tag, u.host.ValueString(), strings.Join(allowed, ", ")),
+				)
+			}
+		}
+	}
+
+	if !data.DMARCRecord.IsNull() && rec.LogoURL != "" {
+		parsed, err := dmarc.Parse(data.DMARCRecord.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddError(
+				summaryDMARCInvalidRecord,
+				fmt.Sprintf("The DMARC record is malformed: %s", err.Error()),
+			)
+			return
+		}
+		if reason := bimiDMARCWeakness(parsed); reason != "" {
+			resp.Diagnostics.AddError(
+				summaryBIMIDMARCPolicyWeak,
+				fmt.Sprintf("Mailbox providers only display BIMI logos for domains with an enforcing DMARC policy, but %s, "+
+					"so the BIMI record would be ignored. Use p=quarantine or p=reject with pct=100 and no sp=none.", reason),
+			)
+			return
+		}
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// parseBIMIRecord parses a BIMI assertion record. Both the l and a tags may
+// be empty, which is how a domain declines to publish a logo.
+func parseBIMIRecord(record string) (*bimiRecord, error) {
+	tags := splitDMARCTags(record)
+	if len(tags) == 0 || tags[0].Name != "v" || tags[0].Value != "BIMI1" {
+		return nil, fmt.Errorf("record must begin with v=BIMI1")
+	}
+
+	rec := &bimiRecord{}
+	seen := make(map[string]bool)
+	for _, t := range tags[1:] {
+		if seen[t.Name] {
+			return nil, fmt.Errorf("duplicate %s tag", t.Name)
+		}
+		seen[t.Name] = true
+
+		switch t.Name {
+		case "l":
+			if t.Value == "" {
+				continue
+			}
+			if err := checkBIMIURL(t.Value, ".svg"); err != nil {
+				return nil, fmt.Errorf("invalid logo URL (l tag): %w", err)
+			}
+			rec.LogoURL = t.Value
+		case "a":
+			if t.Value == "" {
+				continue
+			}
+			if err := checkBIMIURL(t.Value, ".pem"); err != nil {
+				return nil, fmt.Errorf("invalid authority URL (a tag): %w", err)
+			}
+			rec.AuthorityURL = t.Value
+		}
+	}
+	return rec, nil
+}
+
+// checkBIMIURL checks that raw is an https URL whose path has the given file
+// extension.
+func checkBIMIURL(raw, extension string) error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return fmt.Errorf("%q is not a valid URL", raw)
+	}
+	if u.Scheme != "https" {
+		return fmt.Errorf("%q must use https", raw)
+	}
+	if !strings.HasSuffix(strings.ToLower(u.Path), extension) {
+		return fmt.Errorf("%q must point to a %s file", raw, extension)
+	}
+	return nil
+}
+
+// bimiURLHost returns the lowercased host of a URL from a BIMI record, or
+// null for an empty URL. The URL has already been checked by parseBIMIRecord.
+func bimiURLHost(raw string) types.String {
+	if raw == "" {
+		return types.StringNull()
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return types.StringNull()
+	}
+	return types.StringValue(strings.ToLower(strings.TrimSuffix(u.Hostname(), ".")))
+}
+
+// isAllowedBIMIHost reports whether host is within one of the allowed
+// domains.
+func isAllowedBIMIHost(host string, allowed []string) bool {
+	for _, domain := range allowed {
+		if isWithinDomain(host, domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// bimiDMARCWeakness explains why a DMARC policy does not qualify for BIMI, or
+// returns an empty string when it does.
+func bimiDMARCWeakness(rec *dmarc.Record) string {
+	switch {
+	case rec.Policy == dmarc.PolicyNone:
+		return "the DMARC record uses p=none"
+	case rec.Percent != nil && *rec.Percent < 100:
+		return fmt.Sprintf("the DMARC record only applies its policy to %d%% of failing mail (pct=%d)", *rec.Percent, *rec.Percent)
+	case rec.SubdomainPolicy == dmarc.PolicyNone:
+		return "the DMARC record uses sp=none for subdomains"
+	}
+	return ""
+}
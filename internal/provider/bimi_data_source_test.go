@@ -0,0 +1,128 @@
+package provider
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/emersion/go-msgauth/dmarc"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+func TestParseBIMIRecord(t *testing.T) {
+	tests := []struct {
+		name          string
+		record        string
+		wantLogo      string
+		wantAuthority string
+		wantErr       bool
+	}{
+		{
+			name:          "logo and certificate",
+			record:        "v=BIMI1; l=https://example.com/logo.svg; a=https://example.com/vmc.pem",
+			wantLogo:      "https://example.com/logo.svg",
+			wantAuthority: "https://example.com/vmc.pem",
+		},
+		{name: "logo only", record: "v=BIMI1; l=https://example.com/brand/Logo.SVG", wantLogo: "https://example.com/brand/Logo.SVG"},
+		{name: "declined", record: "v=BIMI1; l=; a=;"},
+		{name: "http logo", record: "v=BIMI1; l=http://example.com/logo.svg", wantErr: true},
+		{name: "png logo", record: "v=BIMI1; l=https://example.com/logo.png", wantErr: true},
+		{name: "relative logo", record: "v=BIMI1; l=/logo.svg", wantErr: true},
+		{name: "certificate not pem", record: "v=BIMI1; l=https://example.com/logo.svg; a=https://example.com/vmc.crt", wantErr: true},
+		{name: "wrong version", record: "v=BIMI2; l=https://example.com/logo.svg", wantErr: true},
+		{name: "duplicate logo", record: "v=BIMI1; l=https://example.com/a.svg; l=https://example.com/b.svg", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec, err := parseBIMIRecord(tt.record)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseBIMIRecord() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if rec.LogoURL != tt.wantLogo || rec.AuthorityURL != tt.wantAuthority {
+				t.Errorf("parseBIMIRecord() = %+v, want logo %q and authority %q", rec, tt.wantLogo, tt.wantAuthority)
+			}
+		})
+	}
+}
+
+func TestBIMIDMARCWeakness(t *testing.T) {
+	tests := []struct {
+		record   string
+		wantWeak bool
+	}{
+		{record: "v=DMARC1; p=reject"},
+		{record: "v=DMARC1; p=quarantine; pct=100"},
+		{record: "v=DMARC1; p=none", wantWeak: true},
+		{record: "v=DMARC1; p=quarantine; pct=50", wantWeak: true},
+		{record: "v=DMARC1; p=reject; sp=none", wantWeak: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.record, func(t *testing.T) {
+			rec, err := dmarc.Parse(tt.record)
+			if err != nil {
+				t.Fatalf("dmarc.Parse() error = %v", err)
+			}
+			if got := bimiDMARCWeakness(rec); (got != "") != tt.wantWeak {
+				t.Errorf("bimiDMARCWeakness() = %q, want weak %v", got, tt.wantWeak)
+			}
+		})
+	}
+}
+
+func TestBIMIHosts(t *testing.T) {
+	const record = "v=BIMI1; l=https://Images.Example.com/logo.svg; a=https://vmc.digicert.com/example.pem"
+	allowed := tftypes.NewValue(tftypes.List{ElementType: tftypes.String}, []tftypes.Value{tftypes.NewValue(tftypes.String, "digicert.com")})
+
+	tests := []struct {
+		name         string
+		domain       string
+		allowedHosts *tftypes.Value
+		wantWarnings []string
+	}{
+		{name: "no domain"},
+		{name: "unrelated certificate host", domain: "example.com", wantWarnings: []string{"a= URL is hosted on vmc.digicert.com"}},
+		{name: "allowed certificate host", domain: "example.com", allowedHosts: &allowed},
+		{name: "allow-list only", allowedHosts: &allowed, wantWarnings: []string{"l= URL is hosted on images.example.com"}},
+		{name: "other domain", domain: "example.org", wantWarnings: []string{"l= URL", "a= URL"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			d := &BIMIDataSource{}
+			values := map[string]tftypes.Value{"record": tftypes.NewValue(tftypes.String, record)}
+			if tt.domain != "" {
+				values["domain"] = tftypes.NewValue(tftypes.String, tt.domain)
+			}
+			if tt.allowedHosts != nil {
+				values["allowed_hosts"] = *tt.allowedHosts
+			}
+			config := testDataSourceConfig(t, d, values)
+			resp := datasource.ReadResponse{State: tfsdk.State{Schema: config.Schema}}
+			d.Read(ctx, datasource.ReadRequest{Config: config}, &resp)
+
+			warnings := resp.Diagnostics.Warnings()
+			if resp.Diagnostics.HasError() || len(warnings) != len(tt.wantWarnings) {
+				t.Fatalf("Read() diagnostics = %v, want %d warnings", resp.Diagnostics, len(tt.wantWarnings))
+			}
+			for i, want := range tt.wantWarnings {
+				if warnings[i].Summary() != "BIMI Host Outside Domain" || !strings.Contains(warnings[i].Detail(), want) {
+					t.Errorf("warning %d = %s: %s, want it to mention %q", i, warnings[i].Summary(), warnings[i].Detail(), want)
+				}
+			}
+
+			var data BIMIDataSourceModel
+			resp.Diagnostics.Append(resp.State.Get(ctx, &data)...)
+			if data.LogoHost.ValueString() != "images.example.com" || data.AuthorityHost.ValueString() != "vmc.digicert.com" {
+				t.Errorf("logo_host = %s, authority_host = %s", data.LogoHost, data.AuthorityHost)
+			}
+		})
+	}
+}
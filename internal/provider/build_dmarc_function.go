@@ -0,0 +1,186 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &BuildDMARCFunction{}
+
+func NewBuildDMARCFunction() function.Function {
+	return &BuildDMARCFunction{}
+}
+
+// BuildDMARCFunction defines the function implementation.
+type BuildDMARCFunction struct{}
+
+func (f *BuildDMARCFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "build_dmarc"
+}
+
+func (f *BuildDMARCFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Build a DMARC record from its settings",
+		MarkdownDescription: "Returns a DMARC record assembled from an object of settings, the same way as the `emaildns_dmarc_record` resource: " +
+			"tags in canonical order, checked with the rules of the `emaildns_dmarc` data source. Fails if a setting is invalid " +
+			"or the settings cannot be combined, such as `failure_options` without `ruf`.",
+		Parameters: []function.Parameter{
+			function.DynamicParameter{
+				Name: "settings",
+				MarkdownDescription: "An object with the attributes of the `emaildns_dmarc_record` resource: `policy` (required), `subdomain_policy`, " +
+					"`nonexistent_subdomain_policy`, `percent`, `dkim_alignment`, `spf_alignment`, `rua`, `ruf`, `failure_options`, " +
+					"`report_format`, and `report_interval`. Attributes that are omitted or null leave the tag out",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *BuildDMARCFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var settings types.Dynamic
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &settings))
+	if resp.Error != nil {
+		return
+	}
+
+	s, err := dmarcRecordSettingsFromValue(settings.UnderlyingValue())
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, "Invalid DMARC settings: "+err.Error())
+		return
+	}
+
+	var diags diag.Diagnostics
+	record := buildDMARCRecord(s, &diags)
+	if funcErr := function.FuncErrorFromDiags(ctx, diags); funcErr != nil {
+		resp.Error = function.NewArgumentFuncError(0, funcErr.Text)
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, record))
+}
+
+// dmarcRecordSettingsFromValue converts the object or map passed to
+// build_dmarc into settings. Null attributes are left unset.
+func dmarcRecordSettingsFromValue(value attr.Value) (dmarcRecordSettings, error) {
+	var s dmarcRecordSettings
+	if value == nil || value.IsNull() {
+		return s, fmt.Errorf("settings must be an object, got null")
+	}
+
+	var attributes map[string]attr.Value
+	switch v := value.(type) {
+	case types.Object:
+		attributes = v.Attributes()
+	case types.Map:
+		attributes = v.Elements()
+	default:
+		return s, fmt.Errorf("settings must be an object, got %s", value.Type(context.Background()))
+	}
+
+	strs := map[string]*string{
+		"policy":                       &s.Policy,
+		"subdomain_policy":             &s.SubdomainPolicy,
+		"nonexistent_subdomain_policy": &s.NonexistentPolicy,
+		"dkim_alignment":               &s.DKIMAlignment,
+		"spf_alignment":                &s.SPFAlignment,
+		"report_format":                &s.ReportFormat,
+	}
+	numbers := map[string]**int64{
+		"percent":         &s.Percent,
+		"report_interval": &s.ReportInterval,
+	}
+	lists := map[string]*[]string{
+		"rua":             &s.RUA,
+		"ruf":             &s.RUF,
+		"failure_options": &s.FailureOptions,
+	}
+
+	names := make([]string, 0, len(attributes))
+	for name := range attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		v := attributes[name]
+		if v.IsNull() {
+			continue
+		}
+		var err error
+		switch {
+		case strs[name] != nil:
+			*strs[name], err = dynamicString(v)
+		case numbers[name] != nil:
+			var n int64
+			n, err = dynamicInt64(v)
+			*numbers[name] = &n
+		case lists[name] != nil:
+			*lists[name], err = dynamicStringList(v)
+		default:
+			err = fmt.Errorf("unsupported attribute")
+		}
+		if err != nil {
+			return s, fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return s, nil
+}
+
+// dynamicString returns the value of a string attribute of a dynamic value.
+func dynamicString(v attr.Value) (string, error) {
+	s, ok := v.(types.String)
+	if !ok {
+		return "", fmt.Errorf("must be a string")
+	}
+	return s.ValueString(), nil
+}
+
+// dynamicInt64 returns the value of a whole number attribute of a dynamic
+// value.
+func dynamicInt64(v attr.Value) (int64, error) {
+	switch n := v.(type) {
+	case types.Int64:
+		return n.ValueInt64(), nil
+	case types.Number:
+		i, accuracy := n.ValueBigFloat().Int64()
+		if !n.ValueBigFloat().IsInt() || accuracy != 0 {
+			return 0, fmt.Errorf("must be a whole number")
+		}
+		return i, nil
+	}
+	return 0, fmt.Errorf("must be a number")
+}
+
+// dynamicStringList returns the values of a list, tuple, or set of strings in
+// a dynamic value.
+func dynamicStringList(v attr.Value) ([]string, error) {
+	var elements []attr.Value
+	switch l := v.(type) {
+	case types.List:
+		elements = l.Elements()
+	case types.Tuple:
+		elements = l.Elements()
+	case types.Set:
+		elements = l.Elements()
+	default:
+		return nil, fmt.Errorf("must be a list of strings")
+	}
+
+	values := make([]string, 0, len(elements))
+	for _, element := range elements {
+		s, ok := element.(types.String)
+		if !ok || s.IsNull() {
+			return nil, fmt.Errorf("must be a list of strings")
+		}
+		values = append(values, s.ValueString())
+	}
+	return values, nil
+}
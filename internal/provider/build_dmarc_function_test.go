@@ -0,0 +1,94 @@
+package provider
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestBuildDMARCFunction(t *testing.T) {
+	object := func(attributes map[string]attr.Value) types.Dynamic {
+		attrTypes := make(map[string]attr.Type, len(attributes))
+		for name, v := range attributes {
+			attrTypes[name] = v.Type(context.Background())
+		}
+		return types.DynamicValue(types.ObjectValueMust(attrTypes, attributes))
+	}
+	tuple := func(values ...string) attr.Value {
+		elements := make([]attr.Value, len(values))
+		elementTypes := make([]attr.Type, len(values))
+		for i, v := range values {
+			elements[i], elementTypes[i] = types.StringValue(v), types.StringType
+		}
+		return types.TupleValueMust(elementTypes, elements)
+	}
+
+	tests := []struct {
+		name     string
+		settings types.Dynamic
+		want     string
+		wantErr  string
+	}{
+		{
+			name: "object literal",
+			settings: object(map[string]attr.Value{
+				"policy":  types.StringValue("reject"),
+				"percent": types.NumberValue(big.NewFloat(100)),
+				"rua":     tuple("mailto:dmarc@example.com"),
+				"ruf":     types.ListNull(types.StringType),
+			}),
+			want: "v=DMARC1; p=reject; pct=100; rua=mailto:dmarc@example.com",
+		},
+		{
+			name:     "map",
+			settings: types.DynamicValue(types.MapValueMust(types.StringType, map[string]attr.Value{"policy": types.StringValue("none"), "spf_alignment": types.StringValue("s")})),
+			want:     "v=DMARC1; p=none; aspf=s",
+		},
+		{
+			name:     "unsupported attribute",
+			settings: object(map[string]attr.Value{"policy": types.StringValue("reject"), "pct": types.NumberValue(big.NewFloat(50))}),
+			wantErr:  "pct: unsupported attribute",
+		},
+		{
+			name:     "fractional percent",
+			settings: object(map[string]attr.Value{"policy": types.StringValue("reject"), "percent": types.NumberValue(big.NewFloat(50.5))}),
+			wantErr:  "percent: must be a whole number",
+		},
+		{
+			name:     "failure options without ruf",
+			settings: object(map[string]attr.Value{"policy": types.StringValue("reject"), "failure_options": tuple("1")}),
+			wantErr:  "DMARC Failure Options Without ruf",
+		},
+		{
+			name:     "not an object",
+			settings: types.DynamicValue(types.StringValue("v=DMARC1; p=reject")),
+			wantErr:  "settings must be an object",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := function.RunRequest{Arguments: function.NewArgumentsData([]attr.Value{tt.settings})}
+			resp := function.RunResponse{Result: function.NewResultData(types.StringUnknown())}
+			NewBuildDMARCFunction().Run(context.Background(), req, &resp)
+
+			if tt.wantErr != "" {
+				if resp.Error == nil || !strings.Contains(resp.Error.Text, tt.wantErr) {
+					t.Fatalf("Run() error = %v, want %q", resp.Error, tt.wantErr)
+				}
+				return
+			}
+			if resp.Error != nil {
+				t.Fatalf("Run() error = %v", resp.Error)
+			}
+			if got := resp.Result.Value(); !got.Equal(types.StringValue(tt.want)) {
+				t.Errorf("Run() = %v, want %q", got, tt.want)
+			}
+		})
+	}
+}
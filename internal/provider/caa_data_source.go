@@ -0,0 +1,376 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// caaCriticalFlag is the issuer critical flag of RFC 8659 section 4.1.
+const caaCriticalFlag = 128
+
+var (
+	// caaTagRe matches CAA property tags: letters and digits only.
+	caaTagRe = regexp.MustCompile(`^[A-Za-z0-9]+$`)
+
+	// caaParameterRe matches the key=value parameters of issue and
+	// issuewild values.
+	caaParameterRe = regexp.MustCompile(`^[A-Za-z0-9]+=[\x21-\x3A\x3C-\x7E]*$`)
+)
+
+// knownCAATags lists the property tags defined by RFC 8659, RFC 9495
+// (issuemail), and the BIMI Verified Mark Certificate profile (issuevmc).
+var knownCAATags = map[string]bool{
+	"issue":     true,
+	"issuewild": true,
+	"iodef":     true,
+	"issuemail": true,
+	"issuevmc":  true,
+}
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var (
+	_ datasource.DataSource                   = &CAADataSource{}
+	_ datasource.DataSourceWithValidateConfig = &CAADataSource{}
+	_ datasource.DataSourceWithConfigure      = &CAADataSource{}
+)
+
+func NewCAADataSource() datasource.DataSource {
+	return &CAADataSource{}
+}
+
+// CAADataSource defines the data source implementation.
+type CAADataSource struct {
+	providerData *providerData
+}
+
+// CAADataSourceModel describes the data source data model.
+type CAADataSourceModel struct {
+	Records         types.List   `tfsdk:"records"`
+	ChangeTicket    types.String `tfsdk:"change_ticket"`
+	Parsed          types.List   `tfsdk:"parsed"`
+	Issuers         types.List   `tfsdk:"issuers"`
+	WildcardIssuers types.List   `tfsdk:"wildcard_issuers"`
+}
+
+// caaObjectType defines the Terraform object type for a parsed CAA record.
+var caaObjectType = types.ObjectType{
+	AttrTypes: map[string]attr.Type{
+		"flags":    types.Int64Type,
+		"tag":      types.StringType,
+		"value":    types.StringType,
+		"critical": types.BoolType,
+	},
+}
+
+// caaRecord is a single parsed CAA record.
+type caaRecord struct {
+	Flags int64
+	Tag   string
+	Value string
+}
+
+// Critical reports whether the issuer critical flag is set.
+func (r caaRecord) Critical() bool {
+	return r.Flags&caaCriticalFlag != 0
+}
+
+func (d *CAADataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_caa"
+}
+
+func (d *CAADataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Validates CAA (RFC 8659) records, which restrict the certificate authorities allowed to issue certificates, " +
+			"such as those used for mail server TLS. If a record is invalid, terraform plan will fail with a specific error message.",
+
+		Attributes: map[string]schema.Attribute{
+			"records": schema.ListAttribute{
+				MarkdownDescription: "The CAA records to validate, in presentation format (e.g., `0 issue \"letsencrypt.org\"`)",
+				Required:            true,
+				ElementType:         types.StringType,
+			},
+			"change_ticket": changeTicketAttribute(),
+			"parsed": schema.ListNestedAttribute{
+				MarkdownDescription: "The parsed records, in the order given",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"flags": schema.Int64Attribute{
+							MarkdownDescription: "The flags byte: `0`, or `128` when the issuer critical flag is set",
+							Computed:            true,
+						},
+						"tag": schema.StringAttribute{
+							MarkdownDescription: "The property tag (e.g., `issue`), in lowercase",
+							Computed:            true,
+						},
+						"value": schema.StringAttribute{
+							MarkdownDescription: "The property value, without quotes",
+							Computed:            true,
+						},
+						"critical": schema.BoolAttribute{
+							MarkdownDescription: "True if the issuer critical flag is set",
+							Computed:            true,
+						},
+					},
+				},
+			},
+			"issuers": schema.ListAttribute{
+				MarkdownDescription: "The certificate authority domains allowed to issue certificates (issue tags). " +
+					"Empty when the records forbid issuance, and null when there are no issue tags, which allows any authority",
+				Computed:    true,
+				ElementType: types.StringType,
+			},
+			"wildcard_issuers": schema.ListAttribute{
+				MarkdownDescription: "The certificate authority domains allowed to issue wildcard certificates: the issuewild tags, " +
+					"or the issue tags when there are none. Empty and null as for `issuers`",
+				Computed:    true,
+				ElementType: types.StringType,
+			},
+		},
+	}
+}
+
+func (d *CAADataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	d.providerData = configureProviderData(req, resp)
+}
+
+func (d *CAADataSource) ValidateConfig(ctx context.Context, req datasource.ValidateConfigRequest, resp *datasource.ValidateConfigResponse) {
+	defer d.providerData.applyWarningSettings(&resp.Diagnostics)
+
+	var data CAADataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if data.Records.IsUnknown() || data.Records.IsNull() {
+		return
+	}
+
+	// Records that are unknown (e.g., depend on another resource) are skipped
+	for i, value := range data.Records.Elements() {
+		record, ok := value.(types.String)
+		if !ok || record.IsNull() || record.IsUnknown() {
+			continue
+		}
+		if _, err := parseCAARecord(record.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("records").AtListIndex(i),
+				summaryCAAInvalidRecord,
+				fmt.Sprintf("The CAA record is malformed: %s\n\nRecord: %s", err.Error(), record.ValueString()),
+			)
+		}
+	}
+}
+
+func (d *CAADataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	defer d.providerData.applyWarningSettings(&resp.Diagnostics)
+
+	var data CAADataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data.ChangeTicket = d.providerData.checkChangeTicket(data.ChangeTicket, &resp.Diagnostics)
+
+	var raw []string
+	resp.Diagnostics.Append(data.Records.ElementsAs(ctx, &raw, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	records := make([]caaRecord, len(raw))
+	for i, r := range raw {
+		rec, err := parseCAARecord(r)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				summaryCAAInvalidRecord,
+				fmt.Sprintf("The CAA record %q is malformed: %s", r, err.Error()),
+			)
+			return
+		}
+		records[i] = *rec
+	}
+
+	checkCAATags(records, &resp.Diagnostics)
+
+	values := make([]attr.Value, len(records))
+	for i, r := range records {
+		values[i] = types.ObjectValueMust(caaObjectType.AttrTypes, map[string]attr.Value{
+			"flags":    types.Int64Value(r.Flags),
+			"tag":      types.StringValue(r.Tag),
+			"value":    types.StringValue(r.Value),
+			"critical": types.BoolValue(r.Critical()),
+		})
+	}
+	list, diags := types.ListValue(caaObjectType, values)
+	resp.Diagnostics.Append(diags...)
+	data.Parsed = list
+
+	issuers, hasIssue := caaIssuers(records, "issue")
+	wildcardIssuers, hasIssueWild := caaIssuers(records, "issuewild")
+	if !hasIssueWild {
+		wildcardIssuers, hasIssueWild = issuers, hasIssue
+	}
+	data.Issuers = issuerList(issuers, hasIssue, &resp.Diagnostics)
+	data.WildcardIssuers = issuerList(wildcardIssuers, hasIssueWild, &resp.Diagnostics)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// issuerList converts issuer domains to a Terraform list that is null when
+// no tag restricts issuance and empty when issuance is forbidden.
+func issuerList(issuers []string, present bool, diags *diag.Diagnostics) types.List {
+	if !present {
+		return types.ListNull(types.StringType)
+	}
+	values := make([]attr.Value, len(issuers))
+	for i, issuer := range issuers {
+		values[i] = types.StringValue(issuer)
+	}
+	list, d := types.ListValue(types.StringType, values)
+	diags.Append(d...)
+	return list
+}
+
+// checkCAATags warns about tags that certificate authorities may not
+// understand. RFC 8659 section 4.1 requires authorities to refuse issuance
+// when an unknown tag has the critical flag set.
+func checkCAATags(records []caaRecord, diags *diag.Diagnostics) {
+	for _, r := range records {
+		if knownCAATags[r.Tag] {
+			continue
+		}
+		if r.Critical() {
+			diags.AddWarning(
+				summaryCAACriticalUnknownTag,
+				fmt.Sprintf("The CAA tag %q is marked critical (flags %d) but is not a standard tag. "+
+					"Certificate authorities that do not understand it must refuse to issue any certificate for the domain.", r.Tag, r.Flags),
+			)
+			continue
+		}
+		diags.AddWarning(
+			summaryCAAUnknownTag,
+			fmt.Sprintf("The CAA tag %q is not a standard tag and will be ignored by certificate authorities. Check it for typos.", r.Tag),
+		)
+	}
+}
+
+// caaIssuers returns the issuer domains named by the records with the given
+// tag, and whether any such record exists. Records with an empty issuer,
+// such as `0 issue ";"`, forbid issuance and contribute no domain.
+func caaIssuers(records []caaRecord, tag string) ([]string, bool) {
+	issuers := []string{}
+	found := false
+	for _, r := range records {
+		if r.Tag != tag {
+			continue
+		}
+		found = true
+		if issuer, _ := parseCAAIssuerValue(r.Value); issuer != "" {
+			issuers = append(issuers, issuer)
+		}
+	}
+	return issuers, found
+}
+
+// parseCAARecord parses a CAA record in presentation format: a flags byte,
+// a tag, and a value that may be quoted.
+func parseCAARecord(record string) (*caaRecord, error) {
+	fields := strings.Fields(record)
+	if len(fields) < 3 {
+		return nil, fmt.Errorf("expected <flags> <tag> <value>")
+	}
+
+	flags, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil || (flags != 0 && flags != caaCriticalFlag) {
+		return nil, fmt.Errorf("flags must be 0 or %d (critical), got %q", caaCriticalFlag, fields[0])
+	}
+
+	tag := fields[1]
+	if !caaTagRe.MatchString(tag) || len(tag) > 15 {
+		return nil, fmt.Errorf("tag %q must be 1 to 15 letters and digits", tag)
+	}
+	tag = strings.ToLower(tag)
+
+	// The value is everything after the tag, so quoted values may contain
+	// spaces
+	value := strings.TrimSpace(record)
+	value = strings.TrimSpace(strings.TrimPrefix(value, fields[0]))
+	value = strings.TrimSpace(value[len(fields[1]):])
+	if strings.HasPrefix(value, `"`) {
+		if len(value) < 2 || !strings.HasSuffix(value, `"`) {
+			return nil, fmt.Errorf("unterminated quoted value")
+		}
+		value = value[1 : len(value)-1]
+	}
+
+	switch tag {
+	case "issue", "issuewild", "issuemail", "issuevmc":
+		if _, err := parseCAAIssuerValue(value); err != nil {
+			return nil, err
+		}
+	case "iodef":
+		if err := checkCAAIODEF(value); err != nil {
+			return nil, err
+		}
+	}
+
+	return &caaRecord{Flags: flags, Tag: tag, Value: value}, nil
+}
+
+// parseCAAIssuerValue validates the value of an issue-style property and
+// returns the issuer domain, which is empty when issuance is forbidden.
+func parseCAAIssuerValue(value string) (string, error) {
+	issuer, params, _ := strings.Cut(value, ";")
+	issuer = strings.TrimSpace(issuer)
+	if issuer != "" {
+		if err := checkHostname(issuer); err != nil {
+			return "", fmt.Errorf("invalid issuer domain: %w", err)
+		}
+	}
+
+	for _, param := range strings.Split(params, ";") {
+		param = strings.TrimSpace(param)
+		if param != "" && !caaParameterRe.MatchString(param) {
+			return "", fmt.Errorf("invalid parameter %q (expected key=value)", param)
+		}
+	}
+	return issuer, nil
+}
+
+// checkCAAIODEF validates an iodef value, which must be a mailto, http, or
+// https URL for incident reports.
+func checkCAAIODEF(value string) error {
+	u, err := url.Parse(value)
+	if err != nil {
+		return fmt.Errorf("iodef %q is not a valid URL", value)
+	}
+	switch u.Scheme {
+	case "mailto":
+		if u.Opaque == "" {
+			return fmt.Errorf("iodef %q has no email address", value)
+		}
+	case "http", "https":
+		if u.Host == "" {
+			return fmt.Errorf("iodef %q has no host", value)
+		}
+	default:
+		return fmt.Errorf("iodef %q must be a mailto:, http:, or https: URL", value)
+	}
+	return nil
+}
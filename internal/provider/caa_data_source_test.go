@@ -0,0 +1,84 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+func TestParseCAARecord(t *testing.T) {
+	tests := []struct {
+		name    string
+		record  string
+		want    *caaRecord
+		wantErr bool
+	}{
+		{name: "issue", record: `0 issue "letsencrypt.org"`, want: &caaRecord{Flags: 0, Tag: "issue", Value: "letsencrypt.org"}},
+		{name: "unquoted", record: "0 issuewild digicert.com", want: &caaRecord{Flags: 0, Tag: "issuewild", Value: "digicert.com"}},
+		{name: "parameters", record: `0 issue "letsencrypt.org; validationmethods=dns-01"`, want: &caaRecord{Tag: "issue", Value: "letsencrypt.org; validationmethods=dns-01"}},
+		{name: "forbid issuance", record: `0 issue ";"`, want: &caaRecord{Tag: "issue", Value: ";"}},
+		{name: "critical", record: `128 issue "letsencrypt.org"`, want: &caaRecord{Flags: 128, Tag: "issue", Value: "letsencrypt.org"}},
+		{name: "uppercase tag", record: `0 ISSUE "letsencrypt.org"`, want: &caaRecord{Tag: "issue", Value: "letsencrypt.org"}},
+		{name: "iodef", record: `0 iodef "mailto:security@example.com"`, want: &caaRecord{Tag: "iodef", Value: "mailto:security@example.com"}},
+		{name: "unknown tag", record: `0 policy "x"`, want: &caaRecord{Tag: "policy", Value: "x"}},
+		{name: "invalid flags", record: `1 issue "letsencrypt.org"`, wantErr: true},
+		{name: "flags not a number", record: `x issue "letsencrypt.org"`, wantErr: true},
+		{name: "invalid tag", record: `0 is-sue "letsencrypt.org"`, wantErr: true},
+		{name: "invalid issuer", record: `0 issue "lets encrypt"`, wantErr: true},
+		{name: "issuer url", record: `0 issue "https://letsencrypt.org"`, wantErr: true},
+		{name: "invalid parameter", record: `0 issue "letsencrypt.org; bogus"`, wantErr: true},
+		{name: "iodef ftp", record: `0 iodef "ftp://example.com/"`, wantErr: true},
+		{name: "unterminated quote", record: `0 issue "letsencrypt.org`, wantErr: true},
+		{name: "missing value", record: "0 issue", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseCAARecord(tt.record)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseCAARecord() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseCAARecord() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCAAIssuers(t *testing.T) {
+	records := []caaRecord{
+		{Tag: "issue", Value: "letsencrypt.org"},
+		{Tag: "issue", Value: "digicert.com; cansignhttpexchanges=yes"},
+		{Tag: "issuewild", Value: ";"},
+	}
+
+	issuers, found := caaIssuers(records, "issue")
+	if want := []string{"letsencrypt.org", "digicert.com"}; !found || !reflect.DeepEqual(issuers, want) {
+		t.Errorf("caaIssuers(issue) = %v, %v, want %v", issuers, found, want)
+	}
+	issuers, found = caaIssuers(records, "issuewild")
+	if !found || len(issuers) != 0 {
+		t.Errorf("caaIssuers(issuewild) = %v, %v, want an empty list", issuers, found)
+	}
+	if _, found = caaIssuers(records, "issuemail"); found {
+		t.Error("caaIssuers(issuemail) found = true, want false")
+	}
+}
+
+func TestCheckCAATags(t *testing.T) {
+	var diags diag.Diagnostics
+	checkCAATags([]caaRecord{
+		{Tag: "issue", Value: "letsencrypt.org", Flags: 128},
+		{Tag: "tbs", Value: "x", Flags: 128},
+		{Tag: "policy", Value: "x"},
+	}, &diags)
+
+	var got []string
+	for _, d := range diags {
+		got = append(got, d.Summary())
+	}
+	if want := []string{"Critical Unknown CAA Tag", "Unknown CAA Tag"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("checkCAATags() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,348 @@
+package provider
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+// Summaries of the warnings that have a code. Diagnostics are reported with
+// these constants rather than literal strings, so that rewording a summary
+// cannot lose its code.
+const (
+	summaryADSPObsolete                  = "Obsolete ADSP Record"
+	summaryBIMIHostOutsideDomain         = "BIMI Host Outside Domain"
+	summaryCAACriticalUnknownTag         = "Critical Unknown CAA Tag"
+	summaryCAAUnknownTag                 = "Unknown CAA Tag"
+	summaryDKIMWeakKey                   = "Weak DKIM Key"
+	summaryDKIMUnusualExponent           = "Unusual DKIM Key Exponent"
+	summaryDKIMPKCS1Key                  = "Non-Standard DKIM Key Encoding"
+	summaryDKIMSHA1Hash                  = "Deprecated DKIM Hash Algorithm"
+	summaryDKIMServiceRestricted         = "DKIM Key Restricted From Email"
+	summaryDKIMTestingMode               = "DKIM Testing Mode"
+	summaryDKIMKeyTypeCase               = "DKIM Key Type Not Lowercase"
+	summaryDKIMUnknownTag                = "Unknown DKIM Tag"
+	summaryDKIMQueryMethod               = "Non-Standard DKIM Query Method"
+	summaryDKIMSelectorLookupFailed      = "DKIM Selector Lookup Failed"
+	summaryDMARCSubdomainPolicyWeak      = "Weak DMARC Subdomain Policy"
+	summaryDMARCPolicyNone               = "Monitoring-Only DMARC Policy"
+	summaryDMARCPCTZero                  = "DMARC Policy Disabled by pct=0"
+	summaryDMARCPCTPartial               = "Partial DMARC Policy"
+	summaryDMARCNoRUA                    = "No DMARC Aggregate Reporting"
+	summaryDMARCRUFThirdParty            = "DMARC Failure Reports Sent to Third Party"
+	summaryDMARCTXTStringLength          = "DMARC Record Exceeds TXT String Length"
+	summaryDMARCTXTStringNearLimit       = "DMARC Record Near TXT String Length"
+	summaryDMARCUnknownTags              = "Unknown DMARC Tags"
+	summaryDMARCFOWithoutRUF             = "DMARC Failure Options Without ruf"
+	summaryDMARCReportInterval           = "Non-Standard DMARC Report Interval"
+	summaryDMARCReportFormat             = "Non-Standard DMARC Report Format"
+	summaryDMARCHTTPSDestination         = "DMARC https Report Destination"
+	summaryDMARCHTTPSNoPath              = "DMARC https Report URI Without Path"
+	summaryDMARCRUACount                 = "Too Many DMARC Aggregate Destinations"
+	summaryDMARCUnrelatedReportingDomain = "Unrelated DMARC Reporting Domain"
+	summaryMTASTSShortMaxAge             = "Short MTA-STS Policy Lifetime"
+	summaryMXNoRecords                   = "No MX Records"
+	summaryMXRelativeHost                = "Relative MX Host"
+	summaryMXEqualPreferences            = "Equal MX Preferences"
+	summaryPTRMissing                    = "No PTR Record"
+	summaryPTRNotForwardConfirmed        = "Reverse DNS Not Forward-Confirmed"
+	summaryPTRUnexpectedHostname         = "Unexpected PTR Hostname"
+	summarySPFMechanismLookupFailed      = "SPF Mechanism Lookup Failed"
+	summarySPFNoTerminalMechanism        = "SPF Record Has No Terminal Mechanism"
+	summarySPFRedundantIPRange           = "Redundant SPF IP Range"
+	summarySPFPTRDeprecated              = "Deprecated SPF ptr Mechanism"
+	summarySPFIncludeShouldBeRedirect    = "SPF Include Could Be Redirect"
+	summarySPFRedirectLookupFailed       = "SPF Redirect Lookup Failed"
+	summarySPFLookupCountIncomplete      = "SPF Combined Lookup Count Incomplete"
+	summarySPFIncludeDepthIncomplete     = "SPF Include Depth Incomplete"
+	summarySPFSurroundingSpaces          = "SPF Record Has Surrounding Spaces"
+	summarySPFLookupBreakdownIncomplete  = "SPF Lookup Breakdown Incomplete"
+	summarySPFTXTStringLength            = "SPF Record Exceeds TXT String Length"
+	summarySPFImplicitDomain             = "Implicit SPF Domain"
+	summarySRVZeroWeight                 = "Zero SRV Weight"
+	summaryTLSRPTNoRUA                   = "No TLS-RPT Report Destinations"
+	summaryTLSAUsageUnsupported          = "TLSA Usage Unsupported for SMTP"
+	summaryDNSTemporaryFailure           = "Temporary DNS Failure"
+)
+
+// Summaries of the errors that have a code.
+const (
+	summaryADSPInvalidRecord              = "Invalid ADSP Record"
+	summaryBIMIInvalidRecord              = "Invalid BIMI Record"
+	summaryBIMIDMARCPolicyWeak            = "DMARC Policy Too Weak for BIMI"
+	summaryCAAInvalidRecord               = "Invalid CAA Record"
+	summaryChangeTicketMissing            = "Missing Change Ticket"
+	summaryDKIMInvalidRecord              = "Invalid DKIM Record"
+	summaryDKIMKeyTooShort                = "DKIM Key Too Short"
+	summaryDKIMRevokedKey                 = "Revoked DKIM Key"
+	summaryDKIMInvalidSelector            = "Invalid DKIM Selector"
+	summaryDKIMRecordNotFound             = "DKIM Record Not Found"
+	summaryDKIMLookupFailed               = "DKIM Record Lookup Failed"
+	summaryDKIMInvalidKeyType             = "Invalid DKIM Key Type"
+	summaryDKIMInvalidRSAKeySize          = "Invalid RSA Key Size"
+	summaryDKIMKeyGenerationFailed        = "DKIM Key Generation Failed"
+	summaryDMARCInvalidRecord             = "Invalid DMARC Record"
+	summaryDMARCDuplicateTags             = "Duplicate DMARC Tags"
+	summaryDMARCInvalidReportURI          = "Invalid DMARC Report URI"
+	summaryDMARCDuplicateReportURI        = "Duplicate DMARC Report URI"
+	summaryDMARCLookupFailed              = "DMARC Record Lookup Failed"
+	summaryDMARCAuthorizationLookupFailed = "DMARC Authorization Lookup Failed"
+	summaryDMARCInvalidPolicy             = "Invalid DMARC Policy"
+	summaryDMARCInvalidAlignment          = "Invalid DMARC Alignment Mode"
+	summaryDMARCInvalidPercent            = "Invalid DMARC Percentage"
+	summaryDMARCInvalidReportDestination  = "Invalid DMARC Report Destination"
+	summaryDMARCInvalidFailureOptions     = "Invalid DMARC Failure Options"
+	summaryDMARCInvalidReportFormat       = "Invalid DMARC Report Format"
+	summaryDMARCInvalidReportInterval     = "Invalid DMARC Report Interval"
+	summaryDomainInvalid                  = "Invalid Domain"
+	summaryDomainMissing                  = "Missing Domain"
+	summaryDomainNoRecords                = "No Records to Validate"
+	summaryMTASTSInvalidRecord            = "Invalid MTA-STS Record"
+	summaryMTASTSInvalidPolicy            = "Invalid MTA-STS Policy"
+	summaryMXInvalidConfiguration         = "Invalid MX Configuration"
+	summaryMXNullExpected                 = "Null MX Expected"
+	summaryMXLookupFailed                 = "MX Lookup Failed"
+	summaryPTRInvalidIPAddress            = "Invalid IP Address"
+	summaryPTRInvalidHostname             = "Invalid Hostname"
+	summaryPTRLookupFailed                = "Reverse DNS Lookup Failed"
+	summaryRecordMissing                  = "Missing Record"
+	summaryRecordConflictingSettings      = "Conflicting Record Settings"
+	summaryRecordFileUnreadable           = "Unable to Read Record File"
+	summarySPFParseError                  = "Invalid SPF Record"
+	summarySPFIPLiteralTarget             = "SPF Domain Target Is an IP Address"
+	summarySPFInvalidCIDRLength           = "Invalid SPF CIDR Length"
+	summarySPFRedirectWithAll             = "Conflicting SPF Redirect and All"
+	summarySPFLookupLimitExceeded         = "SPF Lookup Limit Exceeded"
+	summarySPFVoidLookupLimitExceeded     = "SPF Void Lookup Limit Exceeded"
+	summarySPFMXLimitExceeded             = "SPF MX Record Limit Exceeded"
+	summarySPFRecordTooLong               = "SPF Record Too Long"
+	summarySPFIncludeLoop                 = "SPF Include Loop"
+	summarySPFIncludeDepthExceeded        = "SPF Include Depth Exceeded"
+	summarySPFInvalidMaxDepth             = "Invalid Maximum Include Depth"
+	summarySPFInvalidMaxRedirectDepth     = "Invalid Maximum Redirect Depth"
+	summarySPFInvalidRedirectChain        = "Invalid SPF Redirect Chain"
+	summarySPFLookupFailed                = "SPF Record Lookup Failed"
+	summarySPFMergeFailed                 = "Unable to Merge SPF Records"
+	summarySPFInvalidMechanism            = "Invalid SPF Mechanism"
+	summarySPFInvalidAllQualifier         = "Invalid SPF All Qualifier"
+	summarySRVInvalidName                 = "Invalid SRV Name"
+	summarySRVInvalidRecord               = "Invalid SRV Record"
+	summarySRVInvalidNotAvailable         = "Invalid SRV Service Not Available"
+	summaryTLSRPTInvalidRecord            = "Invalid TLS-RPT Record"
+	summaryTLSAInvalidName                = "Invalid TLSA Name"
+	summaryTLSAInvalidRecord              = "Invalid TLSA Record"
+	summaryTXTInvalidRecordType           = "Invalid TXT Record Type"
+	summaryTXTEncodeFailed                = "Unable to Encode Parsed Record"
+)
+
+// warningCodes maps the summary of each warning reported by the data sources
+// to its stable code, which users list in the ignored_warnings provider
+// setting. Codes must never change once released; when a summary is
+// reworded, keep its code.
+var warningCodes = map[string]string{
+	summaryADSPObsolete:                  "ADSP_OBSOLETE",
+	summaryBIMIHostOutsideDomain:         "BIMI_HOST_OUTSIDE_DOMAIN",
+	summaryCAACriticalUnknownTag:         "CAA_CRITICAL_UNKNOWN_TAG",
+	summaryCAAUnknownTag:                 "CAA_UNKNOWN_TAG",
+	summaryDKIMWeakKey:                   "DKIM_WEAK_KEY",
+	summaryDKIMUnusualExponent:           "DKIM_UNUSUAL_EXPONENT",
+	summaryDKIMPKCS1Key:                  "DKIM_PKCS1_KEY",
+	summaryDKIMSHA1Hash:                  "DKIM_SHA1_HASH",
+	summaryDKIMServiceRestricted:         "DKIM_SERVICE_RESTRICTED",
+	summaryDKIMTestingMode:               "DKIM_TESTING_MODE",
+	summaryDKIMKeyTypeCase:               "DKIM_KEY_TYPE_CASE",
+	summaryDKIMUnknownTag:                "DKIM_UNKNOWN_TAG",
+	summaryDKIMQueryMethod:               "DKIM_QUERY_METHOD",
+	summaryDKIMSelectorLookupFailed:      "DKIM_SELECTOR_LOOKUP_FAILED",
+	summaryDMARCSubdomainPolicyWeak:      "DMARC_SUBDOMAIN_POLICY_WEAK",
+	summaryDMARCPolicyNone:               "DMARC_POLICY_NONE",
+	summaryDMARCPCTZero:                  "DMARC_PCT_ZERO",
+	summaryDMARCPCTPartial:               "DMARC_PCT_PARTIAL",
+	summaryDMARCNoRUA:                    "DMARC_NO_RUA",
+	summaryDMARCRUFThirdParty:            "DMARC_RUF_THIRD_PARTY",
+	summaryDMARCTXTStringLength:          "DMARC_TXT_STRING_LENGTH",
+	summaryDMARCTXTStringNearLimit:       "DMARC_TXT_STRING_NEAR_LIMIT",
+	summaryDMARCUnknownTags:              "DMARC_UNKNOWN_TAGS",
+	summaryDMARCFOWithoutRUF:             "DMARC_FO_WITHOUT_RUF",
+	summaryDMARCReportInterval:           "DMARC_REPORT_INTERVAL",
+	summaryDMARCReportFormat:             "DMARC_REPORT_FORMAT",
+	summaryDMARCHTTPSDestination:         "DMARC_HTTPS_DESTINATION",
+	summaryDMARCHTTPSNoPath:              "DMARC_HTTPS_NO_PATH",
+	summaryDMARCRUACount:                 "DMARC_RUA_COUNT",
+	summaryDMARCUnrelatedReportingDomain: "DMARC_UNRELATED_REPORTING_DOMAIN",
+	summaryMTASTSShortMaxAge:             "MTA_STS_SHORT_MAX_AGE",
+	summaryMXNoRecords:                   "MX_NO_RECORDS",
+	summaryMXRelativeHost:                "MX_RELATIVE_HOST",
+	summaryMXEqualPreferences:            "MX_EQUAL_PREFERENCES",
+	summaryPTRMissing:                    "PTR_MISSING",
+	summaryPTRNotForwardConfirmed:        "PTR_NOT_FORWARD_CONFIRMED",
+	summaryPTRUnexpectedHostname:         "PTR_UNEXPECTED_HOSTNAME",
+	summarySPFMechanismLookupFailed:      "SPF_MECHANISM_LOOKUP_FAILED",
+	summarySPFNoTerminalMechanism:        "SPF_NO_TERMINAL_MECHANISM",
+	summarySPFRedundantIPRange:           "SPF_REDUNDANT_IP_RANGE",
+	summarySPFPTRDeprecated:              "SPF_PTR_DEPRECATED",
+	summarySPFIncludeShouldBeRedirect:    "SPF_INCLUDE_SHOULD_BE_REDIRECT",
+	summarySPFRedirectLookupFailed:       "SPF_REDIRECT_LOOKUP_FAILED",
+	summarySPFLookupCountIncomplete:      "SPF_LOOKUP_COUNT_INCOMPLETE",
+	summarySPFIncludeDepthIncomplete:     "SPF_INCLUDE_DEPTH_INCOMPLETE",
+	summarySPFSurroundingSpaces:          "SPF_SURROUNDING_SPACES",
+	summarySPFLookupBreakdownIncomplete:  "SPF_LOOKUP_BREAKDOWN_INCOMPLETE",
+	summarySPFTXTStringLength:            "SPF_TXT_STRING_LENGTH",
+	summarySPFImplicitDomain:             "SPF_IMPLICIT_DOMAIN",
+	summarySRVZeroWeight:                 "SRV_ZERO_WEIGHT",
+	summaryTLSRPTNoRUA:                   "TLS_RPT_NO_RUA",
+	summaryTLSAUsageUnsupported:          "TLSA_USAGE_UNSUPPORTED",
+	summaryDNSTemporaryFailure:           "DNS_TEMPORARY_FAILURE",
+}
+
+// errorCodes maps the summary of each error reported by the data sources and
+// record resources to its stable code. Like warning codes, they must never
+// change once released. Errors reported for provider configuration have no
+// code.
+var errorCodes = map[string]string{
+	summaryADSPInvalidRecord:              "ADSP_INVALID_RECORD",
+	summaryBIMIInvalidRecord:              "BIMI_INVALID_RECORD",
+	summaryBIMIDMARCPolicyWeak:            "BIMI_DMARC_POLICY_WEAK",
+	summaryCAAInvalidRecord:               "CAA_INVALID_RECORD",
+	summaryChangeTicketMissing:            "CHANGE_TICKET_MISSING",
+	summaryDKIMInvalidRecord:              "DKIM_INVALID_RECORD",
+	summaryDKIMKeyTooShort:                "DKIM_KEY_TOO_SHORT",
+	summaryDKIMRevokedKey:                 "DKIM_REVOKED_KEY",
+	summaryDKIMInvalidSelector:            "DKIM_INVALID_SELECTOR",
+	summaryDKIMRecordNotFound:             "DKIM_RECORD_NOT_FOUND",
+	summaryDKIMLookupFailed:               "DKIM_LOOKUP_FAILED",
+	summaryDKIMInvalidKeyType:             "DKIM_INVALID_KEY_TYPE",
+	summaryDKIMInvalidRSAKeySize:          "DKIM_INVALID_RSA_KEY_SIZE",
+	summaryDKIMKeyGenerationFailed:        "DKIM_KEY_GENERATION_FAILED",
+	summaryDMARCInvalidRecord:             "DMARC_INVALID_RECORD",
+	summaryDMARCDuplicateTags:             "DMARC_DUPLICATE_TAGS",
+	summaryDMARCInvalidReportURI:          "DMARC_INVALID_REPORT_URI",
+	summaryDMARCDuplicateReportURI:        "DMARC_DUPLICATE_REPORT_URI",
+	summaryDMARCLookupFailed:              "DMARC_LOOKUP_FAILED",
+	summaryDMARCAuthorizationLookupFailed: "DMARC_AUTHORIZATION_LOOKUP_FAILED",
+	summaryDMARCInvalidPolicy:             "DMARC_INVALID_POLICY",
+	summaryDMARCInvalidAlignment:          "DMARC_INVALID_ALIGNMENT",
+	summaryDMARCInvalidPercent:            "DMARC_INVALID_PERCENT",
+	summaryDMARCInvalidReportDestination:  "DMARC_INVALID_REPORT_DESTINATION",
+	summaryDMARCInvalidFailureOptions:     "DMARC_INVALID_FAILURE_OPTIONS",
+	summaryDMARCInvalidReportFormat:       "DMARC_INVALID_REPORT_FORMAT",
+	summaryDMARCInvalidReportInterval:     "DMARC_INVALID_REPORT_INTERVAL",
+	summaryDomainInvalid:                  "DOMAIN_INVALID",
+	summaryDomainMissing:                  "DOMAIN_MISSING",
+	summaryDomainNoRecords:                "DOMAIN_NO_RECORDS",
+	summaryMTASTSInvalidRecord:            "MTA_STS_INVALID_RECORD",
+	summaryMTASTSInvalidPolicy:            "MTA_STS_INVALID_POLICY",
+	summaryMXInvalidConfiguration:         "MX_INVALID_CONFIGURATION",
+	summaryMXNullExpected:                 "MX_NULL_EXPECTED",
+	summaryMXLookupFailed:                 "MX_LOOKUP_FAILED",
+	summaryPTRInvalidIPAddress:            "PTR_INVALID_IP_ADDRESS",
+	summaryPTRInvalidHostname:             "PTR_INVALID_HOSTNAME",
+	summaryPTRLookupFailed:                "PTR_LOOKUP_FAILED",
+	summaryRecordMissing:                  "RECORD_MISSING",
+	summaryRecordConflictingSettings:      "RECORD_CONFLICTING_SETTINGS",
+	summaryRecordFileUnreadable:           "RECORD_FILE_UNREADABLE",
+	summarySPFParseError:                  "SPF_PARSE_ERROR",
+	summarySPFIPLiteralTarget:             "SPF_IP_LITERAL_TARGET",
+	summarySPFInvalidCIDRLength:           "SPF_INVALID_CIDR_LENGTH",
+	summarySPFRedirectWithAll:             "SPF_REDIRECT_WITH_ALL",
+	summarySPFLookupLimitExceeded:         "SPF_LOOKUP_LIMIT_EXCEEDED",
+	summarySPFVoidLookupLimitExceeded:     "SPF_VOID_LOOKUP_LIMIT_EXCEEDED",
+	summarySPFMXLimitExceeded:             "SPF_MX_LIMIT_EXCEEDED",
+	summarySPFRecordTooLong:               "SPF_RECORD_TOO_LONG",
+	summarySPFIncludeLoop:                 "SPF_INCLUDE_LOOP",
+	summarySPFIncludeDepthExceeded:        "SPF_INCLUDE_DEPTH_EXCEEDED",
+	summarySPFInvalidMaxDepth:             "SPF_INVALID_MAX_DEPTH",
+	summarySPFInvalidMaxRedirectDepth:     "SPF_INVALID_MAX_REDIRECT_DEPTH",
+	summarySPFInvalidRedirectChain:        "SPF_INVALID_REDIRECT_CHAIN",
+	summarySPFLookupFailed:                "SPF_LOOKUP_FAILED",
+	summarySPFMergeFailed:                 "SPF_MERGE_FAILED",
+	summarySPFInvalidMechanism:            "SPF_INVALID_MECHANISM",
+	summarySPFInvalidAllQualifier:         "SPF_INVALID_ALL_QUALIFIER",
+	summarySRVInvalidName:                 "SRV_INVALID_NAME",
+	summarySRVInvalidRecord:               "SRV_INVALID_RECORD",
+	summarySRVInvalidNotAvailable:         "SRV_INVALID_NOT_AVAILABLE",
+	summaryTLSRPTInvalidRecord:            "TLS_RPT_INVALID_RECORD",
+	summaryTLSAInvalidName:                "TLSA_INVALID_NAME",
+	summaryTLSAInvalidRecord:              "TLSA_INVALID_RECORD",
+	summaryTXTInvalidRecordType:           "TXT_INVALID_RECORD_TYPE",
+	summaryTXTEncodeFailed:                "TXT_ENCODE_FAILED",
+}
+
+// diagnosticCodePrefix introduces the code at the end of a diagnostic's
+// detail, where tools reading Terraform's output can find it.
+const diagnosticCodePrefix = "\n\nDiagnostic code: "
+
+// diagnosticCode returns the stable code of the diagnostic with the given
+// summary. Some checks are warnings in data sources and errors in resources,
+// so both kinds of code are looked up regardless of severity.
+func diagnosticCode(summary string) (string, bool) {
+	if code, ok := warningCodes[summary]; ok {
+		return code, true
+	}
+	code, ok := errorCodes[summary]
+	return code, ok
+}
+
+// addDiagnosticCodes appends the code of every diagnostic in diags that has
+// one to its detail.
+func addDiagnosticCodes(diags *diag.Diagnostics) {
+	for i, d := range *diags {
+		code, ok := diagnosticCode(d.Summary())
+		if !ok || strings.HasSuffix(d.Detail(), diagnosticCodePrefix+code) {
+			continue
+		}
+		detail := d.Detail() + diagnosticCodePrefix + code
+		var coded diag.Diagnostic = diag.NewWarningDiagnostic(d.Summary(), detail)
+		if d.Severity() == diag.SeverityError {
+			coded = diag.NewErrorDiagnostic(d.Summary(), detail)
+		}
+		if withPath, ok := d.(diag.DiagnosticWithPath); ok {
+			(*diags)[i] = diag.WithPath(withPath.Path(), coded)
+			continue
+		}
+		(*diags)[i] = coded
+	}
+}
+
+// knownWarningCodes returns every warning code in sorted order.
+func knownWarningCodes() []string {
+	codes := make([]string, 0, len(warningCodes))
+	for _, code := range warningCodes {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// isKnownWarningCode reports whether code is the code of a warning.
+func isKnownWarningCode(code string) bool {
+	for _, known := range warningCodes {
+		if known == code {
+			return true
+		}
+	}
+	return false
+}
+
+// ignoresWarning reports whether d is a warning whose code the provider lists
+// in ignored_warnings.
+func (p *providerData) ignoresWarning(d diag.Diagnostic) bool {
+	if p == nil || d.Severity() != diag.SeverityWarning {
+		return false
+	}
+	code, ok := warningCodes[d.Summary()]
+	return ok && p.ignoredWarnings[code]
+}
+
+// withoutIgnoredWarnings returns diags without the warnings listed in
+// ignored_warnings.
+func (p *providerData) withoutIgnoredWarnings(diags diag.Diagnostics) diag.Diagnostics {
+	var kept diag.Diagnostics
+	for _, d := range diags {
+		if !p.ignoresWarning(d) {
+			kept = append(kept, d)
+		}
+	}
+	return kept
+}
@@ -3,9 +3,14 @@ package provider
 import (
 	"context"
 	"fmt"
+	"maps"
+	"slices"
+	"strings"
 
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
@@ -13,25 +18,55 @@ import (
 var (
 	_ datasource.DataSource                   = &DKIMDataSource{}
 	_ datasource.DataSourceWithValidateConfig = &DKIMDataSource{}
+	_ datasource.DataSourceWithConfigure      = &DKIMDataSource{}
 )
 
 func NewDKIMDataSource() datasource.DataSource {
 	return &DKIMDataSource{}
 }
 
+// recommendedDKIMKeyBits is the RSA key size below which a DKIM key draws a
+// warning. RFC 8301 still accepts 1024-bit keys, but they are considered weak.
+const recommendedDKIMKeyBits = 2048
+
+// standardRSAExponent is the public exponent used by virtually all RSA key
+// generators.
+const standardRSAExponent = 65537
+
 // DKIMDataSource defines the data source implementation.
-type DKIMDataSource struct{}
+type DKIMDataSource struct {
+	providerData *providerData
+}
 
 // DKIMDataSourceModel describes the data source data model.
 type DKIMDataSourceModel struct {
-	Record         types.String `tfsdk:"record"`
+	Record       types.String `tfsdk:"record"`
+	RecordFile   types.String `tfsdk:"record_file"`
+	AllowRevoked types.Bool   `tfsdk:"allow_revoked"`
+	ChangeTicket types.String `tfsdk:"change_ticket"`
+	Valid        types.Bool   `tfsdk:"valid"`
+	Warnings     types.List   `tfsdk:"warnings"`
+	dkimKeyModel
+}
+
+// dkimKeyModel holds the attributes parsed from a DKIM key record, shared by
+// the emaildns_dkim and emaildns_dkim_lookup data sources.
+type dkimKeyModel struct {
+	ParsedJSON     types.String `tfsdk:"parsed_json"`
 	KeyType        types.String `tfsdk:"key_type"`
 	PublicKey      types.String `tfsdk:"public_key"`
+	KeyBits        types.Int64  `tfsdk:"key_bits"`
+	KeyFingerprint types.String `tfsdk:"key_fingerprint"`
+	PublicKeyPEM   types.String `tfsdk:"public_key_pem"`
+	KeyFormat      types.String `tfsdk:"key_format"`
 	HashAlgorithms types.List   `tfsdk:"hash_algorithms"`
 	Services       types.List   `tfsdk:"services"`
 	Flags          types.List   `tfsdk:"flags"`
 	Notes          types.String `tfsdk:"notes"`
+	QueryMethod    types.String `tfsdk:"query_method"`
 	IsRevoked      types.Bool   `tfsdk:"is_revoked"`
+	IsTesting      types.Bool   `tfsdk:"is_testing"`
+	RawTags        types.Map    `tfsdk:"raw_tags"`
 }
 
 func (d *DKIMDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
@@ -39,51 +74,111 @@ func (d *DKIMDataSource) Metadata(ctx context.Context, req datasource.MetadataRe
 }
 
 func (d *DKIMDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	attributes := map[string]schema.Attribute{
+		"record": schema.StringAttribute{
+			MarkdownDescription: "The DKIM TXT record content to validate (e.g., `v=DKIM1; k=rsa; p=MIGfMA0GCS...`). " +
+				"Long records pasted as quoted strings (e.g., `\"v=DKIM1; k=rsa; \" \"p=MIGfMA0GCS...\"`) are joined before validation. " +
+				"When `record_file` is set instead, this is the content of the file",
+			Optional: true,
+			Computed: true,
+		},
+		"record_file": recordFileAttribute(),
+		"allow_revoked": schema.BoolAttribute{
+			MarkdownDescription: "Set to `false` to fail when the record revokes its key (empty `p=` tag), to catch revoked selectors published by mistake. Defaults to `true`.",
+			Optional:            true,
+		},
+		"change_ticket": changeTicketAttribute(),
+		"valid":         validAttribute(),
+		"warnings":      warningsAttribute(),
+	}
+	maps.Copy(attributes, dkimKeyAttributes())
+
 	resp.Schema = schema.Schema{
 		MarkdownDescription: "Validates a DKIM (DomainKeys Identified Mail) DNS TXT record. " +
 			"If the record is invalid, terraform plan will fail with a specific error message.",
 
-		Attributes: map[string]schema.Attribute{
-			"record": schema.StringAttribute{
-				MarkdownDescription: "The DKIM TXT record content to validate (e.g., `v=DKIM1; k=rsa; p=MIGfMA0GCS...`)",
-				Required:            true,
-			},
-			"key_type": schema.StringAttribute{
-				MarkdownDescription: "The key algorithm type (rsa or ed25519)",
-				Computed:            true,
-			},
-			"public_key": schema.StringAttribute{
-				MarkdownDescription: "The base64-encoded public key",
-				Computed:            true,
-			},
-			"hash_algorithms": schema.ListAttribute{
-				MarkdownDescription: "List of acceptable hash algorithms (h tag)",
-				Computed:            true,
-				ElementType:         types.StringType,
-			},
-			"services": schema.ListAttribute{
-				MarkdownDescription: "List of service types (s tag)",
-				Computed:            true,
-				ElementType:         types.StringType,
-			},
-			"flags": schema.ListAttribute{
-				MarkdownDescription: "List of flags (t tag, e.g., 'y' for testing, 's' for strict)",
-				Computed:            true,
-				ElementType:         types.StringType,
-			},
-			"notes": schema.StringAttribute{
-				MarkdownDescription: "Notes field (n tag)",
-				Computed:            true,
-			},
-			"is_revoked": schema.BoolAttribute{
-				MarkdownDescription: "True if the key is revoked (empty p= tag)",
-				Computed:            true,
-			},
+		Attributes: attributes,
+	}
+}
+
+// dkimKeyAttributes returns the schema of the attributes in dkimKeyModel.
+func dkimKeyAttributes() map[string]schema.Attribute {
+	return map[string]schema.Attribute{
+		"parsed_json": schema.StringAttribute{
+			MarkdownDescription: "The parsed record as a JSON object mapping each tag name to its value, for use with `jsondecode`. " +
+				"Keys are sorted so the value only changes with the record. Matches `parsed_json` of `emaildns_txt`",
+			Computed: true,
+		},
+		"key_type": schema.StringAttribute{
+			MarkdownDescription: "The key algorithm type (rsa or ed25519)",
+			Computed:            true,
+		},
+		"public_key": schema.StringAttribute{
+			MarkdownDescription: "The base64-encoded public key",
+			Computed:            true,
+		},
+		"public_key_pem": schema.StringAttribute{
+			MarkdownDescription: "The public key in PEM (`-----BEGIN PUBLIC KEY-----`) format, for tools that verify signatures or import keys. Ed25519 keys are wrapped in a SubjectPublicKeyInfo like RSA keys. Null when the key is revoked",
+			Computed:            true,
+		},
+		"key_format": schema.StringAttribute{
+			MarkdownDescription: "Encoding of the published public key: `pkix` (an RSA SubjectPublicKeyInfo, as RFC 6376 expects), `pkcs1` (a bare RSAPublicKey), or `raw` (the bare 32-byte Ed25519 key, as RFC 8463 expects). Null when the key is revoked",
+			Computed:            true,
+		},
+		"key_bits": schema.Int64Attribute{
+			MarkdownDescription: "The key size in bits: the modulus size for RSA keys, or 256 for Ed25519. Null when the key is revoked",
+			Computed:            true,
+		},
+		"key_fingerprint": schema.StringAttribute{
+			MarkdownDescription: "Hex-encoded SHA-256 hash of the public key as published (the decoded p tag), for detecting key rotations. Null when the key is revoked",
+			Computed:            true,
+		},
+		"hash_algorithms": schema.ListAttribute{
+			MarkdownDescription: "List of acceptable hash algorithms (h tag)",
+			Computed:            true,
+			ElementType:         types.StringType,
+		},
+		"services": schema.ListAttribute{
+			MarkdownDescription: "List of service types (s tag): `email` or `*`. Defaults to `[\"*\"]` when the tag is absent",
+			Computed:            true,
+			ElementType:         types.StringType,
+		},
+		"flags": schema.ListAttribute{
+			MarkdownDescription: "List of flags (t tag, e.g., 'y' for testing, 's' for strict)",
+			Computed:            true,
+			ElementType:         types.StringType,
+		},
+		"notes": schema.StringAttribute{
+			MarkdownDescription: "Notes field (n tag)",
+			Computed:            true,
+		},
+		"query_method": schema.StringAttribute{
+			MarkdownDescription: "Query method (q tag). Defaults to `dns/txt`, the only standardized method, when the tag is absent",
+			Computed:            true,
+		},
+		"is_revoked": schema.BoolAttribute{
+			MarkdownDescription: "True if the key is revoked (empty p= tag)",
+			Computed:            true,
+		},
+		"is_testing": schema.BoolAttribute{
+			MarkdownDescription: "True if the `y` flag is set (t=y), telling receivers not to enforce DKIM failures",
+			Computed:            true,
+		},
+		"raw_tags": schema.MapAttribute{
+			MarkdownDescription: "Every `tag=value` pair in the record keyed by tag name, including tags this provider does not model",
+			Computed:            true,
+			ElementType:         types.StringType,
 		},
 	}
 }
 
+func (d *DKIMDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	d.providerData = configureProviderData(req, resp)
+}
+
 func (d *DKIMDataSource) ValidateConfig(ctx context.Context, req datasource.ValidateConfigRequest, resp *datasource.ValidateConfigResponse) {
+	defer d.providerData.applyWarningSettings(&resp.Diagnostics)
+
 	var data DKIMDataSourceModel
 
 	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
@@ -91,23 +186,22 @@ func (d *DKIMDataSource) ValidateConfig(ctx context.Context, req datasource.Vali
 		return
 	}
 
+	record := recordInput(data.Record, data.RecordFile, &resp.Diagnostics)
+
 	// Skip validation if record is unknown (e.g., depends on another resource)
-	if data.Record.IsUnknown() {
+	// or could not be determined
+	if record.IsUnknown() || record.IsNull() {
 		return
 	}
 
-	// Validate the DKIM record
-	record := data.Record.ValueString()
-	_, err := ParseDKIM(record)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Invalid DKIM Record",
-			fmt.Sprintf("The DKIM record is malformed: %s\n\nRecord: %s", err.Error(), record),
-		)
-	}
+	var checkDiags diag.Diagnostics
+	validateDKIMRecord(record.ValueString(), &checkDiags)
+	d.providerData.routeDiagnostics(checkDiags, &resp.Diagnostics)
 }
 
 func (d *DKIMDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	defer d.providerData.applyWarningSettings(&resp.Diagnostics)
+
 	var data DKIMDataSourceModel
 
 	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
@@ -115,24 +209,159 @@ func (d *DKIMDataSource) Read(ctx context.Context, req datasource.ReadRequest, r
 		return
 	}
 
+	data.Record = recordInput(data.Record, data.RecordFile, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data.ChangeTicket = d.providerData.checkChangeTicket(data.ChangeTicket, &resp.Diagnostics)
+
 	record := data.Record.ValueString()
+	data.Valid, data.Warnings = d.providerData.checkRecord(
+		func(diags *diag.Diagnostics) { validateDKIMRecord(record, diags) },
+		func(diags *diag.Diagnostics) { d.read(ctx, &data, diags) },
+		&resp.Diagnostics,
+	)
+	if !data.Valid.ValueBool() {
+		resp.Diagnostics.Append(setInvalidRecordState(ctx, req.Config, &resp.State, data.ChangeTicket, data.Valid, data.Warnings)...)
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("record"), data.Record)...)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// read parses the record in data and fills in the computed attributes.
+func (d *DKIMDataSource) read(ctx context.Context, data *DKIMDataSourceModel, diags *diag.Diagnostics) {
+	readDKIMKey(ctx, d.providerData, data.Record.ValueString(), &data.dkimKeyModel, diags)
+
+	if !diags.HasError() && data.IsRevoked.ValueBool() && !data.AllowRevoked.IsNull() && !data.AllowRevoked.ValueBool() {
+		diags.AddError(
+			summaryDKIMRevokedKey,
+			"The DKIM record has an empty p= tag, which revokes the key, so every signature made with this selector fails. "+
+				"Publish the public key, or set allow_revoked to true if the revocation is intended.",
+		)
+	}
+}
+
+// readDKIMKey parses a DKIM key record and fills in the attributes of data.
+func readDKIMKey(ctx context.Context, p *providerData, record string, data *dkimKeyModel, diags *diag.Diagnostics) {
 	parsed, err := ParseDKIM(record)
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Invalid DKIM Record",
+		diags.AddError(
+			summaryDKIMInvalidRecord,
 			fmt.Sprintf("The DKIM record is malformed: %s", err.Error()),
 		)
 		return
 	}
+	data.ParsedJSON = parsedRecordJSON("dkim", record, diags)
+
+	if parsed.KeyType == "rsa" && !parsed.IsRevoked {
+		var minBits int64
+		if p != nil {
+			minBits = p.minDKIMKeyBits
+		}
+		switch {
+		case int64(parsed.KeyBits) < minBits:
+			diags.AddError(
+				summaryDKIMKeyTooShort,
+				fmt.Sprintf("The DKIM record publishes a %d-bit RSA key, but the provider requires at least %d bits (min_dkim_key_bits). "+
+					"Rotate to a longer key.", parsed.KeyBits, minBits),
+			)
+			return
+		case parsed.KeyBits < recommendedDKIMKeyBits:
+			diags.AddWarning(
+				summaryDKIMWeakKey,
+				fmt.Sprintf("The DKIM record publishes a %d-bit RSA key. Keys under %d bits are considered weak; "+
+					"consider rotating to a %d-bit key.", parsed.KeyBits, recommendedDKIMKeyBits, recommendedDKIMKeyBits),
+			)
+		}
+	}
+
+	if len(parsed.UnknownTags) > 0 {
+		diags.AddWarning(
+			summaryDKIMUnknownTag,
+			fmt.Sprintf("The DKIM record has tags that are not part of a DKIM key record: %s. "+
+				"Verifiers ignore them, so check them for typos or stray text.", strings.Join(parsed.UnknownTags, ", ")),
+		)
+	}
+
+	if parsed.QueryMethod != "dns/txt" {
+		diags.AddWarning(
+			summaryDKIMQueryMethod,
+			fmt.Sprintf("The DKIM record sets q=%s, but dns/txt is the only standardized query method. "+
+				"Verifiers that do not support the method cannot fetch the key, so signatures fail to verify. Remove the q tag or set q=dns/txt.", parsed.QueryMethod),
+		)
+	}
+
+	if k, ok := parsed.Tags["k"]; ok && k != strings.ToLower(k) {
+		diags.AddWarning(
+			summaryDKIMKeyTypeCase,
+			fmt.Sprintf("The DKIM record sets k=%s. The key type is case-insensitive, but some verifiers compare it literally; publish k=%s instead.", k, strings.ToLower(k)),
+		)
+	}
+
+	if parsed.KeyType == "rsa" && !parsed.IsRevoked && parsed.RSAExponent != standardRSAExponent {
+		diags.AddWarning(
+			summaryDKIMUnusualExponent,
+			fmt.Sprintf("The DKIM record publishes an RSA key with public exponent %d instead of %d. "+
+				"This usually means the key was generated by broken or unusual tooling; consider generating a new key.", parsed.RSAExponent, standardRSAExponent),
+		)
+	}
+
+	if parsed.KeyFormat == "pkcs1" {
+		diags.AddWarning(
+			summaryDKIMPKCS1Key,
+			"The DKIM record publishes a bare PKCS#1 RSA key, but RFC 6376 expects a SubjectPublicKeyInfo (PKIX) key and some verifiers reject other encodings. "+
+				"Re-export the key in PKIX form, e.g. with `openssl rsa -pubout`.",
+		)
+	}
+
+	if slices.Contains(parsed.HashAlgorithms, "sha1") {
+		detail := "The DKIM record allows sha1 signatures (h tag). RFC 8301 deprecates sha1 for DKIM and verifiers must not accept it. "
+		if !slices.Contains(parsed.HashAlgorithms, "sha256") {
+			detail += "Since sha256 is not allowed either, no signature made with this key will verify. "
+		}
+		diags.AddWarning(summaryDKIMSHA1Hash, detail+"Remove sha1 from the h tag, or drop the tag to allow all algorithms.")
+	}
+
+	if len(parsed.Services) == 0 {
+		diags.AddWarning(
+			summaryDKIMServiceRestricted,
+			"The DKIM record sets an empty s tag, which allows the key for no service types, so receivers may reject email signatures made with it. "+
+				"Remove the s tag or set s=email.",
+		)
+	}
+
+	isTesting := slices.Contains(parsed.Flags, "y")
+	if isTesting {
+		diags.AddWarning(
+			summaryDKIMTestingMode,
+			"The DKIM record sets t=y, so receivers treat the domain as testing DKIM and do not act on signature failures. "+
+				"Remove the y flag once signing is working.",
+		)
+	}
 
 	// Set computed attributes
 	data.KeyType = types.StringValue(parsed.KeyType)
 	data.IsRevoked = types.BoolValue(parsed.IsRevoked)
+	data.IsTesting = types.BoolValue(isTesting)
 
 	if parsed.PublicKey != "" {
 		data.PublicKey = types.StringValue(parsed.PublicKey)
+		data.KeyBits = types.Int64Value(int64(parsed.KeyBits))
+		data.KeyFingerprint = types.StringValue(parsed.KeyFingerprint)
+		data.PublicKeyPEM = types.StringValue(parsed.PublicKeyPEM)
+		data.KeyFormat = types.StringNull()
+		if parsed.KeyFormat != "" {
+			data.KeyFormat = types.StringValue(parsed.KeyFormat)
+		}
 	} else {
 		data.PublicKey = types.StringNull()
+		data.KeyBits = types.Int64Null()
+		data.KeyFingerprint = types.StringNull()
+		data.PublicKeyPEM = types.StringNull()
+		data.KeyFormat = types.StringNull()
 	}
 
 	if parsed.Notes != "" {
@@ -141,10 +370,22 @@ func (d *DKIMDataSource) Read(ctx context.Context, req datasource.ReadRequest, r
 		data.Notes = types.StringNull()
 	}
 
+	data.QueryMethod = types.StringValue(parsed.QueryMethod)
+
 	// Convert string slices to Terraform lists
-	data.HashAlgorithms = convertStringSliceToList(ctx, parsed.HashAlgorithms, &resp.Diagnostics)
-	data.Services = convertStringSliceToList(ctx, parsed.Services, &resp.Diagnostics)
-	data.Flags = convertStringSliceToList(ctx, parsed.Flags, &resp.Diagnostics)
+	data.HashAlgorithms = convertStringSliceToList(ctx, parsed.HashAlgorithms, diags)
+	data.Services = convertStringSliceToList(ctx, parsed.Services, diags)
+	data.Flags = convertStringSliceToList(ctx, parsed.Flags, diags)
+	data.RawTags = convertStringMapToMap(ctx, parsed.Tags, diags)
+}
 
-	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+// validateDKIMRecord reports problems with a DKIM record.
+func validateDKIMRecord(record string, diags *diag.Diagnostics) {
+	_, err := ParseDKIM(record)
+	if err != nil {
+		diags.AddError(
+			summaryDKIMInvalidRecord,
+			fmt.Sprintf("The DKIM record is malformed: %s\n\nRecord: %s", err.Error(), record),
+		)
+	}
 }
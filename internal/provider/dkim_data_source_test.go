@@ -0,0 +1,230 @@
+package provider
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+const (
+	testDKIMRSA1024 = "v=DKIM1; k=rsa; p=MIGfMA0GCSqGSIb3DQEBAQUAA4GNADCBiQKBgQDHUigNmWXWQU1xMaOc4Xq1L1Lo8y8qFzqZ6rQNLzb+j3YwjBwEHC9oNWcXqrAqsBgBfJmC7BDL0x6IdCaNEyL3Q3KvQZPksLLzqN5IaMTWYhE7bX4k8HKkAWrJJVaQaXW7/HmAK8Y8htTPxCmKJHQI8V3dWH/JOoq3BlJZu2e22QIDAQAB"
+	testDKIMEd25519 = "v=DKIM1; k=ed25519; p=11qYAYKxCrfVS/7TyWQHOg7hcvPapiMlrwIaaPcHURo="
+)
+
+func TestDKIMKeyBitsDiagnostics(t *testing.T) {
+	tests := []struct {
+		name        string
+		record      string
+		provider    *providerData
+		wantSummary string
+		wantError   bool
+	}{
+		{name: "1024-bit key", record: testDKIMRSA1024, wantSummary: "Weak DKIM Key"},
+		{name: "below minimum", record: testDKIMRSA1024, provider: &providerData{minDKIMKeyBits: 2048}, wantSummary: "DKIM Key Too Short", wantError: true},
+		{name: "at minimum", record: testDKIMRSA1024, provider: &providerData{minDKIMKeyBits: 1024}, wantSummary: "Weak DKIM Key"},
+		{name: "ed25519 key", record: testDKIMEd25519, provider: &providerData{minDKIMKeyBits: 2048}},
+		{name: "revoked key", record: "v=DKIM1; p=", provider: &providerData{minDKIMKeyBits: 2048}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var diags diag.Diagnostics
+			data := DKIMDataSourceModel{Record: types.StringValue(tt.record)}
+			(&DKIMDataSource{providerData: tt.provider}).read(context.Background(), &data, &diags)
+
+			if diags.HasError() != tt.wantError {
+				t.Errorf("HasError() = %v, want %v (diagnostics: %v)", diags.HasError(), tt.wantError, diags)
+			}
+			var got string
+			if len(diags) > 0 {
+				got = diags[0].Summary()
+			}
+			if len(diags) > 1 || got != tt.wantSummary {
+				t.Errorf("diagnostics = %v, want one %q", diags, tt.wantSummary)
+			}
+		})
+	}
+}
+
+func TestDKIMTestingFlag(t *testing.T) {
+	tests := []struct {
+		name        string
+		record      string
+		wantTesting bool
+	}{
+		{name: "no flags", record: testDKIMEd25519},
+		{name: "strict only", record: testDKIMEd25519 + "; t=s"},
+		{name: "testing", record: testDKIMEd25519 + "; t=y", wantTesting: true},
+		{name: "testing and strict", record: testDKIMEd25519 + "; t=s:y", wantTesting: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var diags diag.Diagnostics
+			data := DKIMDataSourceModel{Record: types.StringValue(tt.record)}
+			(&DKIMDataSource{}).read(context.Background(), &data, &diags)
+
+			if data.IsTesting.ValueBool() != tt.wantTesting {
+				t.Errorf("is_testing = %v, want %v", data.IsTesting.ValueBool(), tt.wantTesting)
+			}
+			if warned := len(diags) == 1 && diags[0].Summary() == "DKIM Testing Mode"; warned != tt.wantTesting {
+				t.Errorf("diagnostics = %v, want testing warning %v", diags, tt.wantTesting)
+			}
+		})
+	}
+}
+
+func TestDKIMHashAlgorithmDiagnostics(t *testing.T) {
+	tests := []struct {
+		name     string
+		hashes   string
+		wantWarn bool
+	}{
+		{name: "sha256", hashes: "sha256"},
+		{name: "sha1 allowed", hashes: "sha1:sha256", wantWarn: true},
+		{name: "sha1 only", hashes: "sha1", wantWarn: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var diags diag.Diagnostics
+			data := DKIMDataSourceModel{Record: types.StringValue(testDKIMEd25519 + "; h=" + tt.hashes)}
+			(&DKIMDataSource{}).read(context.Background(), &data, &diags)
+
+			if warned := len(diags) == 1 && diags[0].Summary() == "Deprecated DKIM Hash Algorithm"; warned != tt.wantWarn {
+				t.Errorf("diagnostics = %v, want sha1 warning %v", diags, tt.wantWarn)
+			}
+		})
+	}
+}
+
+func TestDKIMEmptyServicesWarning(t *testing.T) {
+	var diags diag.Diagnostics
+	data := DKIMDataSourceModel{Record: types.StringValue(testDKIMEd25519 + "; s=")}
+	(&DKIMDataSource{}).read(context.Background(), &data, &diags)
+
+	if len(diags) != 1 || diags[0].Summary() != "DKIM Key Restricted From Email" {
+		t.Errorf("diagnostics = %v, want the empty s tag warning", diags)
+	}
+}
+
+func TestDKIMKeyFormat(t *testing.T) {
+	pkix, err := ParseDKIM(testDKIMRSA1024)
+	if err != nil {
+		t.Fatalf("ParseDKIM() error = %v", err)
+	}
+	der, _ := base64.StdEncoding.DecodeString(pkix.PublicKey)
+	pub, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		t.Fatalf("x509.ParsePKIXPublicKey() error = %v", err)
+	}
+	pkcs1 := "v=DKIM1; k=rsa; p=" + base64.StdEncoding.EncodeToString(x509.MarshalPKCS1PublicKey(pub.(*rsa.PublicKey)))
+
+	tests := []struct {
+		name       string
+		record     string
+		wantFormat string
+		wantWarn   bool
+	}{
+		{name: "pkix", record: testDKIMRSA1024, wantFormat: "pkix"},
+		{name: "pkcs1", record: pkcs1, wantFormat: "pkcs1", wantWarn: true},
+		{name: "ed25519", record: testDKIMEd25519, wantFormat: "raw"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var diags diag.Diagnostics
+			data := DKIMDataSourceModel{Record: types.StringValue(tt.record)}
+			(&DKIMDataSource{}).read(context.Background(), &data, &diags)
+
+			if got := data.KeyFormat.ValueString(); got != tt.wantFormat {
+				t.Errorf("key_format = %q, want %q", got, tt.wantFormat)
+			}
+			warned := false
+			for _, d := range diags {
+				warned = warned || d.Summary() == "Non-Standard DKIM Key Encoding"
+			}
+			if warned != tt.wantWarn {
+				t.Errorf("diagnostics = %v, want encoding warning %v", diags, tt.wantWarn)
+			}
+		})
+	}
+}
+
+func TestDKIMExponentWarning(t *testing.T) {
+	parsed, err := ParseDKIM(testDKIMRSA1024)
+	if err != nil {
+		t.Fatalf("ParseDKIM() error = %v", err)
+	}
+	der, _ := base64.StdEncoding.DecodeString(parsed.PublicKey)
+	pub, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		t.Fatalf("x509.ParsePKIXPublicKey() error = %v", err)
+	}
+	key := *pub.(*rsa.PublicKey)
+	key.E = 3
+	der, err = x509.MarshalPKIXPublicKey(&key)
+	if err != nil {
+		t.Fatalf("x509.MarshalPKIXPublicKey() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		record   string
+		wantWarn bool
+	}{
+		{name: "65537", record: testDKIMRSA1024},
+		{name: "3", record: "v=DKIM1; k=rsa; p=" + base64.StdEncoding.EncodeToString(der), wantWarn: true},
+		{name: "ed25519", record: testDKIMEd25519},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var diags diag.Diagnostics
+			data := DKIMDataSourceModel{Record: types.StringValue(tt.record)}
+			(&DKIMDataSource{}).read(context.Background(), &data, &diags)
+
+			warned := false
+			for _, d := range diags {
+				warned = warned || d.Summary() == "Unusual DKIM Key Exponent"
+			}
+			if warned != tt.wantWarn {
+				t.Errorf("diagnostics = %v, want exponent warning %v", diags, tt.wantWarn)
+			}
+		})
+	}
+}
+
+func TestDKIMAllowRevoked(t *testing.T) {
+	tests := []struct {
+		name         string
+		record       string
+		allowRevoked types.Bool
+		wantErr      bool
+	}{
+		{name: "revoked by default", record: "v=DKIM1; p=", allowRevoked: types.BoolNull()},
+		{name: "revoked allowed", record: "v=DKIM1; p=", allowRevoked: types.BoolValue(true)},
+		{name: "revoked disallowed", record: "v=DKIM1; p=", allowRevoked: types.BoolValue(false), wantErr: true},
+		{name: "live key disallowed", record: testDKIMEd25519, allowRevoked: types.BoolValue(false)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var diags diag.Diagnostics
+			data := DKIMDataSourceModel{Record: types.StringValue(tt.record), AllowRevoked: tt.allowRevoked}
+			(&DKIMDataSource{}).read(context.Background(), &data, &diags)
+
+			if diags.HasError() != tt.wantErr {
+				t.Errorf("diagnostics = %v, wantErr %v", diags, tt.wantErr)
+			}
+			if tt.wantErr && diags.Errors()[0].Summary() != "Revoked DKIM Key" {
+				t.Errorf("error = %q, want %q", diags.Errors()[0].Summary(), "Revoked DKIM Key")
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &DKIMKeyBitsFunction{}
+
+func NewDKIMKeyBitsFunction() function.Function {
+	return &DKIMKeyBitsFunction{}
+}
+
+// DKIMKeyBitsFunction defines the function implementation.
+type DKIMKeyBitsFunction struct{}
+
+func (f *DKIMKeyBitsFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "dkim_key_bits"
+}
+
+func (f *DKIMKeyBitsFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Get the key size of a DKIM record",
+		MarkdownDescription: "Returns the size in bits of the public key published by a DKIM record, the same as the `key_bits` attribute " +
+			"of the `emaildns_dkim` data source. Ed25519 keys are always 256 bits. Returns `0` when the record is invalid or the key is revoked " +
+			"(`p=` is empty), so the result can be compared directly in conditions.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "record",
+				MarkdownDescription: "The DKIM record content (e.g., `v=DKIM1; k=rsa; p=MIIBIjANBg...`)",
+			},
+		},
+		Return: function.Int64Return{},
+	}
+}
+
+func (f *DKIMKeyBitsFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var record string
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &record))
+	if resp.Error != nil {
+		return
+	}
+
+	var bits int64
+	if parsed, err := ParseDKIM(record); err == nil {
+		bits = int64(parsed.KeyBits)
+	}
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, bits))
+}
@@ -0,0 +1,38 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestDKIMKeyBitsFunction(t *testing.T) {
+	tests := []struct {
+		name   string
+		record string
+		want   int64
+	}{
+		{name: "rsa", record: testDKIMRSA1024, want: 1024},
+		{name: "ed25519", record: testDKIMEd25519, want: 256},
+		{name: "revoked", record: "v=DKIM1; p=", want: 0},
+		{name: "invalid", record: "v=DKIM1; k=rsa; p=not-base64!", want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := function.RunRequest{Arguments: function.NewArgumentsData([]attr.Value{types.StringValue(tt.record)})}
+			resp := function.RunResponse{Result: function.NewResultData(types.Int64Unknown())}
+			NewDKIMKeyBitsFunction().Run(context.Background(), req, &resp)
+
+			if resp.Error != nil {
+				t.Fatalf("Run() error = %v", resp.Error)
+			}
+			if got := resp.Result.Value(); !got.Equal(types.Int64Value(tt.want)) {
+				t.Errorf("Run() = %v, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,263 @@
+package provider
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64default"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// defaultDKIMKeyBits is the RSA key size generated when rsa_bits is not set.
+const defaultDKIMKeyBits = 2048
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var (
+	_ resource.Resource                   = &DKIMKeyPairResource{}
+	_ resource.ResourceWithValidateConfig = &DKIMKeyPairResource{}
+)
+
+func NewDKIMKeyPairResource() resource.Resource {
+	return &DKIMKeyPairResource{}
+}
+
+// DKIMKeyPairResource generates a DKIM signing key and the TXT record that
+// publishes its public half.
+type DKIMKeyPairResource struct{}
+
+// DKIMKeyPairResourceModel describes the resource data model.
+type DKIMKeyPairResourceModel struct {
+	ID             types.String `tfsdk:"id"`
+	KeyType        types.String `tfsdk:"key_type"`
+	RSABits        types.Int64  `tfsdk:"rsa_bits"`
+	PrivateKeyPEM  types.String `tfsdk:"private_key_pem"`
+	PublicKey      types.String `tfsdk:"public_key"`
+	KeyFingerprint types.String `tfsdk:"key_fingerprint"`
+	TXTRecord      types.String `tfsdk:"txt_record"`
+}
+
+func (r *DKIMKeyPairResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_dkim_keypair"
+}
+
+func (r *DKIMKeyPairResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	// Every generated value is kept until the key is replaced
+	keep := []planmodifier.String{stringplanmodifier.UseStateForUnknown()}
+
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Generates a DKIM key pair and the TXT record that publishes its public key. " +
+			"The key is generated once and kept in state; it is only replaced when `key_type` or `rsa_bits` changes.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				MarkdownDescription: "The key fingerprint",
+				Computed:            true,
+				PlanModifiers:       keep,
+			},
+			"key_type": schema.StringAttribute{
+				MarkdownDescription: "The key algorithm: `rsa` or `ed25519`. Defaults to `rsa`. Changing it generates a new key",
+				Optional:            true,
+				Computed:            true,
+				Default:             stringdefault.StaticString("rsa"),
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.RequiresReplace()},
+			},
+			"rsa_bits": schema.Int64Attribute{
+				MarkdownDescription: fmt.Sprintf("The RSA key size in bits, at least 1024. Defaults to `%d`. Ignored for Ed25519 keys. Changing it generates a new key", defaultDKIMKeyBits),
+				Optional:            true,
+				Computed:            true,
+				Default:             int64default.StaticInt64(defaultDKIMKeyBits),
+				PlanModifiers:       []planmodifier.Int64{int64planmodifier.RequiresReplace()},
+			},
+			"private_key_pem": schema.StringAttribute{
+				MarkdownDescription: "The private key in PKCS#8 PEM format, for configuring the signing mail server",
+				Computed:            true,
+				Sensitive:           true,
+				PlanModifiers:       keep,
+			},
+			"public_key": schema.StringAttribute{
+				MarkdownDescription: "The base64-encoded public key, as published in the `p` tag",
+				Computed:            true,
+				PlanModifiers:       keep,
+			},
+			"key_fingerprint": schema.StringAttribute{
+				MarkdownDescription: "Hex-encoded SHA-256 hash of the published public key, matching `key_fingerprint` of `emaildns_dkim`",
+				Computed:            true,
+				PlanModifiers:       keep,
+			},
+			"txt_record": schema.StringAttribute{
+				MarkdownDescription: "The DKIM TXT record to publish at `<selector>._domainkey.<domain>` (e.g., `v=DKIM1; k=rsa; p=MIIBIjAN...`)",
+				Computed:            true,
+				PlanModifiers:       keep,
+			},
+		},
+	}
+}
+
+func (r *DKIMKeyPairResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	defer addDiagnosticCodes(&resp.Diagnostics)
+
+	var data DKIMKeyPairResourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	keyType := data.KeyType.ValueString()
+	if !data.KeyType.IsUnknown() && !data.KeyType.IsNull() && keyType != "rsa" && keyType != "ed25519" {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("key_type"),
+			summaryDKIMInvalidKeyType,
+			fmt.Sprintf("key_type must be rsa or ed25519, got %q.", keyType),
+		)
+	}
+
+	if data.RSABits.IsUnknown() || data.RSABits.IsNull() {
+		return
+	}
+	if keyType == "ed25519" {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("rsa_bits"),
+			summaryDKIMInvalidRSAKeySize,
+			"rsa_bits only applies to RSA keys. Remove it when key_type is ed25519.",
+		)
+		return
+	}
+	bits := data.RSABits.ValueInt64()
+	switch {
+	case bits < 1024:
+		resp.Diagnostics.AddAttributeError(
+			path.Root("rsa_bits"),
+			summaryDKIMInvalidRSAKeySize,
+			fmt.Sprintf("rsa_bits must be at least 1024 (RFC 8301), got %d.", bits),
+		)
+	case bits < recommendedDKIMKeyBits:
+		resp.Diagnostics.AddAttributeWarning(
+			path.Root("rsa_bits"),
+			summaryDKIMWeakKey,
+			fmt.Sprintf("RSA keys under %d bits are considered weak; consider rsa_bits = %d.", recommendedDKIMKeyBits, recommendedDKIMKeyBits),
+		)
+	}
+}
+
+func (r *DKIMKeyPairResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	defer addDiagnosticCodes(&resp.Diagnostics)
+
+	var data DKIMKeyPairResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	privateKeyPEM, txtRecord, err := generateDKIMKeyPair(data.KeyType.ValueString(), int(data.RSABits.ValueInt64()))
+	if err != nil {
+		resp.Diagnostics.AddError(
+			summaryDKIMKeyGenerationFailed,
+			fmt.Sprintf("Could not generate the DKIM key pair: %s", err.Error()),
+		)
+		return
+	}
+
+	parsed, err := ParseDKIM(txtRecord)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			summaryDKIMKeyGenerationFailed,
+			fmt.Sprintf("The generated DKIM record is invalid: %s", err.Error()),
+		)
+		return
+	}
+
+	data.ID = types.StringValue(parsed.KeyFingerprint)
+	data.PrivateKeyPEM = types.StringValue(privateKeyPEM)
+	data.PublicKey = types.StringValue(parsed.PublicKey)
+	data.KeyFingerprint = types.StringValue(parsed.KeyFingerprint)
+	data.TXTRecord = types.StringValue(txtRecord)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// Read keeps the stored key, since nothing outside Terraform can change it.
+func (r *DKIMKeyPairResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data DKIMKeyPairResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// Update is never called with a changed key, because every configurable
+// attribute requires replacement.
+func (r *DKIMKeyPairResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	defer addDiagnosticCodes(&resp.Diagnostics)
+
+	var data DKIMKeyPairResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// Delete only removes the key from state.
+func (r *DKIMKeyPairResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+}
+
+// generateDKIMKeyPair generates a key of the given type and returns the
+// private key as PKCS#8 PEM and the DKIM TXT record publishing the public key.
+// bits is only used for RSA keys.
+func generateDKIMKeyPair(keyType string, bits int) (privateKeyPEM, txtRecord string, err error) {
+	var private crypto.Signer
+	var public []byte
+
+	switch keyType {
+	case "rsa":
+		key, err := rsa.GenerateKey(rand.Reader, bits)
+		if err != nil {
+			return "", "", err
+		}
+		public, err = x509.MarshalPKIXPublicKey(&key.PublicKey)
+		if err != nil {
+			return "", "", err
+		}
+		private = key
+	case "ed25519":
+		// RFC 8463 publishes the bare 32-byte key rather than a
+		// SubjectPublicKeyInfo
+		pub, key, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return "", "", err
+		}
+		public = pub
+		private = key
+	default:
+		return "", "", fmt.Errorf("unsupported key type: %s (expected rsa or ed25519)", keyType)
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return "", "", err
+	}
+	privateKeyPEM = string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+	txtRecord = fmt.Sprintf("v=DKIM1; k=%s; p=%s", keyType, base64.StdEncoding.EncodeToString(public))
+	return privateKeyPEM, txtRecord, nil
+}
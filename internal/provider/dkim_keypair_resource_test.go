@@ -0,0 +1,59 @@
+package provider
+
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+)
+
+func TestGenerateDKIMKeyPair(t *testing.T) {
+	tests := []struct {
+		name     string
+		keyType  string
+		bits     int
+		wantBits int
+		wantErr  bool
+	}{
+		{name: "rsa", keyType: "rsa", bits: 2048, wantBits: 2048},
+		{name: "ed25519", keyType: "ed25519", wantBits: 256},
+		{name: "unknown type", keyType: "dsa", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			privateKeyPEM, txtRecord, err := generateDKIMKeyPair(tt.keyType, tt.bits)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("generateDKIMKeyPair() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			parsed, err := ParseDKIM(txtRecord)
+			if err != nil {
+				t.Fatalf("ParseDKIM(%q) error = %v", txtRecord, err)
+			}
+			if parsed.KeyType != tt.keyType || parsed.KeyBits != tt.wantBits {
+				t.Errorf("generated %s key with %d bits, want %s with %d", parsed.KeyType, parsed.KeyBits, tt.keyType, tt.wantBits)
+			}
+
+			block, _ := pem.Decode([]byte(privateKeyPEM))
+			if block == nil || block.Type != "PRIVATE KEY" {
+				t.Fatalf("private key is not a PEM PRIVATE KEY block")
+			}
+			key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+			if err != nil {
+				t.Fatalf("x509.ParsePKCS8PrivateKey() error = %v", err)
+			}
+			public, err := x509.MarshalPKIXPublicKey(key.(crypto.Signer).Public())
+			if err != nil {
+				t.Fatalf("x509.MarshalPKIXPublicKey() error = %v", err)
+			}
+			block, _ = pem.Decode([]byte(parsed.PublicKeyPEM))
+			if string(block.Bytes) != string(public) {
+				t.Error("private key does not match the published public key")
+			}
+		})
+	}
+}
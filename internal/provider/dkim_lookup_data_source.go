@@ -0,0 +1,169 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"maps"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var (
+	_ datasource.DataSource                   = &DKIMLookupDataSource{}
+	_ datasource.DataSourceWithValidateConfig = &DKIMLookupDataSource{}
+	_ datasource.DataSourceWithConfigure      = &DKIMLookupDataSource{}
+)
+
+func NewDKIMLookupDataSource() datasource.DataSource {
+	return &DKIMLookupDataSource{}
+}
+
+// DKIMLookupDataSource defines the data source implementation.
+type DKIMLookupDataSource struct {
+	providerData *providerData
+}
+
+// DKIMLookupDataSourceModel describes the data source data model.
+type DKIMLookupDataSourceModel struct {
+	Domain       types.String `tfsdk:"domain"`
+	Selector     types.String `tfsdk:"selector"`
+	ChangeTicket types.String `tfsdk:"change_ticket"`
+	Name         types.String `tfsdk:"name"`
+	Record       types.String `tfsdk:"record"`
+	Valid        types.Bool   `tfsdk:"valid"`
+	Warnings     types.List   `tfsdk:"warnings"`
+	dkimKeyModel
+}
+
+func (d *DKIMLookupDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_dkim_lookup"
+}
+
+func (d *DKIMLookupDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	attributes := map[string]schema.Attribute{
+		"domain": schema.StringAttribute{
+			MarkdownDescription: "The signing domain (d= in signatures, e.g., `example.com`)",
+			Required:            true,
+		},
+		"selector": schema.StringAttribute{
+			MarkdownDescription: "The selector of the key (s= in signatures, e.g., `google`)",
+			Required:            true,
+		},
+		"change_ticket": changeTicketAttribute(),
+		"name": schema.StringAttribute{
+			MarkdownDescription: "The name looked up: `<selector>._domainkey.<domain>`, lowercased",
+			Computed:            true,
+		},
+		"record": schema.StringAttribute{
+			MarkdownDescription: "The DKIM key record published at `name`",
+			Computed:            true,
+		},
+		"valid":    validAttribute(),
+		"warnings": warningsAttribute(),
+	}
+	maps.Copy(attributes, dkimKeyAttributes())
+
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Looks up the DKIM key record published at `<selector>._domainkey.<domain>` in live DNS and validates it " +
+			"with the same checks as the `emaildns_dkim` data source, to verify that a key is actually published correctly.",
+
+		Attributes: attributes,
+	}
+}
+
+func (d *DKIMLookupDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	d.providerData = configureProviderData(req, resp)
+}
+
+func (d *DKIMLookupDataSource) ValidateConfig(ctx context.Context, req datasource.ValidateConfigRequest, resp *datasource.ValidateConfigResponse) {
+	defer d.providerData.applyWarningSettings(&resp.Diagnostics)
+
+	var data DKIMLookupDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Values that are unknown (e.g., depend on another resource) are skipped
+	if !data.Domain.IsUnknown() && !data.Domain.IsNull() {
+		if err := checkHostname(data.Domain.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("domain"),
+				summaryDomainInvalid,
+				fmt.Sprintf("The domain is invalid: %s", err.Error()),
+			)
+		}
+	}
+	if !data.Selector.IsUnknown() && !data.Selector.IsNull() {
+		if err := checkDKIMSelector(data.Selector.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("selector"),
+				summaryDKIMInvalidSelector,
+				fmt.Sprintf("The selector is invalid: %s", err.Error()),
+			)
+		}
+	}
+}
+
+func (d *DKIMLookupDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	defer d.providerData.applyWarningSettings(&resp.Diagnostics)
+
+	var data DKIMLookupDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data.ChangeTicket = d.providerData.checkChangeTicket(data.ChangeTicket, &resp.Diagnostics)
+
+	// The record is only known once it is looked up, so it is validated
+	// along with the read
+	data.Valid, data.Warnings = d.providerData.checkRecord(
+		func(*diag.Diagnostics) {},
+		func(diags *diag.Diagnostics) { d.read(ctx, &data, diags) },
+		&resp.Diagnostics,
+	)
+	if !data.Valid.ValueBool() {
+		resp.Diagnostics.Append(setInvalidRecordState(ctx, req.Config, &resp.State, data.ChangeTicket, data.Valid, data.Warnings)...)
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("name"), data.Name)...)
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("record"), data.Record)...)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// read looks up the key record of the configured selector and fills in the
+// computed attributes.
+func (d *DKIMLookupDataSource) read(ctx context.Context, data *DKIMLookupDataSourceModel, diags *diag.Diagnostics) {
+	result := lookupDKIMSelector(ctx, d.providerData.dnsResolver(), data.Domain.ValueString(), data.Selector.ValueString())
+	data.Name = types.StringValue(result.Name)
+	if result.Record != "" {
+		data.Record = types.StringValue(result.Record)
+	}
+
+	switch result.Status {
+	case dkimSelectorMissing:
+		diags.AddError(
+			summaryDKIMRecordNotFound,
+			fmt.Sprintf("No TXT record is published at %s. Publish the DKIM key record there, or check the selector and domain.", result.Name),
+		)
+	case dkimSelectorError:
+		diags.AddError(lookupFailure(
+			summaryDKIMLookupFailed,
+			fmt.Sprintf("Unable to look up the DKIM record at %s: %s", result.Name, result.Err.Error()),
+			result.Err,
+		))
+	case dkimSelectorInvalid:
+		validateDKIMRecord(result.Record, diags)
+	default:
+		readDKIMKey(ctx, d.providerData, result.Record, &data.dkimKeyModel, diags)
+	}
+}
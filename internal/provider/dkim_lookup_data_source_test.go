@@ -0,0 +1,56 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestDKIMLookupRead(t *testing.T) {
+	d := &DKIMLookupDataSource{providerData: &providerData{
+		resolver: &failingTXTResolver{
+			fakeResolver: &fakeResolver{txt: map[string][]string{
+				"google._domainkey.example.com": {testDKIMEd25519},
+				"s1._domainkey.example.com":     {"v=DKIM1; k=dsa; p=abc"},
+			}},
+			name: "k1._domainkey.example.com",
+		},
+	}}
+
+	tests := []struct {
+		selector string
+		wantName string
+		wantErr  string
+	}{
+		{selector: "Google", wantName: "google._domainkey.example.com"},
+		{selector: "s1", wantName: "s1._domainkey.example.com", wantErr: "Invalid DKIM Record"},
+		{selector: "default", wantName: "default._domainkey.example.com", wantErr: "DKIM Record Not Found"},
+		{selector: "k1", wantName: "k1._domainkey.example.com", wantErr: "Temporary DNS Failure"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			data := DKIMLookupDataSourceModel{Domain: types.StringValue("example.com"), Selector: types.StringValue(tt.selector)}
+			var diags diag.Diagnostics
+			d.read(context.Background(), &data, &diags)
+
+			if data.Name.ValueString() != tt.wantName {
+				t.Errorf("name = %q, want %q", data.Name.ValueString(), tt.wantName)
+			}
+			if tt.wantErr != "" {
+				if !diags.HasError() || diags.Errors()[0].Summary() != tt.wantErr {
+					t.Errorf("diagnostics = %v, want error %q", diags, tt.wantErr)
+				}
+				return
+			}
+			if diags.HasError() {
+				t.Fatalf("diagnostics = %v", diags)
+			}
+			if data.Record.ValueString() != testDKIMEd25519 || data.KeyType.ValueString() != "ed25519" {
+				t.Errorf("record = %q, key_type = %q", data.Record.ValueString(), data.KeyType.ValueString())
+			}
+		})
+	}
+}
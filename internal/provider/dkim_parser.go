@@ -2,10 +2,14 @@ package provider
 
 import (
 	"crypto/rsa"
+	"crypto/sha256"
 	"crypto/x509"
 	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
 
 	"golang.org/x/crypto/ed25519"
@@ -13,13 +17,28 @@ import (
 
 // DKIMRecord holds the parsed DKIM public key record.
 type DKIMRecord struct {
-	KeyType        string   // "k" tag - rsa or ed25519, defaults to rsa
-	PublicKey      string   // "p" tag - base64 encoded public key
-	HashAlgorithms []string // "h" tag - acceptable hash algorithms
-	Services       []string // "s" tag - service types
-	Flags          []string // "t" tag - flags (y for testing, s for strict)
-	Notes          string   // "n" tag - notes
-	IsRevoked      bool     // true if p= is empty (key revoked)
+	KeyType        string            // "k" tag - rsa or ed25519, defaults to rsa
+	PublicKey      string            // "p" tag - base64 encoded public key
+	KeyBits        int               // key size in bits, zero if revoked
+	KeyFingerprint string            // hex SHA-256 of the decoded p tag, empty if revoked
+	PublicKeyPEM   string            // PKIX public key in PEM armor, empty if revoked
+	KeyFormat      string            // "pkix" or "pkcs1" for RSA keys, "raw" for Ed25519, empty if revoked
+	RSAExponent    int               // public exponent of RSA keys, zero otherwise
+	HashAlgorithms []string          // "h" tag - acceptable hash algorithms
+	Services       []string          // "s" tag - service types, defaults to "*"
+	Flags          []string          // "t" tag - flags (y for testing, s for strict)
+	Notes          string            // "n" tag - notes
+	QueryMethod    string            // "q" tag - query method, defaults to dns/txt
+	IsRevoked      bool              // true if p= is empty (key revoked)
+	Tags           map[string]string // every tag=value pair, including unknown tags
+	UnknownTags    []string          // sorted names of tags outside knownDKIMTags
+}
+
+// knownDKIMTags lists the tags of a DKIM key record. g (granularity) was
+// removed by RFC 6376 and q (query method) belongs in signatures, but both
+// are still commonly published and harmless.
+var knownDKIMTags = map[string]bool{
+	"v": true, "k": true, "p": true, "h": true, "s": true, "t": true, "n": true, "g": true, "q": true,
 }
 
 // ParseDKIM parses a DKIM TXT record and returns the parsed record or an error.
@@ -32,8 +51,16 @@ func ParseDKIM(s string) (*DKIMRecord, error) {
 
 	rec := &DKIMRecord{
 		KeyType: "rsa", // default
+		Tags:    params,
 	}
 
+	for name := range params {
+		if !knownDKIMTags[name] {
+			rec.UnknownTags = append(rec.UnknownTags, name)
+		}
+	}
+	sort.Strings(rec.UnknownTags)
+
 	// Check version if present
 	if v, ok := params["v"]; ok && v != "DKIM1" {
 		return nil, errors.New("incompatible DKIM version: expected DKIM1")
@@ -50,8 +77,9 @@ func ParseDKIM(s string) (*DKIMRecord, error) {
 		rec.IsRevoked = true
 		rec.PublicKey = ""
 	} else {
-		// Remove any whitespace from the key
-		p = strings.ReplaceAll(p, " ", "")
+		// Remove any whitespace from the key, including tabs and line
+		// breaks that RFC 6376 allows as folding whitespace
+		p = strings.Join(strings.Fields(p), "")
 		rec.PublicKey = p
 
 		// Validate that it's valid base64
@@ -59,25 +87,36 @@ func ParseDKIM(s string) (*DKIMRecord, error) {
 		if err != nil {
 			return nil, fmt.Errorf("invalid base64 in public key: %w", err)
 		}
+		sum := sha256.Sum256(b)
+		rec.KeyFingerprint = hex.EncodeToString(sum[:])
 
-		// Parse key type
+		// Parse key type. Tag values are case-insensitive, and some
+		// providers publish k=RSA
 		if k, ok := params["k"]; ok {
-			rec.KeyType = k
+			rec.KeyType = strings.ToLower(k)
 		}
 
 		// Validate the key based on type
+		var pub any
 		switch rec.KeyType {
 		case "rsa", "":
 			rec.KeyType = "rsa"
 			// Try to parse as PKIX first, then PKCS1
-			pub, err := x509.ParsePKIXPublicKey(b)
+			rec.KeyFormat = "pkix"
+			parsed, err := x509.ParsePKIXPublicKey(b)
 			if err != nil {
-				pub, err = x509.ParsePKCS1PublicKey(b)
+				parsed, err = x509.ParsePKCS1PublicKey(b)
 				if err != nil {
+					// Unlike RSA, Ed25519 has to be named in the k tag
+					if _, hasK := params["k"]; !hasK && len(b) == ed25519.PublicKeySize {
+						return nil, fmt.Errorf("public key is %d bytes, the size of an Ed25519 key, but the record has no k tag; "+
+							"verifiers assume k=rsa and fail to verify signatures, so add k=ed25519", len(b))
+					}
 					return nil, fmt.Errorf("invalid RSA public key: %w", err)
 				}
+				rec.KeyFormat = "pkcs1"
 			}
-			rsaPub, ok := pub.(*rsa.PublicKey)
+			rsaPub, ok := parsed.(*rsa.PublicKey)
 			if !ok {
 				return nil, errors.New("public key is not an RSA key")
 			}
@@ -86,23 +125,55 @@ func ParseDKIM(s string) (*DKIMRecord, error) {
 			if keyBits < 1024 {
 				return nil, fmt.Errorf("RSA key too short: %d bits (minimum 1024 required)", keyBits)
 			}
+			rec.KeyBits = keyBits
+			rec.RSAExponent = rsaPub.E
+			pub = rsaPub
 		case "ed25519":
 			if len(b) != ed25519.PublicKeySize {
+				// A common mistake is publishing the SubjectPublicKeyInfo
+				// that tools such as openssl export
+				if parsed, err := x509.ParsePKIXPublicKey(b); err == nil {
+					if _, ok := parsed.(ed25519.PublicKey); ok {
+						return nil, fmt.Errorf("invalid Ed25519 public key: got a %d-byte SubjectPublicKeyInfo, but RFC 8463 requires the bare %d-byte key", len(b), ed25519.PublicKeySize)
+					}
+				}
 				return nil, fmt.Errorf("invalid Ed25519 public key size: got %d bytes, expected %d", len(b), ed25519.PublicKeySize)
 			}
+			rec.KeyFormat = "raw"
+			rec.KeyBits = ed25519.PublicKeySize * 8
+			pub = ed25519.PublicKey(b)
 		default:
 			return nil, fmt.Errorf("unsupported key type: %s (expected rsa or ed25519)", rec.KeyType)
 		}
+
+		// Re-encode as PKIX, since RSA keys may be published in PKCS#1 form
+		// and Ed25519 keys are published as the bare key
+		der, err := x509.MarshalPKIXPublicKey(pub)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode public key: %w", err)
+		}
+		rec.PublicKeyPEM = string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
 	}
 
 	// Parse hash algorithms (h tag)
 	if h, ok := params["h"]; ok {
 		rec.HashAlgorithms = parseTagList(h)
+		for _, alg := range rec.HashAlgorithms {
+			if alg != "sha1" && alg != "sha256" {
+				return nil, fmt.Errorf("unknown hash algorithm %q in h tag (expected sha1 or sha256)", alg)
+			}
+		}
 	}
 
-	// Parse services (s tag)
+	// Parse services (s tag). RFC 6376 only defines "email" and "*"
+	rec.Services = []string{"*"}
 	if s, ok := params["s"]; ok {
 		rec.Services = parseTagList(s)
+		for _, svc := range rec.Services {
+			if svc != "email" && svc != "*" {
+				return nil, fmt.Errorf("unknown service type %q in s tag (expected email or *)", svc)
+			}
+		}
 	}
 
 	// Parse flags (t tag)
@@ -115,11 +186,22 @@ func ParseDKIM(s string) (*DKIMRecord, error) {
 		rec.Notes = n
 	}
 
+	// Parse query method (q tag). dns/txt is the only method RFC 6376 defines
+	rec.QueryMethod = "dns/txt"
+	if q, ok := params["q"]; ok {
+		rec.QueryMethod = q
+	}
+
 	return rec, nil
 }
 
 // parseDKIMParams parses the key=value pairs from a DKIM record.
 func parseDKIMParams(s string) (map[string]string, error) {
+	s, err := joinTXTStrings(s)
+	if err != nil {
+		return nil, err
+	}
+
 	params := make(map[string]string)
 	pairs := strings.Split(s, ";")
 
@@ -159,3 +241,33 @@ func parseTagList(s string) []string {
 	}
 	return result
 }
+
+// joinTXTStrings undoes the zone file quoting of a TXT record that was pasted
+// as one or more quoted strings, such as `"v=DKIM1; k=rsa; " "p=MIGf..."`,
+// concatenating the strings the way resolvers do. Input that does not start
+// with a quote is returned unchanged.
+func joinTXTStrings(s string) (string, error) {
+	rest := strings.TrimSpace(s)
+	if !strings.HasPrefix(rest, `"`) {
+		return s, nil
+	}
+
+	var b strings.Builder
+	for rest != "" {
+		if rest[0] != '"' {
+			return "", fmt.Errorf("unexpected %q between quoted strings", rest[0])
+		}
+		i := 1
+		for ; i < len(rest) && rest[i] != '"'; i++ {
+			if rest[i] == '\\' && i+1 < len(rest) {
+				i++
+			}
+			b.WriteByte(rest[i])
+		}
+		if i == len(rest) {
+			return "", errors.New("unterminated quoted string")
+		}
+		rest = strings.TrimSpace(rest[i+1:])
+	}
+	return b.String(), nil
+}
@@ -1,21 +1,49 @@
 package provider
 
 import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"strings"
 	"testing"
 )
 
 func TestParseDKIM_Valid(t *testing.T) {
 	tests := []struct {
-		name    string
-		record  string
-		wantKey string
-		wantErr bool
+		name     string
+		record   string
+		wantKey  string
+		wantBits int
+		wantErr  bool
 	}{
 		{
-			name:    "valid RSA key",
-			record:  "v=DKIM1; k=rsa; p=MIGfMA0GCSqGSIb3DQEBAQUAA4GNADCBiQKBgQDHUigNmWXWQU1xMaOc4Xq1L1Lo8y8qFzqZ6rQNLzb+j3YwjBwEHC9oNWcXqrAqsBgBfJmC7BDL0x6IdCaNEyL3Q3KvQZPksLLzqN5IaMTWYhE7bX4k8HKkAWrJJVaQaXW7/HmAK8Y8htTPxCmKJHQI8V3dWH/JOoq3BlJZu2e22QIDAQAB",
-			wantKey: "rsa",
-			wantErr: false,
+			name:     "valid RSA key",
+			record:   "v=DKIM1; k=rsa; p=MIGfMA0GCSqGSIb3DQEBAQUAA4GNADCBiQKBgQDHUigNmWXWQU1xMaOc4Xq1L1Lo8y8qFzqZ6rQNLzb+j3YwjBwEHC9oNWcXqrAqsBgBfJmC7BDL0x6IdCaNEyL3Q3KvQZPksLLzqN5IaMTWYhE7bX4k8HKkAWrJJVaQaXW7/HmAK8Y8htTPxCmKJHQI8V3dWH/JOoq3BlJZu2e22QIDAQAB",
+			wantKey:  "rsa",
+			wantBits: 1024,
+			wantErr:  false,
+		},
+		{
+			name:     "valid Ed25519 key",
+			record:   "v=DKIM1; k=ed25519; p=11qYAYKxCrfVS/7TyWQHOg7hcvPapiMlrwIaaPcHURo=",
+			wantKey:  "ed25519",
+			wantBits: 256,
+		},
+		{
+			name:     "uppercase RSA key type",
+			record:   "v=DKIM1; k=RSA; p=MIGfMA0GCSqGSIb3DQEBAQUAA4GNADCBiQKBgQDHUigNmWXWQU1xMaOc4Xq1L1Lo8y8qFzqZ6rQNLzb+j3YwjBwEHC9oNWcXqrAqsBgBfJmC7BDL0x6IdCaNEyL3Q3KvQZPksLLzqN5IaMTWYhE7bX4k8HKkAWrJJVaQaXW7/HmAK8Y8htTPxCmKJHQI8V3dWH/JOoq3BlJZu2e22QIDAQAB",
+			wantKey:  "rsa",
+			wantBits: 1024,
+		},
+		{
+			name:     "mixed case Ed25519 key type",
+			record:   "v=DKIM1; k=Ed25519; p=11qYAYKxCrfVS/7TyWQHOg7hcvPapiMlrwIaaPcHURo=",
+			wantKey:  "ed25519",
+			wantBits: 256,
 		},
 		{
 			name:    "revoked key (empty p)",
@@ -33,6 +61,34 @@ func TestParseDKIM_Valid(t *testing.T) {
 			record:  "v=DKIM1; p=not-valid-base64!!!",
 			wantErr: true,
 		},
+		{
+			name:     "sha256 hash",
+			record:   "v=DKIM1; k=ed25519; h=sha256; p=11qYAYKxCrfVS/7TyWQHOg7hcvPapiMlrwIaaPcHURo=",
+			wantKey:  "ed25519",
+			wantBits: 256,
+		},
+		{
+			name:     "sha1 and sha256 hashes",
+			record:   "v=DKIM1; k=ed25519; h=sha1:sha256; p=11qYAYKxCrfVS/7TyWQHOg7hcvPapiMlrwIaaPcHURo=",
+			wantKey:  "ed25519",
+			wantBits: 256,
+		},
+		{
+			name:     "email service",
+			record:   "v=DKIM1; k=ed25519; s=email; p=11qYAYKxCrfVS/7TyWQHOg7hcvPapiMlrwIaaPcHURo=",
+			wantKey:  "ed25519",
+			wantBits: 256,
+		},
+		{
+			name:    "unknown service",
+			record:  "v=DKIM1; k=ed25519; s=mail; p=11qYAYKxCrfVS/7TyWQHOg7hcvPapiMlrwIaaPcHURo=",
+			wantErr: true,
+		},
+		{
+			name:    "unknown hash",
+			record:  "v=DKIM1; k=ed25519; h=sha265; p=11qYAYKxCrfVS/7TyWQHOg7hcvPapiMlrwIaaPcHURo=",
+			wantErr: true,
+		},
 		{
 			name:    "wrong version",
 			record:  "v=DKIM2; p=MIGfMA0GCSqGSIb3DQEBAQUAA4GNADCBiQKBgQDHUigNmWXWQU1xMaOc4Xq1L1Lo8y8qFzqZ6rQNLzb+j3YwjBwEHC9oNWcXqrAqsBgBfJmC7BDL0x6IdCaNEyL3Q3KvQZPksLLzqN5IaMTWYhE7bX4k8HKkAWrJJVaQaXW7/HmAK8Y8htTPxCmKJHQI8V3dWH/JOoq3BlJZu2e22QIDAQAB",
@@ -50,6 +106,9 @@ func TestParseDKIM_Valid(t *testing.T) {
 			if !tt.wantErr && rec.KeyType != tt.wantKey {
 				t.Errorf("ParseDKIM() KeyType = %v, want %v", rec.KeyType, tt.wantKey)
 			}
+			if !tt.wantErr && rec.KeyBits != tt.wantBits {
+				t.Errorf("ParseDKIM() KeyBits = %v, want %v", rec.KeyBits, tt.wantBits)
+			}
 		})
 	}
 }
@@ -63,3 +122,247 @@ func TestParseDKIM_RevokedKey(t *testing.T) {
 		t.Error("ParseDKIM() IsRevoked = false, want true")
 	}
 }
+
+func TestParseDKIM_KeyFingerprint(t *testing.T) {
+	const want = "21fe31dfa154a261626bf854046fd2271b7bed4b6abe45aa58877ef47f9721b9"
+
+	for _, record := range []string{
+		"v=DKIM1; k=ed25519; p=11qYAYKxCrfVS/7TyWQHOg7hcvPapiMlrwIaaPcHURo=",
+		"v=DKIM1; k=ed25519; p=11qYAYKxCrfVS/7TyWQH Og7hcvPapiMlrwIaaPcHURo=",
+	} {
+		rec, err := ParseDKIM(record)
+		if err != nil {
+			t.Fatalf("ParseDKIM(%q) error = %v", record, err)
+		}
+		if rec.KeyFingerprint != want {
+			t.Errorf("ParseDKIM(%q) KeyFingerprint = %v, want %v", record, rec.KeyFingerprint, want)
+		}
+	}
+
+	rec, err := ParseDKIM("v=DKIM1; p=")
+	if err != nil {
+		t.Fatalf("ParseDKIM() error = %v", err)
+	}
+	if rec.KeyFingerprint != "" {
+		t.Errorf("ParseDKIM() KeyFingerprint = %v for a revoked key, want empty", rec.KeyFingerprint)
+	}
+}
+
+func TestParseDKIM_MultiString(t *testing.T) {
+	const key = "MIGfMA0GCSqGSIb3DQEBAQUAA4GNADCBiQKBgQDHUigNmWXWQU1xMaOc4Xq1L1Lo8y8qFzqZ6rQNLzb+j3YwjBwEHC9oNWcXqrAqsBgBfJmC7BDL0x6IdCaNEyL3Q3KvQZPksLLzqN5IaMTWYhE7bX4k8HKkAWrJJVaQaXW7/HmAK8Y8htTPxCmKJHQI8V3dWH/JOoq3BlJZu2e22QIDAQAB"
+
+	tests := []struct {
+		name    string
+		record  string
+		wantErr bool
+	}{
+		{name: "single quoted string", record: `"v=DKIM1; k=rsa; p=` + key + `"`},
+		{name: "two strings", record: `"v=DKIM1; k=rsa; " "p=` + key + `"`},
+		{name: "three strings", record: `"v=DKIM1; k=rsa; p=` + key[:60] + `" "` + key[60:120] + `"  "` + key[120:] + `"`},
+		{name: "leading whitespace", record: ` "v=DKIM1; " "p=` + key + `"`},
+		{name: "unterminated", record: `"v=DKIM1; " "p=` + key, wantErr: true},
+		{name: "text between strings", record: `"v=DKIM1; " k=rsa; "p=` + key + `"`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec, err := ParseDKIM(tt.record)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseDKIM() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && rec.PublicKey != key {
+				t.Errorf("ParseDKIM() PublicKey = %v, want %v", rec.PublicKey, key)
+			}
+		})
+	}
+}
+
+func TestJoinTXTStrings(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "unquoted", input: "v=DKIM1; p=abc", want: "v=DKIM1; p=abc"},
+		{name: "adjacent strings", input: `"v=DKIM1; ""p=abc"`, want: "v=DKIM1; p=abc"},
+		{name: "escaped quote", input: `"n=say \"hi\"; " "p=abc"`, want: `n=say "hi"; p=abc`},
+		{name: "empty string", input: `"" "p=abc"`, want: "p=abc"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := joinTXTStrings(tt.input)
+			if err != nil {
+				t.Fatalf("joinTXTStrings() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("joinTXTStrings() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDKIM_PublicKeyPEM(t *testing.T) {
+	tests := []struct {
+		name   string
+		record string
+		check  func(any) bool
+	}{
+		{
+			name:   "rsa",
+			record: testDKIMRSA1024,
+			check:  func(k any) bool { _, ok := k.(*rsa.PublicKey); return ok },
+		},
+		{
+			name:   "ed25519",
+			record: testDKIMEd25519,
+			check:  func(k any) bool { _, ok := k.(ed25519.PublicKey); return ok },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec, err := ParseDKIM(tt.record)
+			if err != nil {
+				t.Fatalf("ParseDKIM() error = %v", err)
+			}
+			block, rest := pem.Decode([]byte(rec.PublicKeyPEM))
+			if block == nil || block.Type != "PUBLIC KEY" || len(rest) != 0 {
+				t.Fatalf("PublicKeyPEM = %q, want a single PUBLIC KEY block", rec.PublicKeyPEM)
+			}
+			key, err := x509.ParsePKIXPublicKey(block.Bytes)
+			if err != nil {
+				t.Fatalf("x509.ParsePKIXPublicKey() error = %v", err)
+			}
+			if !tt.check(key) {
+				t.Errorf("PublicKeyPEM holds a %T", key)
+			}
+		})
+	}
+}
+
+func TestParseDKIM_Ed25519(t *testing.T) {
+	_, generated, err := generateDKIMKeyPair("ed25519", 0)
+	if err != nil {
+		t.Fatalf("generateDKIMKeyPair() error = %v", err)
+	}
+
+	// The first record is the example key of RFC 8463 appendix A.2
+	for _, record := range []string{testDKIMEd25519, generated} {
+		t.Run(record, func(t *testing.T) {
+			rec, err := ParseDKIM(record)
+			if err != nil {
+				t.Fatalf("ParseDKIM() error = %v", err)
+			}
+			raw, _ := base64.StdEncoding.DecodeString(rec.PublicKey)
+			sum := sha256.Sum256(raw)
+			if rec.KeyType != "ed25519" || rec.KeyBits != 256 || rec.KeyFormat != "raw" || rec.KeyFingerprint != hex.EncodeToString(sum[:]) {
+				t.Errorf("ParseDKIM() = key_type %q, key_bits %d, key_format %q, fingerprint %q", rec.KeyType, rec.KeyBits, rec.KeyFormat, rec.KeyFingerprint)
+			}
+
+			block, _ := pem.Decode([]byte(rec.PublicKeyPEM))
+			if block == nil {
+				t.Fatalf("PublicKeyPEM = %q, want a PEM block", rec.PublicKeyPEM)
+			}
+			key, err := x509.ParsePKIXPublicKey(block.Bytes)
+			if err != nil {
+				t.Fatalf("x509.ParsePKIXPublicKey() error = %v", err)
+			}
+			if pub, ok := key.(ed25519.PublicKey); !ok || !pub.Equal(ed25519.PublicKey(raw)) {
+				t.Errorf("PublicKeyPEM holds %v, want the published key", key)
+			}
+		})
+	}
+
+	// Ed25519 keys must be named in the k tag, and a key of another size is
+	// not an Ed25519 key
+	for record, want := range map[string]string{
+		"v=DKIM1; p=11qYAYKxCrfVS/7TyWQHOg7hcvPapiMlrwIaaPcHURo=":                      "add k=ed25519",
+		"v=DKIM1; k=rsa; p=11qYAYKxCrfVS/7TyWQHOg7hcvPapiMlrwIaaPcHURo=":               "invalid RSA public key",
+		"v=DKIM1; k=ed25519; p=" + base64.StdEncoding.EncodeToString(make([]byte, 31)): "got 31 bytes, expected 32",
+	} {
+		if _, err := ParseDKIM(record); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("ParseDKIM(%q) error = %v, want %q", record, err, want)
+		}
+	}
+
+	// Publishing the SubjectPublicKeyInfo instead of the bare key is rejected
+	// with a hint
+	raw, _ := base64.StdEncoding.DecodeString("11qYAYKxCrfVS/7TyWQHOg7hcvPapiMlrwIaaPcHURo=")
+	spki, err := x509.MarshalPKIXPublicKey(ed25519.PublicKey(raw))
+	if err != nil {
+		t.Fatalf("x509.MarshalPKIXPublicKey() error = %v", err)
+	}
+	_, err = ParseDKIM("v=DKIM1; k=ed25519; p=" + base64.StdEncoding.EncodeToString(spki))
+	if err == nil || !strings.Contains(err.Error(), "SubjectPublicKeyInfo") {
+		t.Errorf("ParseDKIM() error = %v, want a SubjectPublicKeyInfo hint", err)
+	}
+}
+
+func TestParseDKIM_DefaultServices(t *testing.T) {
+	rec, err := ParseDKIM(testDKIMEd25519)
+	if err != nil {
+		t.Fatalf("ParseDKIM() error = %v", err)
+	}
+	if len(rec.Services) != 1 || rec.Services[0] != "*" {
+		t.Errorf("ParseDKIM() Services = %v, want [*]", rec.Services)
+	}
+}
+
+func TestParseDKIM_KeyWhitespace(t *testing.T) {
+	const key = "11qYAYKxCrfVS/7TyWQHOg7hcvPapiMlrwIaaPcHURo="
+
+	tests := []struct {
+		name string
+		key  string
+	}{
+		{name: "spaces", key: "11qYAYKx CrfVS/7TyWQH Og7hcvPapiMlrwIaaPcHURo="},
+		{name: "tab", key: "11qYAYKxCrfVS/7TyWQH\tOg7hcvPapiMlrwIaaPcHURo="},
+		{name: "newline", key: "11qYAYKxCrfVS/7TyWQH\nOg7hcvPapiMlrwIaaPcHURo="},
+		{name: "crlf and indent", key: "11qYAYKxCrfVS/7TyWQH\r\n\tOg7hcvPapiMlrwIaaPcHURo="},
+		{name: "trailing newline", key: key + "\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec, err := ParseDKIM("v=DKIM1; k=ed25519; p=" + tt.key)
+			if err != nil {
+				t.Fatalf("ParseDKIM() error = %v", err)
+			}
+			if rec.PublicKey != key {
+				t.Errorf("ParseDKIM() PublicKey = %q, want %q", rec.PublicKey, key)
+			}
+		})
+	}
+}
+
+func TestParseDKIM_UnknownTags(t *testing.T) {
+	rec, err := ParseDKIM("v=DKIM1; k=ed25519; q=dns/txt; x=1; qq=dns/txt; p=11qYAYKxCrfVS/7TyWQHOg7hcvPapiMlrwIaaPcHURo=")
+	if err != nil {
+		t.Fatalf("ParseDKIM() error = %v", err)
+	}
+	if got := strings.Join(rec.UnknownTags, ","); got != "qq,x" {
+		t.Errorf("UnknownTags = %q, want %q", got, "qq,x")
+	}
+}
+
+func TestParseDKIM_QueryMethod(t *testing.T) {
+	tests := []struct {
+		record string
+		want   string
+	}{
+		{record: "v=DKIM1; k=ed25519; p=11qYAYKxCrfVS/7TyWQHOg7hcvPapiMlrwIaaPcHURo=", want: "dns/txt"},
+		{record: "v=DKIM1; k=ed25519; q=dns/txt; p=11qYAYKxCrfVS/7TyWQHOg7hcvPapiMlrwIaaPcHURo=", want: "dns/txt"},
+		{record: "v=DKIM1; k=ed25519; q=http/well-known; p=11qYAYKxCrfVS/7TyWQHOg7hcvPapiMlrwIaaPcHURo=", want: "http/well-known"},
+	}
+
+	for _, tt := range tests {
+		rec, err := ParseDKIM(tt.record)
+		if err != nil {
+			t.Fatalf("ParseDKIM(%q) error = %v", tt.record, err)
+		}
+		if rec.QueryMethod != tt.want {
+			t.Errorf("ParseDKIM(%q) QueryMethod = %q, want %q", tt.record, rec.QueryMethod, tt.want)
+		}
+	}
+}
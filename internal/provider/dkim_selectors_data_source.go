@@ -0,0 +1,297 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var (
+	_ datasource.DataSource                   = &DKIMSelectorsDataSource{}
+	_ datasource.DataSourceWithValidateConfig = &DKIMSelectorsDataSource{}
+	_ datasource.DataSourceWithConfigure      = &DKIMSelectorsDataSource{}
+)
+
+// defaultDKIMSelectors are the selectors checked when none are configured:
+// those used by default by common mail services and signing software.
+var defaultDKIMSelectors = []string{
+	"default", "dkim", "google", "k1", "k2", "k3", "mail", "s1", "s2", "selector1", "selector2",
+}
+
+// Values of the status attribute of a DKIM selector result.
+const (
+	dkimSelectorValid   = "valid"
+	dkimSelectorInvalid = "invalid"
+	dkimSelectorMissing = "missing"
+	dkimSelectorError   = "error"
+)
+
+func NewDKIMSelectorsDataSource() datasource.DataSource {
+	return &DKIMSelectorsDataSource{}
+}
+
+// DKIMSelectorsDataSource defines the data source implementation.
+type DKIMSelectorsDataSource struct {
+	providerData *providerData
+}
+
+// DKIMSelectorsDataSourceModel describes the data source data model.
+type DKIMSelectorsDataSourceModel struct {
+	Domain         types.String `tfsdk:"domain"`
+	Selectors      types.List   `tfsdk:"selectors"`
+	ChangeTicket   types.String `tfsdk:"change_ticket"`
+	Results        types.List   `tfsdk:"results"`
+	FoundSelectors types.List   `tfsdk:"found_selectors"`
+}
+
+// dkimSelectorResultObjectType defines the Terraform object type for the
+// result of checking one selector.
+var dkimSelectorResultObjectType = types.ObjectType{
+	AttrTypes: map[string]attr.Type{
+		"selector": types.StringType,
+		"name":     types.StringType,
+		"status":   types.StringType,
+		"record":   types.StringType,
+		"error":    types.StringType,
+	},
+}
+
+// dkimSelectorResult is the outcome of looking up one selector. Record is
+// empty when nothing was found, and Err is set for invalid records and
+// failed lookups.
+type dkimSelectorResult struct {
+	Selector string
+	Name     string
+	Status   string
+	Record   string
+	Err      error
+}
+
+func (d *DKIMSelectorsDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_dkim_selectors"
+}
+
+func (d *DKIMSelectorsDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Discovers which DKIM selectors a domain publishes in live DNS, by looking up `<selector>._domainkey.<domain>` " +
+			"for each candidate selector and parsing the key records found. Useful for auditing which DKIM keys are actually published.",
+
+		Attributes: map[string]schema.Attribute{
+			"domain": schema.StringAttribute{
+				MarkdownDescription: "The domain to check (e.g., `example.com`)",
+				Required:            true,
+			},
+			"selectors": schema.ListAttribute{
+				MarkdownDescription: "Candidate selector names to look up (e.g., `[\"google\", \"s1\"]`). Defaults to the selectors used by common mail services: `" +
+					strings.Join(defaultDKIMSelectors, "`, `") + "`",
+				Optional:    true,
+				Computed:    true,
+				ElementType: types.StringType,
+			},
+			"change_ticket": changeTicketAttribute(),
+			"results": schema.ListNestedAttribute{
+				MarkdownDescription: "The outcome of each lookup, in the order of `selectors`",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"selector": schema.StringAttribute{
+							MarkdownDescription: "The selector",
+							Computed:            true,
+						},
+						"name": schema.StringAttribute{
+							MarkdownDescription: "The name looked up (e.g., `google._domainkey.example.com`)",
+							Computed:            true,
+						},
+						"status": schema.StringAttribute{
+							MarkdownDescription: "`valid` if a DKIM key record was found and parses, `invalid` if the TXT records found are not a valid DKIM key record, " +
+								"`missing` if the name has no TXT records, or `error` if the lookup failed",
+							Computed: true,
+						},
+						"record": schema.StringAttribute{
+							MarkdownDescription: "The TXT record found. Null when the status is `missing` or `error`",
+							Computed:            true,
+						},
+						"error": schema.StringAttribute{
+							MarkdownDescription: "Why the record is invalid or the lookup failed. Null otherwise",
+							Computed:            true,
+						},
+					},
+				},
+			},
+			"found_selectors": schema.ListAttribute{
+				MarkdownDescription: "The selectors whose status is `valid`. Empty when none are",
+				Computed:            true,
+				ElementType:         types.StringType,
+			},
+		},
+	}
+}
+
+func (d *DKIMSelectorsDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	d.providerData = configureProviderData(req, resp)
+}
+
+func (d *DKIMSelectorsDataSource) ValidateConfig(ctx context.Context, req datasource.ValidateConfigRequest, resp *datasource.ValidateConfigResponse) {
+	defer d.providerData.applyWarningSettings(&resp.Diagnostics)
+
+	var data DKIMSelectorsDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Values that are unknown (e.g., depend on another resource) are skipped
+	if !data.Domain.IsUnknown() && !data.Domain.IsNull() {
+		if err := checkHostname(data.Domain.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("domain"),
+				summaryDomainInvalid,
+				fmt.Sprintf("The domain is invalid: %s", err.Error()),
+			)
+		}
+	}
+
+	if data.Selectors.IsUnknown() || data.Selectors.IsNull() {
+		return
+	}
+	if len(data.Selectors.Elements()) == 0 {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("selectors"),
+			summaryDKIMInvalidSelector,
+			"selectors must not be empty. Remove it to check the default selectors.",
+		)
+	}
+	for i, element := range data.Selectors.Elements() {
+		selector, ok := element.(types.String)
+		if !ok || selector.IsUnknown() {
+			continue
+		}
+		if err := checkDKIMSelector(selector.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("selectors").AtListIndex(i),
+				summaryDKIMInvalidSelector,
+				fmt.Sprintf("The selector is invalid: %s", err.Error()),
+			)
+		}
+	}
+}
+
+func (d *DKIMSelectorsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	defer d.providerData.applyWarningSettings(&resp.Diagnostics)
+
+	var data DKIMSelectorsDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data.ChangeTicket = d.providerData.checkChangeTicket(data.ChangeTicket, &resp.Diagnostics)
+
+	selectors := defaultDKIMSelectors
+	if data.Selectors.IsNull() {
+		data.Selectors = convertStringSliceToList(ctx, selectors, &resp.Diagnostics)
+	} else {
+		selectors = nil
+		resp.Diagnostics.Append(data.Selectors.ElementsAs(ctx, &selectors, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	domain := data.Domain.ValueString()
+	resolver := d.providerData.dnsResolver()
+
+	// A failed lookup only affects its own selector, so the results of the
+	// others are still returned
+	values := make([]attr.Value, len(selectors))
+	found := []string{}
+	for i, selector := range selectors {
+		result := lookupDKIMSelector(ctx, resolver, domain, selector)
+		switch result.Status {
+		case dkimSelectorValid:
+			found = append(found, selector)
+		case dkimSelectorError:
+			resp.Diagnostics.AddWarning(lookupFailure(
+				summaryDKIMSelectorLookupFailed,
+				fmt.Sprintf("Unable to look up the DKIM selector %s at %s: %s", selector, result.Name, result.Err.Error()),
+				result.Err,
+			))
+		}
+
+		record, errText := types.StringNull(), types.StringNull()
+		if result.Record != "" {
+			record = types.StringValue(result.Record)
+		}
+		if result.Err != nil {
+			errText = types.StringValue(result.Err.Error())
+		}
+		values[i] = types.ObjectValueMust(dkimSelectorResultObjectType.AttrTypes, map[string]attr.Value{
+			"selector": types.StringValue(selector),
+			"name":     types.StringValue(result.Name),
+			"status":   types.StringValue(result.Status),
+			"record":   record,
+			"error":    errText,
+		})
+	}
+
+	list, listDiags := types.ListValue(dkimSelectorResultObjectType, values)
+	resp.Diagnostics.Append(listDiags...)
+	data.Results = list
+	foundList, listDiags := types.ListValueFrom(ctx, types.StringType, found)
+	resp.Diagnostics.Append(listDiags...)
+	data.FoundSelectors = foundList
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// checkDKIMSelector reports whether selector is a valid DKIM selector: one or
+// more dot-separated host name labels (RFC 6376 section 3.1).
+func checkDKIMSelector(selector string) error {
+	if selector == "" {
+		return fmt.Errorf("selector is empty")
+	}
+	for _, label := range strings.Split(selector, ".") {
+		if err := checkHostnameLabel(label); err != nil {
+			return fmt.Errorf("%q is not a valid selector: %w", selector, err)
+		}
+	}
+	return nil
+}
+
+// lookupDKIMSelector looks up the DKIM key record of selector under domain.
+// When the name has several TXT records, the first that parses as a DKIM key
+// record is used.
+func lookupDKIMSelector(ctx context.Context, resolver dnsResolver, domain, selector string) dkimSelectorResult {
+	name := strings.ToLower(selector) + "._domainkey." + strings.ToLower(strings.TrimSuffix(domain, "."))
+	result := dkimSelectorResult{Selector: selector, Name: name}
+
+	txts, err := resolver.LookupTXT(ctx, name)
+	if err != nil && !isNotFound(err) {
+		result.Status, result.Err = dkimSelectorError, err
+		return result
+	}
+	if len(txts) == 0 {
+		result.Status = dkimSelectorMissing
+		return result
+	}
+
+	for _, txt := range txts {
+		if _, err := ParseDKIM(txt); err == nil {
+			result.Status, result.Record, result.Err = dkimSelectorValid, txt, nil
+			return result
+		} else if result.Err == nil {
+			result.Record, result.Err = txt, err
+		}
+	}
+	result.Status = dkimSelectorInvalid
+	return result
+}
@@ -0,0 +1,76 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+)
+
+// failingTXTResolver fails TXT lookups of one name and answers the rest from
+// the wrapped resolver.
+type failingTXTResolver struct {
+	*fakeResolver
+	name string
+}
+
+func (r *failingTXTResolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	if name == r.name {
+		return nil, &net.DNSError{Err: "i/o timeout", Name: name, IsTimeout: true}
+	}
+	return r.fakeResolver.LookupTXT(ctx, name)
+}
+
+func TestLookupDKIMSelector(t *testing.T) {
+	resolver := &failingTXTResolver{
+		fakeResolver: &fakeResolver{txt: map[string][]string{
+			"google._domainkey.example.com": {testDKIMEd25519},
+			"s1._domainkey.example.com":     {"v=spf1 -all", testDKIMEd25519},
+			"s2._domainkey.example.com":     {"v=DKIM1; k=dsa; p=abc"},
+		}},
+		name: "k1._domainkey.example.com",
+	}
+
+	tests := []struct {
+		selector   string
+		wantName   string
+		wantStatus string
+		wantRecord string
+		wantErr    bool
+	}{
+		{selector: "google", wantName: "google._domainkey.example.com", wantStatus: "valid", wantRecord: testDKIMEd25519},
+		{selector: "S1", wantName: "s1._domainkey.example.com", wantStatus: "valid", wantRecord: testDKIMEd25519},
+		{selector: "s2", wantName: "s2._domainkey.example.com", wantStatus: "invalid", wantRecord: "v=DKIM1; k=dsa; p=abc", wantErr: true},
+		{selector: "default", wantName: "default._domainkey.example.com", wantStatus: "missing"},
+		{selector: "k1", wantName: "k1._domainkey.example.com", wantStatus: "error", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			got := lookupDKIMSelector(context.Background(), resolver, "Example.com.", tt.selector)
+			if got.Name != tt.wantName || got.Status != tt.wantStatus || got.Record != tt.wantRecord {
+				t.Errorf("lookupDKIMSelector() = %s %s %q, want %s %s %q", got.Name, got.Status, got.Record, tt.wantName, tt.wantStatus, tt.wantRecord)
+			}
+			if (got.Err != nil) != tt.wantErr {
+				t.Errorf("lookupDKIMSelector() error = %v, wantErr %v", got.Err, tt.wantErr)
+			}
+			var dnsErr *net.DNSError
+			if tt.wantStatus == "error" && !errors.As(got.Err, &dnsErr) {
+				t.Errorf("lookupDKIMSelector() error = %v, want the lookup error", got.Err)
+			}
+		})
+	}
+}
+
+func TestCheckDKIMSelector(t *testing.T) {
+	for _, selector := range []string{"google", "s1", "2024-01.mail", "selector1"} {
+		if err := checkDKIMSelector(selector); err != nil {
+			t.Errorf("checkDKIMSelector(%q) error = %v", selector, err)
+		}
+	}
+	for _, selector := range []string{"", "-bad", "a..b", "has space", "s1."} {
+		if err := checkDKIMSelector(selector); err == nil {
+			t.Errorf("checkDKIMSelector(%q) = nil, want error", selector)
+		}
+	}
+}
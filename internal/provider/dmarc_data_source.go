@@ -3,18 +3,23 @@ package provider
 import (
 	"context"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/emersion/go-msgauth/dmarc"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
 // Ensure provider defined types fully satisfy framework interfaces.
 var (
-	_ datasource.DataSource              = &DMARCDataSource{}
+	_ datasource.DataSource                   = &DMARCDataSource{}
 	_ datasource.DataSourceWithValidateConfig = &DMARCDataSource{}
+	_ datasource.DataSourceWithConfigure      = &DMARCDataSource{}
 )
 
 func NewDMARCDataSource() datasource.DataSource {
@@ -22,18 +27,69 @@ func NewDMARCDataSource() datasource.DataSource {
 }
 
 // DMARCDataSource defines the data source implementation.
-type DMARCDataSource struct{}
+type DMARCDataSource struct {
+	providerData *providerData
+}
 
 // DMARCDataSourceModel describes the data source data model.
 type DMARCDataSourceModel struct {
 	Record             types.String `tfsdk:"record"`
+	RecordFile         types.String `tfsdk:"record_file"`
+	ParsedJSON         types.String `tfsdk:"parsed_json"`
+	Domain             types.String `tfsdk:"domain"`
+	Lookup             types.Bool   `tfsdk:"lookup"`
+	ChangeTicket       types.String `tfsdk:"change_ticket"`
+	Valid              types.Bool   `tfsdk:"valid"`
+	Warnings           types.List   `tfsdk:"warnings"`
 	Policy             types.String `tfsdk:"policy"`
 	SubdomainPolicy    types.String `tfsdk:"subdomain_policy"`
+	EffectiveSubdomain types.String `tfsdk:"effective_subdomain_policy"`
+	NonexistentPolicy  types.String `tfsdk:"nonexistent_subdomain_policy"`
+	SubdomainStrength  types.String `tfsdk:"subdomain_policy_strength"`
 	DKIMAlignment      types.String `tfsdk:"dkim_alignment"`
+	DKIMAlignmentSet   types.Bool   `tfsdk:"dkim_alignment_explicit"`
 	SPFAlignment       types.String `tfsdk:"spf_alignment"`
+	SPFAlignmentSet    types.Bool   `tfsdk:"spf_alignment_explicit"`
 	Percent            types.Int64  `tfsdk:"percent"`
+	IsEnforcing        types.Bool   `tfsdk:"is_enforcing"`
 	ReportURIAggregate types.List   `tfsdk:"report_uri_aggregate"`
 	ReportURIFailure   types.List   `tfsdk:"report_uri_failure"`
+	RUA                types.List   `tfsdk:"rua"`
+	RUF                types.List   `tfsdk:"ruf"`
+	FailureOptions     types.List   `tfsdk:"failure_options"`
+	ReportFormat       types.String `tfsdk:"report_format"`
+	ReportInterval     types.Int64  `tfsdk:"report_interval"`
+	RawTags            types.Map    `tfsdk:"raw_tags"`
+}
+
+// reportURIObjectType defines the Terraform object type for parsed report
+// destinations.
+var reportURIObjectType = types.ObjectType{
+	AttrTypes: map[string]attr.Type{
+		"scheme":   types.StringType,
+		"address":  types.StringType,
+		"max_size": types.Int64Type,
+	},
+}
+
+// reportURINestedObject returns the schema of a parsed report destination.
+func reportURINestedObject() schema.NestedAttributeObject {
+	return schema.NestedAttributeObject{
+		Attributes: map[string]schema.Attribute{
+			"scheme": schema.StringAttribute{
+				MarkdownDescription: "The URI scheme (mailto or https)",
+				Computed:            true,
+			},
+			"address": schema.StringAttribute{
+				MarkdownDescription: "The destination email address (e.g., `dmarc@example.com`), or the full URL for https destinations",
+				Computed:            true,
+			},
+			"max_size": schema.Int64Attribute{
+				MarkdownDescription: "The maximum report size in bytes from the `!` suffix, or null when unlimited",
+				Computed:            true,
+			},
+		},
+	}
 }
 
 func (d *DMARCDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
@@ -47,8 +103,29 @@ func (d *DMARCDataSource) Schema(ctx context.Context, req datasource.SchemaReque
 
 		Attributes: map[string]schema.Attribute{
 			"record": schema.StringAttribute{
-				MarkdownDescription: "The DMARC TXT record content to validate (e.g., `v=DMARC1; p=reject; rua=mailto:dmarc@example.com`)",
-				Required:            true,
+				MarkdownDescription: "The DMARC TXT record content to validate (e.g., `v=DMARC1; p=reject; rua=mailto:dmarc@example.com`). " +
+					"When `record_file` or `lookup` is set instead, this is the content of the file or the published record",
+				Optional: true,
+				Computed: true,
+			},
+			"record_file": recordFileAttribute(),
+			"domain": schema.StringAttribute{
+				MarkdownDescription: "The domain the record is published for (e.g., `example.com`). Used to detect report destinations on third-party domains, " +
+					"and to find the record when `lookup` is `true`",
+				Optional: true,
+			},
+			"lookup": schema.BoolAttribute{
+				MarkdownDescription: "Set to `true` to validate the record currently published at `_dmarc.<domain>` in live DNS instead of `record` or `record_file`. " +
+					"The record found is returned in `record`. Defaults to `false`.",
+				Optional: true,
+			},
+			"change_ticket": changeTicketAttribute(),
+			"valid":         validAttribute(),
+			"warnings":      warningsAttribute(),
+			"parsed_json": schema.StringAttribute{
+				MarkdownDescription: "The parsed record as a JSON object mapping each tag name to its value as written, for use with `jsondecode`. " +
+					"Keys are sorted so the value only changes with the record. Matches `parsed_json` of `emaildns_txt`",
+				Computed: true,
 			},
 			"policy": schema.StringAttribute{
 				MarkdownDescription: "The parsed policy value (none, quarantine, or reject)",
@@ -58,18 +135,44 @@ func (d *DMARCDataSource) Schema(ctx context.Context, req datasource.SchemaReque
 				MarkdownDescription: "The parsed subdomain policy value (sp tag)",
 				Computed:            true,
 			},
+			"effective_subdomain_policy": schema.StringAttribute{
+				MarkdownDescription: "The policy applied to subdomains: the sp tag when present, otherwise the inherited p tag",
+				Computed:            true,
+			},
+			"nonexistent_subdomain_policy": schema.StringAttribute{
+				MarkdownDescription: "The policy for subdomains that do not exist in DNS (np tag, RFC 9091). Null when the tag is absent; " +
+					"`np=reject` is recommended for domains that do not send from subdomains",
+				Computed: true,
+			},
+			"subdomain_policy_strength": schema.StringAttribute{
+				MarkdownDescription: "How the subdomain policy compares to the domain policy: `weaker`, `same`, or `stronger`. " +
+					"`same` when the sp tag is absent, since subdomains then inherit the p tag",
+				Computed: true,
+			},
 			"dkim_alignment": schema.StringAttribute{
 				MarkdownDescription: "The DKIM alignment mode (r for relaxed, s for strict)",
 				Computed:            true,
 			},
+			"dkim_alignment_explicit": schema.BoolAttribute{
+				MarkdownDescription: "True if the adkim tag is present, false if `dkim_alignment` is the relaxed default",
+				Computed:            true,
+			},
 			"spf_alignment": schema.StringAttribute{
 				MarkdownDescription: "The SPF alignment mode (r for relaxed, s for strict)",
 				Computed:            true,
 			},
+			"spf_alignment_explicit": schema.BoolAttribute{
+				MarkdownDescription: "True if the aspf tag is present, false if `spf_alignment` is the relaxed default",
+				Computed:            true,
+			},
 			"percent": schema.Int64Attribute{
 				MarkdownDescription: "The percentage of messages to which the policy applies (0-100)",
 				Computed:            true,
 			},
+			"is_enforcing": schema.BoolAttribute{
+				MarkdownDescription: "True if the policy is `quarantine` or `reject` and applies to all failing mail (`pct` absent or 100)",
+				Computed:            true,
+			},
 			"report_uri_aggregate": schema.ListAttribute{
 				MarkdownDescription: "List of URIs for aggregate reports (rua tag)",
 				Computed:            true,
@@ -80,11 +183,46 @@ func (d *DMARCDataSource) Schema(ctx context.Context, req datasource.SchemaReque
 				Computed:            true,
 				ElementType:         types.StringType,
 			},
+			"rua": schema.ListNestedAttribute{
+				MarkdownDescription: "Parsed aggregate report destinations (rua tag)",
+				Computed:            true,
+				NestedObject:        reportURINestedObject(),
+			},
+			"ruf": schema.ListNestedAttribute{
+				MarkdownDescription: "Parsed failure report destinations (ruf tag)",
+				Computed:            true,
+				NestedObject:        reportURINestedObject(),
+			},
+			"report_format": schema.StringAttribute{
+				MarkdownDescription: "Failure report format (rf tag). Defaults to `afrf` when the tag is absent",
+				Computed:            true,
+			},
+			"report_interval": schema.Int64Attribute{
+				MarkdownDescription: "Requested aggregate report interval in seconds (ri tag). Defaults to `86400` (one day) when the tag is absent",
+				Computed:            true,
+			},
+			"failure_options": schema.ListAttribute{
+				MarkdownDescription: "Failure reporting options (fo tag): `0`, `1`, `d`, or `s`. Defaults to `[\"0\"]` when the tag is absent",
+				Computed:            true,
+				ElementType:         types.StringType,
+			},
+			"raw_tags": schema.MapAttribute{
+				MarkdownDescription: "Every `tag=value` pair in the record keyed by tag name, including tags this provider does not model. " +
+					"Use it to write custom checks against tags without first-class attributes",
+				Computed:    true,
+				ElementType: types.StringType,
+			},
 		},
 	}
 }
 
+func (d *DMARCDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	d.providerData = configureProviderData(req, resp)
+}
+
 func (d *DMARCDataSource) ValidateConfig(ctx context.Context, req datasource.ValidateConfigRequest, resp *datasource.ValidateConfigResponse) {
+	defer d.providerData.applyWarningSettings(&resp.Diagnostics)
+
 	var data DMARCDataSourceModel
 
 	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
@@ -92,23 +230,31 @@ func (d *DMARCDataSource) ValidateConfig(ctx context.Context, req datasource.Val
 		return
 	}
 
-	// Skip validation if record is unknown (e.g., depends on another resource)
-	if data.Record.IsUnknown() {
+	// The published record is only known once it is looked up in Read
+	if data.Lookup.IsUnknown() {
+		return
+	}
+	if data.Lookup.ValueBool() {
+		checkLookupInput(data.Record, data.RecordFile, data.Domain, &resp.Diagnostics)
 		return
 	}
 
-	// Validate the DMARC record
-	record := data.Record.ValueString()
-	_, err := dmarc.Parse(record)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Invalid DMARC Record",
-			fmt.Sprintf("The DMARC record is malformed: %s\n\nRecord: %s", err.Error(), record),
-		)
+	record := recordInput(data.Record, data.RecordFile, &resp.Diagnostics)
+
+	// Skip validation if record is unknown (e.g., depends on another resource)
+	// or could not be determined
+	if record.IsUnknown() || record.IsNull() {
+		return
 	}
+
+	var checkDiags diag.Diagnostics
+	validateDMARCRecord(record.ValueString(), &checkDiags)
+	d.providerData.routeDiagnostics(checkDiags, &resp.Diagnostics)
 }
 
 func (d *DMARCDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	defer d.providerData.applyWarningSettings(&resp.Diagnostics)
+
 	var data DMARCDataSourceModel
 
 	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
@@ -116,15 +262,56 @@ func (d *DMARCDataSource) Read(ctx context.Context, req datasource.ReadRequest,
 		return
 	}
 
+	data.ChangeTicket = d.providerData.checkChangeTicket(data.ChangeTicket, &resp.Diagnostics)
+
+	validate := func(diags *diag.Diagnostics) { validateDMARCRecord(data.Record.ValueString(), diags) }
+	read := func(diags *diag.Diagnostics) { d.read(ctx, &data, diags) }
+	if data.Lookup.ValueBool() {
+		// ValidateConfig has not seen the published record, so it is
+		// validated along with the read
+		validate = func(*diag.Diagnostics) {}
+		read = func(diags *diag.Diagnostics) {
+			record, err := lookupDMARCRecord(ctx, d.providerData.dnsResolver(), data.Domain.ValueString())
+			if err != nil {
+				diags.AddError(lookupFailure(summaryDMARCLookupFailed, err.Error(), err))
+				return
+			}
+			data.Record = types.StringValue(record)
+			validateDMARCRecord(record, diags)
+			if !diags.HasError() {
+				d.read(ctx, &data, diags)
+			}
+		}
+	} else {
+		data.Record = recordInput(data.Record, data.RecordFile, &resp.Diagnostics)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	data.Valid, data.Warnings = d.providerData.checkRecord(validate, read, &resp.Diagnostics)
+	if !data.Valid.ValueBool() {
+		resp.Diagnostics.Append(setInvalidRecordState(ctx, req.Config, &resp.State, data.ChangeTicket, data.Valid, data.Warnings)...)
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("record"), data.Record)...)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// read parses the record in data and fills in the computed attributes.
+func (d *DMARCDataSource) read(ctx context.Context, data *DMARCDataSourceModel, diags *diag.Diagnostics) {
 	record := data.Record.ValueString()
-	parsed, err := dmarc.Parse(record)
+	parsed, err := parseDMARCRecord(record)
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Invalid DMARC Record",
+		diags.AddError(
+			summaryDMARCInvalidRecord,
 			fmt.Sprintf("The DMARC record is malformed: %s", err.Error()),
 		)
 		return
 	}
+	tags := splitDMARCTags(record)
+	data.ParsedJSON = parsedRecordJSON("dmarc", record, diags)
 
 	// Set computed attributes
 	data.Policy = types.StringValue(string(parsed.Policy))
@@ -135,20 +322,382 @@ func (d *DMARCDataSource) Read(ctx context.Context, req datasource.ReadRequest,
 		data.SubdomainPolicy = types.StringNull()
 	}
 
+	data.EffectiveSubdomain = types.StringValue(string(effectiveSubdomainPolicy(parsed)))
+	data.SubdomainStrength = types.StringValue(compareSubdomainPolicy(parsed))
+
+	data.NonexistentPolicy = types.StringNull()
+	if np, ok := dmarcTagValue(tags, "np"); ok {
+		policy, err := parseDMARCPolicy("np", np)
+		if err != nil {
+			diags.AddError(
+				summaryDMARCInvalidRecord,
+				fmt.Sprintf("The DMARC record is malformed: %s", err.Error()),
+			)
+			return
+		}
+		data.NonexistentPolicy = types.StringValue(string(policy))
+	}
+
+	if data.SubdomainStrength.ValueString() == "weaker" {
+		diags.AddWarning(
+			summaryDMARCSubdomainPolicyWeak,
+			fmt.Sprintf("The DMARC record applies p=%s to the domain but only sp=%s to subdomains. "+
+				"Attackers can spoof any subdomain, including ones that do not exist, to get around the stricter domain policy. "+
+				"Consider sp=%s unless subdomains legitimately send unauthenticated mail.", parsed.Policy, parsed.SubdomainPolicy, parsed.Policy),
+		)
+	}
+
+	if d.providerData != nil && d.providerData.warnOnMonitoring {
+		// sp=none under an enforcing p is already covered by the weak
+		// subdomain policy warning
+		if parsed.Policy == dmarc.PolicyNone {
+			diags.AddWarning(
+				summaryDMARCPolicyNone,
+				"The DMARC record uses p=none, which only monitors mail and does not protect the domain from spoofing. "+
+					"Once aggregate reports show legitimate mail passing, move to p=quarantine and then p=reject.",
+			)
+		}
+	}
+
+	_, adkimSet := dmarcTagValue(tags, "adkim")
+	_, aspfSet := dmarcTagValue(tags, "aspf")
 	data.DKIMAlignment = types.StringValue(string(parsed.DKIMAlignment))
+	data.DKIMAlignmentSet = types.BoolValue(adkimSet)
 	data.SPFAlignment = types.StringValue(string(parsed.SPFAlignment))
+	data.SPFAlignmentSet = types.BoolValue(aspfSet)
+
+	data.IsEnforcing = types.BoolValue(isEnforcingDMARCPolicy(parsed))
 
 	if parsed.Percent != nil {
 		data.Percent = types.Int64Value(int64(*parsed.Percent))
+
+		switch pct := *parsed.Percent; {
+		case pct == 0 && parsed.Policy != dmarc.PolicyNone:
+			diags.AddWarning(
+				summaryDMARCPCTZero,
+				fmt.Sprintf("The DMARC record sets p=%s but pct=0, so the policy is applied to no messages at all. "+
+					"The record looks enforcing but provides no protection. Raise pct or remove the tag to apply the policy to all mail.", parsed.Policy),
+			)
+		case pct < 100:
+			diags.AddWarning(
+				summaryDMARCPCTPartial,
+				fmt.Sprintf("The DMARC record sets pct=%d, so the policy only applies to %d%% of failing messages. "+
+					"This is useful during a staged rollout, but should be raised to 100 once the rollout is complete.", pct, pct),
+			)
+		}
 	} else {
 		data.Percent = types.Int64Null()
 	}
 
 	// Convert string slices to Terraform lists
-	data.ReportURIAggregate = convertStringSliceToList(ctx, parsed.ReportURIAggregate, &resp.Diagnostics)
-	data.ReportURIFailure = convertStringSliceToList(ctx, parsed.ReportURIFailure, &resp.Diagnostics)
+	data.ReportURIAggregate = convertStringSliceToList(ctx, parsed.ReportURIAggregate, diags)
+	data.ReportURIFailure = convertStringSliceToList(ctx, parsed.ReportURIFailure, diags)
 
-	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+	if len(parsed.ReportURIAggregate) == 0 {
+		diags.AddWarning(
+			summaryDMARCNoRUA,
+			fmt.Sprintf("The DMARC record applies p=%s but has no rua tag, so no aggregate reports will be sent. "+
+				"Without them there is no visibility into which mail passes or fails DMARC. "+
+				"Add at least one destination, e.g. rua=mailto:dmarc-reports@example.com.", parsed.Policy),
+		)
+	}
+
+	data.RUA = types.ListNull(reportURIObjectType)
+	if rua, ok := dmarcTagValue(tags, "rua"); ok {
+		uris, err := parseReportURIs("rua", rua)
+		if err != nil {
+			diags.AddError(
+				summaryDMARCInvalidRecord,
+				fmt.Sprintf("The DMARC record is malformed: %s", err.Error()),
+			)
+			return
+		}
+		data.RUA = reportURIList(uris, diags)
+	}
+
+	data.RUF = types.ListNull(reportURIObjectType)
+	if ruf, ok := dmarcTagValue(tags, "ruf"); ok {
+		uris, err := parseReportURIs("ruf", ruf)
+		if err != nil {
+			diags.AddError(
+				summaryDMARCInvalidRecord,
+				fmt.Sprintf("The DMARC record is malformed: %s", err.Error()),
+			)
+			return
+		}
+		data.RUF = reportURIList(uris, diags)
+
+		if domain := data.Domain.ValueString(); domain != "" {
+			for _, uri := range uris {
+				if isExternalReportURI(domain, uri) {
+					diags.AddWarning(
+						summaryDMARCRUFThirdParty,
+						fmt.Sprintf("The ruf destination %s is outside %s. Failure reports can contain personal data from message headers, "+
+							"and receivers only deliver them if the destination domain publishes a TXT authorization record at %s.",
+							uri.Address, domain, dmarcAuthorizationName(domain, reportURIHost(uri))),
+					)
+				}
+			}
+		}
+	}
+
+	data.ReportFormat = types.StringValue(defaultReportFormat)
+	if rf, ok := dmarcTagValue(tags, "rf"); ok {
+		data.ReportFormat = types.StringValue(rf)
+	}
+
+	data.ReportInterval = types.Int64Value(defaultReportInterval)
+	if parsed.ReportInterval > 0 {
+		data.ReportInterval = types.Int64Value(int64(parsed.ReportInterval / time.Second))
+	}
+
+	failureOptions := []string{"0"}
+	if fo, ok := dmarcTagValue(tags, "fo"); ok {
+		// Already validated by dmarc.Parse
+		failureOptions, _ = parseFailureOptions(fo)
+	}
+	data.FailureOptions = convertStringSliceToList(ctx, failureOptions, diags)
+
+	data.RawTags = convertStringMapToMap(ctx, dmarcRawTags(tags), diags)
+}
+
+// dmarcNearTXTStringLength is the length above which a DMARC record is
+// reported as approaching the TXT string limit: less than the length of one
+// more typical mailto destination is left.
+const dmarcNearTXTStringLength = 225
+
+// validateDMARCRecord reports problems with a DMARC record that dmarc.Parse
+// either misses or only reports generically.
+func validateDMARCRecord(record string, diags *diag.Diagnostics) {
+	tags := splitDMARCTags(record)
+
+	if err := checkDMARCTagOrder(record); err != nil {
+		diags.AddError(
+			summaryDMARCInvalidRecord,
+			fmt.Sprintf("The DMARC record is malformed: %s\n\nRecord: %s", err.Error(), record),
+		)
+		return
+	}
+
+	switch {
+	case len(record) > maxTXTStringLength:
+		diags.AddWarning(
+			summaryDMARCTXTStringLength,
+			fmt.Sprintf("The DMARC record is %d bytes, longer than the %d-byte limit for a single TXT string. "+
+				"It must be published as multiple strings, which some DNS providers do not handle automatically. "+
+				"Consider fewer report destinations.", len(record), maxTXTStringLength),
+		)
+	case len(record) > dmarcNearTXTStringLength:
+		diags.AddWarning(
+			summaryDMARCTXTStringNearLimit,
+			fmt.Sprintf("The DMARC record is %d bytes, close to the %d-byte limit for a single TXT string. "+
+				"Adding another report destination will likely push it over, after which it must be published as multiple strings.",
+				len(record), maxTXTStringLength),
+		)
+	}
+
+	if duplicates := duplicateDMARCTags(tags); len(duplicates) > 0 {
+		diags.AddError(
+			summaryDMARCDuplicateTags,
+			fmt.Sprintf("The DMARC record repeats the %s tag. Receivers disagree on which value to use, so each tag must appear only once.\n\nRecord: %s",
+				strings.Join(duplicates, ", "), record),
+		)
+		return
+	}
+
+	if np, ok := dmarcTagValue(tags, "np"); ok {
+		if _, err := parseDMARCPolicy("np", np); err != nil {
+			diags.AddError(
+				summaryDMARCInvalidRecord,
+				fmt.Sprintf("The DMARC record is malformed: %s\n\nRecord: %s", err.Error(), record),
+			)
+			return
+		}
+	}
+
+	if unknown := unknownDMARCTags(tags); len(unknown) > 0 {
+		diags.AddWarning(
+			summaryDMARCUnknownTags,
+			fmt.Sprintf("The DMARC record contains unknown tags: %s. Receivers ignore unknown tags, so this is usually a typo "+
+				"(e.g. pl=reject instead of p=reject).", strings.Join(unknown, ", ")),
+		)
+	}
+
+	if fo, ok := dmarcTagValue(tags, "fo"); ok {
+		if _, err := parseFailureOptions(fo); err != nil {
+			diags.AddError(
+				summaryDMARCInvalidRecord,
+				fmt.Sprintf("The DMARC record is malformed: %s\n\nRecord: %s", err.Error(), record),
+			)
+			return
+		}
+		if _, hasRUF := dmarcTagValue(tags, "ruf"); !hasRUF {
+			diags.AddWarning(
+				summaryDMARCFOWithoutRUF,
+				fmt.Sprintf("The DMARC record sets fo=%s but has no ruf tag. Failure reporting options have no effect without a failure report URI.", fo),
+			)
+		}
+	}
+
+	// Receivers silently drop destinations they cannot parse, so report the
+	// offending entry instead of relying on dmarc.Parse, which accepts anything
+	for _, tag := range []string{"rua", "ruf"} {
+		value, ok := dmarcTagValue(tags, tag)
+		if !ok {
+			continue
+		}
+		uris, err := parseReportURIs(tag, value)
+		if err != nil {
+			diags.AddError(
+				summaryDMARCInvalidReportURI,
+				fmt.Sprintf("The DMARC record has an invalid report destination: %s\n\nRecord: %s", err.Error(), record),
+			)
+			return
+		}
+
+		distinct, duplicates := distinctReportURIs(uris)
+		if len(duplicates) > 0 {
+			diags.AddError(
+				summaryDMARCDuplicateReportURI,
+				fmt.Sprintf("The DMARC %s tag lists %s more than once, so receivers may send the same report twice. "+
+					"Remove the repeated destinations.\n\nRecord: %s", tag, strings.Join(duplicates, ", "), record),
+			)
+			return
+		}
+		var https, missingPath []string
+		for _, uri := range uris {
+			if uri.Scheme != "https" {
+				continue
+			}
+			https = append(https, uri.Address)
+			if reportURIMissingPath(uri) {
+				missingPath = append(missingPath, uri.Address)
+			}
+		}
+		if len(https) > 0 {
+			diags.AddWarning(
+				summaryDMARCHTTPSDestination,
+				fmt.Sprintf("The DMARC %s tag sends reports to %s. RFC 7489 allows https destinations, but most receivers only send reports to mailto destinations. "+
+					"Add a mailto destination as well to receive reports from them.", tag, strings.Join(https, ", ")),
+			)
+		}
+		if len(missingPath) > 0 {
+			diags.AddWarning(
+				summaryDMARCHTTPSNoPath,
+				fmt.Sprintf("The DMARC %s tag sends reports to %s, which has no path. Some receivers require a path to post reports to, "+
+					"e.g. https://reports.example.com/dmarc.", tag, strings.Join(missingPath, ", ")),
+			)
+		}
+		if tag == "rua" && len(distinct) > maxAggregateReportURIs {
+			diags.AddWarning(
+				summaryDMARCRUACount,
+				fmt.Sprintf("The DMARC record lists %d rua destinations. Every receiver sends a report to each of them, and some only honor the first %d. "+
+					"Consider sending reports to at most %d destinations and forwarding them from there.", len(distinct), maxAggregateReportURIs, maxAggregateReportURIs),
+			)
+		}
+	}
+
+	if ri, ok := dmarcTagValue(tags, "ri"); ok {
+		seconds, err := parseReportInterval(ri)
+		if err != nil {
+			diags.AddError(
+				summaryDMARCInvalidRecord,
+				fmt.Sprintf("The DMARC record is malformed: %s\n\nRecord: %s", err.Error(), record),
+			)
+			return
+		}
+		if seconds < minReportInterval {
+			diags.AddWarning(
+				summaryDMARCReportInterval,
+				fmt.Sprintf("The DMARC record requests aggregate reports every %d seconds (ri=%s). "+
+					"Most receivers only send daily reports and ignore intervals under %d seconds.", seconds, ri, minReportInterval),
+			)
+		}
+	}
+
+	if rf, ok := dmarcTagValue(tags, "rf"); ok && rf != defaultReportFormat {
+		diags.AddWarning(
+			summaryDMARCReportFormat,
+			fmt.Sprintf("The DMARC record sets rf=%s. Most receivers only support the Authentication Failure Reporting Format (rf=afrf) "+
+				"and will not send failure reports in other formats.", rf),
+		)
+	}
+
+	_, err := parseDMARCRecord(record)
+	if err != nil {
+		diags.AddError(
+			summaryDMARCInvalidRecord,
+			fmt.Sprintf("The DMARC record is malformed: %s\n\nRecord: %s", err.Error(), record),
+		)
+	}
+}
+
+// policyStrength ranks DMARC policies from least (none) to most (reject)
+// restrictive.
+func policyStrength(p dmarc.Policy) int {
+	switch p {
+	case dmarc.PolicyReject:
+		return 2
+	case dmarc.PolicyQuarantine:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// isEnforcingDMARCPolicy reports whether a DMARC record applies an enforcing
+// policy to all mail that fails DMARC.
+func isEnforcingDMARCPolicy(rec *dmarc.Record) bool {
+	if rec.Policy != dmarc.PolicyQuarantine && rec.Policy != dmarc.PolicyReject {
+		return false
+	}
+	return rec.Percent == nil || *rec.Percent == 100
+}
+
+// effectiveSubdomainPolicy returns the policy applied to subdomains, which is
+// inherited from p when the sp tag is absent.
+func effectiveSubdomainPolicy(rec *dmarc.Record) dmarc.Policy {
+	if rec.SubdomainPolicy != "" {
+		return rec.SubdomainPolicy
+	}
+	return rec.Policy
+}
+
+// compareSubdomainPolicy describes the sp policy relative to the p policy.
+func compareSubdomainPolicy(rec *dmarc.Record) string {
+	switch sp, p := policyStrength(effectiveSubdomainPolicy(rec)), policyStrength(rec.Policy); {
+	case sp < p:
+		return "weaker"
+	case sp > p:
+		return "stronger"
+	default:
+		return "same"
+	}
+}
+
+// reportURIList converts parsed report destinations to a Terraform list.
+func reportURIList(uris []dmarcReportURI, diags *diag.Diagnostics) types.List {
+	values := make([]attr.Value, 0, len(uris))
+	for _, uri := range uris {
+		maxSize := types.Int64Null()
+		if uri.MaxSize != nil {
+			maxSize = types.Int64Value(*uri.MaxSize)
+		}
+		obj, objDiags := types.ObjectValue(
+			reportURIObjectType.AttrTypes,
+			map[string]attr.Value{
+				"scheme":   types.StringValue(uri.Scheme),
+				"address":  types.StringValue(uri.Address),
+				"max_size": maxSize,
+			},
+		)
+		diags.Append(objDiags...)
+		values = append(values, obj)
+	}
+
+	list, listDiags := types.ListValue(reportURIObjectType, values)
+	diags.Append(listDiags...)
+	return list
 }
 
 // convertStringSliceToList converts a Go string slice to a Terraform list.
@@ -166,3 +715,41 @@ func convertStringSliceToList(ctx context.Context, slice []string, diags *diag.D
 	diags.Append(d...)
 	return list
 }
+
+// convertStringMapToMap converts a Go string map to a Terraform map.
+func convertStringMapToMap(ctx context.Context, m map[string]string, diags *diag.Diagnostics) types.Map {
+	result, d := types.MapValueFrom(ctx, types.StringType, m)
+	diags.Append(d...)
+	return result
+}
+
+// lookupDMARCRecord returns the DMARC record published for domain. RFC 7489
+// section 6.6.3 requires exactly one record beginning with "v=DMARC1";
+// receivers apply no policy when there are several.
+func lookupDMARCRecord(ctx context.Context, resolver dnsResolver, domain string) (string, error) {
+	name := "_dmarc." + strings.ToLower(strings.TrimSuffix(domain, "."))
+	txts, err := resolver.LookupTXT(ctx, name)
+	if err != nil {
+		if isNotFound(err) {
+			return "", fmt.Errorf("no DMARC record found at %s", name)
+		}
+		return "", fmt.Errorf("unable to look up the DMARC record at %s: %w", name, err)
+	}
+
+	var records []string
+	for _, txt := range txts {
+		version, _, _ := strings.Cut(txt, ";")
+		if strings.TrimSpace(version) == "v=DMARC1" {
+			records = append(records, txt)
+		}
+	}
+
+	switch len(records) {
+	case 0:
+		return "", fmt.Errorf("no DMARC record found at %s", name)
+	case 1:
+		return records[0], nil
+	default:
+		return "", fmt.Errorf("%d DMARC records found at %s, but only one is allowed; receivers ignore them all and apply no policy", len(records), name)
+	}
+}
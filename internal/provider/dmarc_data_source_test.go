@@ -0,0 +1,346 @@
+package provider
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/emersion/go-msgauth/dmarc"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+func TestEffectiveSubdomainPolicy(t *testing.T) {
+	tests := []struct {
+		name         string
+		record       string
+		wantPolicy   dmarc.Policy
+		wantStrength string
+	}{
+		{name: "inherited", record: "v=DMARC1; p=reject", wantPolicy: dmarc.PolicyReject, wantStrength: "same"},
+		{name: "explicit same", record: "v=DMARC1; p=quarantine; sp=quarantine", wantPolicy: dmarc.PolicyQuarantine, wantStrength: "same"},
+		{name: "weaker", record: "v=DMARC1; p=quarantine; sp=none", wantPolicy: dmarc.PolicyNone, wantStrength: "weaker"},
+		{name: "stronger", record: "v=DMARC1; p=none; sp=reject", wantPolicy: dmarc.PolicyReject, wantStrength: "stronger"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec, err := dmarc.Parse(tt.record)
+			if err != nil {
+				t.Fatalf("dmarc.Parse() error = %v", err)
+			}
+			if got := effectiveSubdomainPolicy(rec); got != tt.wantPolicy {
+				t.Errorf("effectiveSubdomainPolicy() = %q, want %q", got, tt.wantPolicy)
+			}
+			if got := compareSubdomainPolicy(rec); got != tt.wantStrength {
+				t.Errorf("compareSubdomainPolicy() = %q, want %q", got, tt.wantStrength)
+			}
+		})
+	}
+}
+
+func TestWeakDMARCSubdomainPolicy(t *testing.T) {
+	tests := []struct {
+		name         string
+		record       string
+		wantStrength string
+		wantWarning  bool
+	}{
+		{name: "reject with sp=none", record: "v=DMARC1; p=reject; sp=none; rua=mailto:dmarc@example.com", wantStrength: "weaker", wantWarning: true},
+		{name: "reject with sp=quarantine", record: "v=DMARC1; p=reject; sp=quarantine; rua=mailto:dmarc@example.com", wantStrength: "weaker", wantWarning: true},
+		{name: "sp absent", record: "v=DMARC1; p=reject; rua=mailto:dmarc@example.com", wantStrength: "same"},
+		{name: "sp stronger", record: "v=DMARC1; p=quarantine; sp=reject; rua=mailto:dmarc@example.com", wantStrength: "stronger"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := DMARCDataSourceModel{Record: types.StringValue(tt.record)}
+			var diags diag.Diagnostics
+			(&DMARCDataSource{}).read(context.Background(), &data, &diags)
+			if diags.HasError() {
+				t.Fatalf("read() diagnostics = %v", diags)
+			}
+
+			if got := data.SubdomainStrength.ValueString(); got != tt.wantStrength {
+				t.Errorf("subdomain_policy_strength = %q, want %q", got, tt.wantStrength)
+			}
+			if got := hasDiagnostic(diags, diag.SeverityWarning, "Weak DMARC Subdomain Policy"); got != tt.wantWarning {
+				t.Errorf("read() diagnostics = %v, want Weak DMARC Subdomain Policy %v", diags, tt.wantWarning)
+			}
+		})
+	}
+}
+
+func TestDMARCThirdPartyFailureReports(t *testing.T) {
+	data := DMARCDataSourceModel{
+		Record: types.StringValue("v=DMARC1; p=reject; rua=mailto:dmarc@example.com; ruf=mailto:ruf@example.com,mailto:ruf@Vendor.net"),
+		Domain: types.StringValue("Example.com."),
+	}
+	var diags diag.Diagnostics
+	(&DMARCDataSource{}).read(context.Background(), &data, &diags)
+
+	var warnings []diag.Diagnostic
+	for _, d := range diags {
+		if d.Summary() == "DMARC Failure Reports Sent to Third Party" {
+			warnings = append(warnings, d)
+		}
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0].Detail(), "example.com._report._dmarc.vendor.net") {
+		t.Errorf("read() diagnostics = %v, want one third-party warning naming example.com._report._dmarc.vendor.net", diags)
+	}
+}
+
+func TestDMARCMonitoringOnlyPolicy(t *testing.T) {
+	const none = "v=DMARC1; p=none; rua=mailto:dmarc@example.com"
+	ignored := map[string]bool{"DMARC_POLICY_NONE": true}
+
+	tests := []struct {
+		name     string
+		provider *providerData
+		record   string
+		want     diag.Severity // SeverityInvalid for no diagnostic
+	}{
+		{name: "unconfigured", provider: nil, record: none, want: diag.SeverityInvalid},
+		{name: "not enabled", provider: &providerData{}, record: none, want: diag.SeverityInvalid},
+		{name: "enabled", provider: &providerData{warnOnMonitoring: true}, record: none, want: diag.SeverityWarning},
+		{name: "enforcing policy", provider: &providerData{warnOnMonitoring: true}, record: "v=DMARC1; p=reject; rua=mailto:dmarc@example.com", want: diag.SeverityInvalid},
+		{name: "ignored", provider: &providerData{warnOnMonitoring: true, ignoredWarnings: ignored}, record: none, want: diag.SeverityInvalid},
+		{name: "strict mode", provider: &providerData{warnOnMonitoring: true, strictMode: true}, record: none, want: diag.SeverityError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := DMARCDataSourceModel{Record: types.StringValue(tt.record)}
+			var diags diag.Diagnostics
+			(&DMARCDataSource{providerData: tt.provider}).read(context.Background(), &data, &diags)
+			tt.provider.applyWarningSettings(&diags)
+
+			var found []diag.Diagnostic
+			for _, d := range diags {
+				if d.Summary() == "Monitoring-Only DMARC Policy" {
+					found = append(found, d)
+				}
+			}
+			if tt.want == diag.SeverityInvalid {
+				if len(found) > 0 {
+					t.Errorf("read() diagnostics = %v, want no Monitoring-Only DMARC Policy", diags)
+				}
+				return
+			}
+			if len(found) != 1 || found[0].Severity() != tt.want || !strings.HasSuffix(found[0].Detail(), "Diagnostic code: DMARC_POLICY_NONE") {
+				t.Errorf("read() diagnostics = %v, want one Monitoring-Only DMARC Policy %v with code DMARC_POLICY_NONE", diags, tt.want)
+			}
+		})
+	}
+
+	resp := testConfigureProvider(t, map[string]tftypes.Value{
+		"warn_on_monitoring": tftypes.NewValue(tftypes.Bool, true),
+		"ignored_warnings":   tftypes.NewValue(tftypes.List{ElementType: tftypes.String}, []tftypes.Value{tftypes.NewValue(tftypes.String, "DMARC_POLICY_NONE")}),
+	})
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("Configure() diagnostics = %v", resp.Diagnostics)
+	}
+	if p := resp.DataSourceData.(*providerData); !p.warnOnMonitoring || !p.ignoredWarnings["DMARC_POLICY_NONE"] {
+		t.Errorf("Configure() = %+v, want warn_on_monitoring with DMARC_POLICY_NONE ignored", p)
+	}
+}
+
+func TestDMARCPercentWarnings(t *testing.T) {
+	tests := []struct {
+		name        string
+		record      string
+		wantPercent int64 // -1 for null
+		wantWarning string
+	}{
+		{name: "pct=0", record: "v=DMARC1; p=reject; pct=0", wantPercent: 0, wantWarning: "DMARC Policy Disabled by pct=0"},
+		{name: "pct=50", record: "v=DMARC1; p=quarantine; pct=50", wantPercent: 50, wantWarning: "Partial DMARC Policy"},
+		{name: "pct=100", record: "v=DMARC1; p=reject; pct=100", wantPercent: 100},
+		{name: "no pct", record: "v=DMARC1; p=reject", wantPercent: -1},
+		// pct=0 only disables enforcement, which p=none has none of
+		{name: "p=none with pct=0", record: "v=DMARC1; p=none; pct=0", wantPercent: 0, wantWarning: "Partial DMARC Policy"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := DMARCDataSourceModel{Record: types.StringValue(tt.record + "; rua=mailto:dmarc@example.com")}
+			var diags diag.Diagnostics
+			(&DMARCDataSource{}).read(context.Background(), &data, &diags)
+			if diags.HasError() {
+				t.Fatalf("read() diagnostics = %v", diags)
+			}
+
+			gotPercent := int64(-1)
+			if !data.Percent.IsNull() {
+				gotPercent = data.Percent.ValueInt64()
+			}
+			if gotPercent != tt.wantPercent {
+				t.Errorf("percent = %d, want %d", gotPercent, tt.wantPercent)
+			}
+
+			for _, summary := range []string{"DMARC Policy Disabled by pct=0", "Partial DMARC Policy"} {
+				if got := hasDiagnostic(diags, diag.SeverityWarning, summary); got != (summary == tt.wantWarning) {
+					t.Errorf("read() diagnostics = %v, want %s %v", diags, summary, summary == tt.wantWarning)
+				}
+			}
+		})
+	}
+}
+
+func TestDMARCNoAggregateReporting(t *testing.T) {
+	tests := []struct {
+		name        string
+		record      string
+		wantWarning bool
+	}{
+		{name: "no rua", record: "v=DMARC1; p=reject", wantWarning: true},
+		{name: "ruf only", record: "v=DMARC1; p=reject; ruf=mailto:ruf@example.com", wantWarning: true},
+		{name: "rua", record: "v=DMARC1; p=reject; rua=mailto:dmarc@example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := DMARCDataSourceModel{Record: types.StringValue(tt.record)}
+			var diags diag.Diagnostics
+			(&DMARCDataSource{}).read(context.Background(), &data, &diags)
+			if got := hasDiagnostic(diags, diag.SeverityWarning, "No DMARC Aggregate Reporting"); got != tt.wantWarning {
+				t.Errorf("read() diagnostics = %v, want No DMARC Aggregate Reporting %v", diags, tt.wantWarning)
+			}
+		})
+	}
+}
+
+func TestDMARCTXTStringLength(t *testing.T) {
+	record := func(n int) string {
+		r := "v=DMARC1; p=reject; rua=mailto:"
+		return r + strings.Repeat("a", n-len(r))
+	}
+
+	tests := []struct {
+		name     string
+		record   string
+		wantNear bool
+		wantOver bool
+	}{
+		{name: "short", record: record(100)},
+		{name: "at near threshold", record: record(dmarcNearTXTStringLength)},
+		{name: "near limit", record: record(250), wantNear: true},
+		{name: "at limit", record: record(maxTXTStringLength), wantNear: true},
+		{name: "over limit", record: record(300), wantOver: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var diags diag.Diagnostics
+			validateDMARCRecord(tt.record, &diags)
+			if got := hasDiagnostic(diags, diag.SeverityWarning, summaryDMARCTXTStringNearLimit); got != tt.wantNear {
+				t.Errorf("validateDMARCRecord() diagnostics = %v, want %s %v", diags, summaryDMARCTXTStringNearLimit, tt.wantNear)
+			}
+			if got := hasDiagnostic(diags, diag.SeverityWarning, summaryDMARCTXTStringLength); got != tt.wantOver {
+				t.Errorf("validateDMARCRecord() diagnostics = %v, want %s %v", diags, summaryDMARCTXTStringLength, tt.wantOver)
+			}
+		})
+	}
+}
+
+func TestDMARCAlignmentExplicit(t *testing.T) {
+	tests := []struct {
+		name        string
+		record      string
+		wantDKIM    string
+		wantDKIMSet bool
+		wantSPF     string
+		wantSPFSet  bool
+	}{
+		{name: "omitted", record: "v=DMARC1; p=reject", wantDKIM: "r", wantSPF: "r"},
+		{name: "explicit relaxed", record: "v=DMARC1; p=reject; adkim=r; aspf=r", wantDKIM: "r", wantDKIMSet: true, wantSPF: "r", wantSPFSet: true},
+		{name: "explicit adkim only", record: "v=DMARC1; p=reject; adkim=r", wantDKIM: "r", wantDKIMSet: true, wantSPF: "r"},
+		{name: "explicit aspf only", record: "v=DMARC1; p=reject; aspf=r", wantDKIM: "r", wantSPF: "r", wantSPFSet: true},
+		{name: "strict", record: "v=DMARC1; p=reject; adkim=s; aspf=s", wantDKIM: "s", wantDKIMSet: true, wantSPF: "s", wantSPFSet: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := DMARCDataSourceModel{Record: types.StringValue(tt.record)}
+			var diags diag.Diagnostics
+			(&DMARCDataSource{}).read(context.Background(), &data, &diags)
+			if diags.HasError() {
+				t.Fatalf("read() errors = %v", diags)
+			}
+			if got := data.DKIMAlignment.ValueString(); got != tt.wantDKIM {
+				t.Errorf("dkim_alignment = %q, want %q", got, tt.wantDKIM)
+			}
+			if got := data.DKIMAlignmentSet.ValueBool(); got != tt.wantDKIMSet {
+				t.Errorf("dkim_alignment_explicit = %v, want %v", got, tt.wantDKIMSet)
+			}
+			if got := data.SPFAlignment.ValueString(); got != tt.wantSPF {
+				t.Errorf("spf_alignment = %q, want %q", got, tt.wantSPF)
+			}
+			if got := data.SPFAlignmentSet.ValueBool(); got != tt.wantSPFSet {
+				t.Errorf("spf_alignment_explicit = %v, want %v", got, tt.wantSPFSet)
+			}
+		})
+	}
+}
+
+func TestIsEnforcingDMARCPolicy(t *testing.T) {
+	tests := []struct {
+		record string
+		want   bool
+	}{
+		{record: "v=DMARC1; p=none", want: false},
+		{record: "v=DMARC1; p=quarantine", want: true},
+		{record: "v=DMARC1; p=reject; pct=100", want: true},
+		{record: "v=DMARC1; p=reject; pct=50", want: false},
+		{record: "v=DMARC1; p=quarantine; pct=0", want: false},
+		{record: "v=DMARC1; p=none; pct=100", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.record, func(t *testing.T) {
+			rec, err := dmarc.Parse(tt.record)
+			if err != nil {
+				t.Fatalf("dmarc.Parse() error = %v", err)
+			}
+			if got := isEnforcingDMARCPolicy(rec); got != tt.want {
+				t.Errorf("isEnforcingDMARCPolicy() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLookupDMARCRecord(t *testing.T) {
+	resolver := &fakeResolver{txt: map[string][]string{
+		"_dmarc.example.com":  {"google-site-verification=abc", "v=DMARC1; p=reject"},
+		"_dmarc.example.net":  {"v=DMARC1; p=none", "v=DMARC1; p=reject"},
+		"_dmarc.example.org":  {"v=spf1 -all"},
+		"_dmarc.example.info": {"v=DMARC1 ; p=quarantine"},
+	}}
+
+	tests := []struct {
+		domain  string
+		want    string
+		wantErr string
+	}{
+		{domain: "Example.com.", want: "v=DMARC1; p=reject"},
+		{domain: "example.info", want: "v=DMARC1 ; p=quarantine"},
+		{domain: "example.net", wantErr: "2 DMARC records found at _dmarc.example.net"},
+		{domain: "example.org", wantErr: "no DMARC record found at _dmarc.example.org"},
+		{domain: "example.edu", wantErr: "no DMARC record found at _dmarc.example.edu"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.domain, func(t *testing.T) {
+			got, err := lookupDMARCRecord(context.Background(), resolver, tt.domain)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("lookupDMARCRecord() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("lookupDMARCRecord() = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var (
+	_ datasource.DataSource                   = &DMARCExternalCheckDataSource{}
+	_ datasource.DataSourceWithValidateConfig = &DMARCExternalCheckDataSource{}
+	_ datasource.DataSourceWithConfigure      = &DMARCExternalCheckDataSource{}
+)
+
+func NewDMARCExternalCheckDataSource() datasource.DataSource {
+	return &DMARCExternalCheckDataSource{}
+}
+
+// DMARCExternalCheckDataSource defines the data source implementation.
+type DMARCExternalCheckDataSource struct {
+	providerData *providerData
+}
+
+// DMARCExternalCheckDataSourceModel describes the data source data model.
+type DMARCExternalCheckDataSourceModel struct {
+	Domain              types.String `tfsdk:"domain"`
+	ReportURI           types.String `tfsdk:"report_uri"`
+	ChangeTicket        types.String `tfsdk:"change_ticket"`
+	Required            types.Bool   `tfsdk:"required"`
+	AuthorizationDomain types.String `tfsdk:"authorization_domain"`
+	Authorized          types.Bool   `tfsdk:"authorized"`
+	Record              types.String `tfsdk:"record"`
+}
+
+func (d *DMARCExternalCheckDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_dmarc_external_check"
+}
+
+func (d *DMARCExternalCheckDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Checks that a DMARC report destination on another domain has authorized the policy domain to send it reports. " +
+			"Receivers only deliver reports to external destinations that publish a `<domain>._report._dmarc.<destination>` TXT record.",
+
+		Attributes: map[string]schema.Attribute{
+			"domain": schema.StringAttribute{
+				MarkdownDescription: "The domain publishing the DMARC record (e.g., `example.com`)",
+				Required:            true,
+			},
+			"report_uri": schema.StringAttribute{
+				MarkdownDescription: "A single rua or ruf destination from the record (e.g., `mailto:dmarc@vendor.example.net`)",
+				Required:            true,
+			},
+			"change_ticket": changeTicketAttribute(),
+			"required": schema.BoolAttribute{
+				MarkdownDescription: "True if the destination is outside `domain` and therefore needs an authorization record",
+				Computed:            true,
+			},
+			"authorization_domain": schema.StringAttribute{
+				MarkdownDescription: "The name queried for the authorization record (e.g., `example.com._report._dmarc.vendor.example.net`). Null when no authorization is required",
+				Computed:            true,
+			},
+			"authorized": schema.BoolAttribute{
+				MarkdownDescription: "True if the destination will receive reports: either it is within `domain` or it publishes a valid authorization record",
+				Computed:            true,
+			},
+			"record": schema.StringAttribute{
+				MarkdownDescription: "The authorization TXT record found, if any",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (d *DMARCExternalCheckDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	d.providerData = configureProviderData(req, resp)
+}
+
+func (d *DMARCExternalCheckDataSource) ValidateConfig(ctx context.Context, req datasource.ValidateConfigRequest, resp *datasource.ValidateConfigResponse) {
+	defer d.providerData.applyWarningSettings(&resp.Diagnostics)
+
+	var data DMARCExternalCheckDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Skip validation if the URI is unknown (e.g., depends on another resource)
+	if data.ReportURI.IsUnknown() || data.ReportURI.IsNull() {
+		return
+	}
+
+	if _, err := parseReportURI(data.ReportURI.ValueString()); err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("report_uri"),
+			summaryDMARCInvalidReportURI,
+			fmt.Sprintf("The report URI %q is invalid: %s", data.ReportURI.ValueString(), err.Error()),
+		)
+	}
+}
+
+func (d *DMARCExternalCheckDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	defer d.providerData.applyWarningSettings(&resp.Diagnostics)
+
+	var data DMARCExternalCheckDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data.ChangeTicket = d.providerData.checkChangeTicket(data.ChangeTicket, &resp.Diagnostics)
+
+	domain := data.Domain.ValueString()
+	uri, err := parseReportURI(data.ReportURI.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			summaryDMARCInvalidReportURI,
+			fmt.Sprintf("The report URI is invalid: %s", err.Error()),
+		)
+		return
+	}
+
+	data.Required = types.BoolValue(isExternalReportURI(domain, uri))
+	data.AuthorizationDomain = types.StringNull()
+	data.Record = types.StringNull()
+
+	if !data.Required.ValueBool() {
+		data.Authorized = types.BoolValue(true)
+		resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+		return
+	}
+
+	name := dmarcAuthorizationName(domain, reportURIHost(uri))
+	data.AuthorizationDomain = types.StringValue(name)
+
+	record, err := lookupDMARCAuthorization(ctx, d.providerData.dnsResolver(), name)
+	if err != nil {
+		resp.Diagnostics.AddError(lookupFailure(
+			summaryDMARCAuthorizationLookupFailed,
+			fmt.Sprintf("Unable to look up the DMARC authorization record at %s: %s", name, err.Error()),
+			err,
+		))
+		return
+	}
+
+	data.Authorized = types.BoolValue(record != "")
+	if record != "" {
+		data.Record = types.StringValue(record)
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// dmarcAuthorizationName returns the name at which a report destination host
+// authorizes a policy domain, as described in RFC 7489 section 7.1.
+func dmarcAuthorizationName(domain, host string) string {
+	return strings.ToLower(strings.TrimSuffix(domain, ".")) + "._report._dmarc." + host
+}
+
+// lookupDMARCAuthorization returns the first TXT record at name that begins
+// with "v=DMARC1", or an empty string when there is none.
+func lookupDMARCAuthorization(ctx context.Context, resolver dnsResolver, name string) (string, error) {
+	txts, err := resolver.LookupTXT(ctx, name)
+	if err != nil {
+		if isNotFound(err) {
+			return "", nil
+		}
+		return "", err
+	}
+
+	for _, txt := range txts {
+		version, _, _ := strings.Cut(txt, ";")
+		if strings.TrimSpace(version) == "v=DMARC1" {
+			return txt, nil
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,37 @@
+package provider
+
+import (
+	"context"
+	"testing"
+)
+
+func TestLookupDMARCAuthorization(t *testing.T) {
+	resolver := &fakeResolver{txt: map[string][]string{
+		"example.com._report._dmarc.vendor.net":  {"v=DMARC1"},
+		"example.com._report._dmarc.other.net":   {"unrelated", "v=DMARC1; rua=mailto:dmarc@other.net"},
+		"example.com._report._dmarc.invalid.net": {"v=spf1 -all"},
+	}}
+
+	tests := []struct {
+		name string
+		host string
+		want string
+	}{
+		{name: "authorized", host: "vendor.net", want: "v=DMARC1"},
+		{name: "authorized with tags", host: "other.net", want: "v=DMARC1; rua=mailto:dmarc@other.net"},
+		{name: "not a DMARC record", host: "invalid.net", want: ""},
+		{name: "missing", host: "missing.net", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := lookupDMARCAuthorization(context.Background(), resolver, dmarcAuthorizationName("Example.com.", tt.host))
+			if err != nil {
+				t.Fatalf("lookupDMARCAuthorization() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("lookupDMARCAuthorization() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
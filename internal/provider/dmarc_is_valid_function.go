@@ -0,0 +1,63 @@
+package provider
+
+import (
+	"context"
+	"errors"
+
+	"github.com/emersion/go-msgauth/dmarc"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &DMARCIsValidFunction{}
+
+func NewDMARCIsValidFunction() function.Function {
+	return &DMARCIsValidFunction{}
+}
+
+// DMARCIsValidFunction defines the function implementation.
+type DMARCIsValidFunction struct{}
+
+func (f *DMARCIsValidFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "dmarc_is_valid"
+}
+
+func (f *DMARCIsValidFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Check whether a DMARC record is valid",
+		MarkdownDescription: "Returns `true` if a DMARC record passes the checks of the `emaildns_dmarc` data source that would fail the plan, " +
+			"and `false` otherwise. Warnings do not make a record invalid. Unlike the data source, an invalid record is not an error, " +
+			"so the result can be used directly in conditions, `count`, and `for_each`.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "record",
+				MarkdownDescription: "The DMARC record content (e.g., `v=DMARC1; p=reject; rua=mailto:dmarc@example.com`)",
+			},
+		},
+		Return: function.BoolReturn{},
+	}
+}
+
+func (f *DMARCIsValidFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var record string
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &record))
+	if resp.Error != nil {
+		return
+	}
+
+	_, err := parseValidDMARCRecord(record)
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, err == nil))
+}
+
+// parseValidDMARCRecord parses a DMARC record passed to a function. A record
+// is only returned when validateDMARCRecord finds no errors in it.
+func parseValidDMARCRecord(record string) (*dmarc.Record, error) {
+	var diags diag.Diagnostics
+	validateDMARCRecord(record, &diags)
+	for _, d := range diags.Errors() {
+		return nil, errors.New(d.Detail())
+	}
+	return parseDMARCRecord(record)
+}
@@ -0,0 +1,40 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestDMARCIsValidFunction(t *testing.T) {
+	tests := []struct {
+		name   string
+		record string
+		want   bool
+	}{
+		{name: "valid", record: "v=DMARC1; p=reject; rua=mailto:dmarc@example.com", want: true},
+		{name: "warnings only", record: "v=DMARC1; p=none", want: true},
+		{name: "invalid policy", record: "v=DMARC1; p=rejectt", want: false},
+		{name: "tag order", record: "p=reject; v=DMARC1", want: false},
+		{name: "duplicate tags", record: "v=DMARC1; p=reject; p=none", want: false},
+		{name: "empty", record: "", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := function.RunRequest{Arguments: function.NewArgumentsData([]attr.Value{types.StringValue(tt.record)})}
+			resp := function.RunResponse{Result: function.NewResultData(types.BoolUnknown())}
+			NewDMARCIsValidFunction().Run(context.Background(), req, &resp)
+
+			if resp.Error != nil {
+				t.Fatalf("Run() error = %v", resp.Error)
+			}
+			if got := resp.Result.Value(); !got.Equal(types.BoolValue(tt.want)) {
+				t.Errorf("Run() = %v, want %t", got, tt.want)
+			}
+		})
+	}
+}
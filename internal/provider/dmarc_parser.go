@@ -0,0 +1,395 @@
+package provider
+
+import (
+	"fmt"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/emersion/go-msgauth/dmarc"
+)
+
+// defaultReportFormat is the rf value receivers assume when the tag is absent.
+const defaultReportFormat = "afrf"
+
+// defaultReportInterval is the aggregate report interval, in seconds, that
+// receivers assume when the ri tag is absent.
+const defaultReportInterval = 86400
+
+// minReportInterval is the shortest ri value most receivers honor.
+const minReportInterval = 3600
+
+// maxAggregateReportURIs is the number of rua destinations above which a
+// warning is produced. Some receivers only send reports to the first two.
+const maxAggregateReportURIs = 2
+
+// reportFormatRe matches a single report format name in the rf tag.
+var reportFormatRe = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9-]*$`)
+
+// parseDMARCRecord parses a DMARC record with dmarc.Parse. The rf tag is
+// validated here instead, because dmarc.Parse rejects every format other than
+// afrf while RFC 7489 allows other registered formats.
+func parseDMARCRecord(record string) (*dmarc.Record, error) {
+	parts := strings.Split(record, ";")
+	kept := parts[:0:0]
+	for _, part := range parts {
+		name, value, found := strings.Cut(part, "=")
+		if found && strings.TrimSpace(name) == "rf" {
+			if _, err := parseReportFormats(strings.TrimSpace(value)); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		kept = append(kept, part)
+	}
+	return dmarc.Parse(strings.Join(kept, ";"))
+}
+
+// parseReportInterval validates the ri tag as a positive number of seconds.
+func parseReportInterval(ri string) (int64, error) {
+	seconds, err := strconv.ParseInt(ri, 10, 64)
+	if err != nil || seconds <= 0 {
+		return 0, fmt.Errorf("invalid report interval %q in ri tag (expected a positive number of seconds)", ri)
+	}
+	return seconds, nil
+}
+
+// parseReportFormats splits the rf tag into its colon-separated formats.
+func parseReportFormats(rf string) ([]string, error) {
+	formats := parseTagList(rf)
+	if len(formats) == 0 {
+		return nil, fmt.Errorf("empty rf tag")
+	}
+	for _, f := range formats {
+		if !reportFormatRe.MatchString(f) {
+			return nil, fmt.Errorf("invalid report format %q in rf tag", f)
+		}
+	}
+	return formats, nil
+}
+
+// dmarcReportURI is a single destination from a rua or ruf tag.
+type dmarcReportURI struct {
+	Scheme  string
+	Address string
+	// MaxSize is the maximum report size in bytes, or nil when the
+	// destination has no size limit.
+	MaxSize *int64
+}
+
+// reportSizeRe matches the "!" size limit suffix of a report URI, with an
+// optional k, m, g, or t unit.
+var reportSizeRe = regexp.MustCompile(`^([0-9]+)([kmgt]?)$`)
+
+// parseReportURIs splits a rua or ruf tag value into its comma-separated
+// destinations and parses each one.
+func parseReportURIs(tag, value string) ([]dmarcReportURI, error) {
+	var uris []dmarcReportURI
+	for _, raw := range strings.Split(value, ",") {
+		uri, err := parseReportURI(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s destination %q: %w", tag, strings.TrimSpace(raw), err)
+		}
+		uris = append(uris, uri)
+	}
+	return uris, nil
+}
+
+// parseReportURI parses a single report destination such as
+// "mailto:dmarc@example.com!10m" as described in RFC 7489 section 6.2. Both
+// mailto and https destinations are accepted; for https the address is the
+// full URL.
+func parseReportURI(raw string) (dmarcReportURI, error) {
+	var uri dmarcReportURI
+
+	// A literal "!" in the URI itself must be percent-encoded, so the last
+	// one introduces the size limit
+	if i := strings.LastIndex(raw, "!"); i >= 0 {
+		size, err := parseReportSize(raw[i+1:])
+		if err != nil {
+			return uri, err
+		}
+		uri.MaxSize = &size
+		raw = raw[:i]
+	}
+
+	scheme, address, found := strings.Cut(raw, ":")
+	if !found || scheme == "" || strings.Contains(scheme, "@") {
+		if strings.Contains(raw, "@") {
+			return uri, fmt.Errorf("missing URI scheme (did you mean mailto:%s?)", raw)
+		}
+		return uri, fmt.Errorf("missing URI scheme (expected mailto: or https:)")
+	}
+	uri.Scheme = strings.ToLower(scheme)
+
+	switch uri.Scheme {
+	case "mailto":
+	case "https":
+		u, err := url.Parse(raw)
+		if err != nil || u.Host == "" || u.User != nil {
+			return uri, fmt.Errorf("%q is not a valid https URL", raw)
+		}
+		uri.Address = raw
+		return uri, nil
+	default:
+		return uri, fmt.Errorf("unsupported URI scheme %q (expected mailto or https)", scheme)
+	}
+
+	address, err := url.PathUnescape(address)
+	if err != nil {
+		return uri, fmt.Errorf("invalid percent-encoding in address")
+	}
+	parsed, err := mail.ParseAddress(address)
+	if err != nil || parsed.Name != "" || parsed.Address != address {
+		return uri, fmt.Errorf("%q is not a valid email address", address)
+	}
+	uri.Address = address
+
+	return uri, nil
+}
+
+// reportURIMissingPath reports whether an https report destination has no
+// path, such as "https://reports.example.com". Some receivers only post
+// reports to URLs with a path.
+func reportURIMissingPath(uri dmarcReportURI) bool {
+	if uri.Scheme != "https" {
+		return false
+	}
+	u, err := url.Parse(uri.Address)
+	return err == nil && (u.Path == "" || u.Path == "/")
+}
+
+// reportURIKey returns the form of a report destination used to compare
+// destinations: the scheme and address, with the email domain or URL host
+// lowercased. The size limit is left out, since it does not change where
+// reports are sent.
+func reportURIKey(uri dmarcReportURI) string {
+	switch uri.Scheme {
+	case "mailto":
+		if local, domain, found := strings.Cut(uri.Address, "@"); found {
+			return "mailto:" + local + "@" + strings.ToLower(domain)
+		}
+	case "https":
+		// The address of an https destination is the full URL
+		if u, err := url.Parse(uri.Address); err == nil {
+			u.Scheme = "https"
+			u.Host = strings.ToLower(u.Host)
+			return u.String()
+		}
+		return uri.Address
+	}
+	return uri.Scheme + ":" + uri.Address
+}
+
+// distinctReportURIs returns the distinct destinations in uris, as compared
+// by reportURIKey, and those listed more than once.
+func distinctReportURIs(uris []dmarcReportURI) (distinct, duplicates []string) {
+	seen := make(map[string]int)
+	for _, uri := range uris {
+		key := reportURIKey(uri)
+		seen[key]++
+		switch seen[key] {
+		case 1:
+			distinct = append(distinct, key)
+		case 2:
+			duplicates = append(duplicates, key)
+		}
+	}
+	return distinct, duplicates
+}
+
+// isExternalReportURI reports whether a report destination is outside the
+// domain the record is published for. Destinations at the domain itself or
+// one of its subdomains are considered internal.
+func isExternalReportURI(domain string, uri dmarcReportURI) bool {
+	host := reportURIHost(uri)
+	if host == "" {
+		return false
+	}
+	return !isWithinDomain(host, domain)
+}
+
+// reportURIHost returns the lowercased domain that receives reports sent to a
+// destination: the email domain for mailto and the URL host for https.
+func reportURIHost(uri dmarcReportURI) string {
+	var host string
+	switch uri.Scheme {
+	case "mailto":
+		_, host, _ = strings.Cut(uri.Address, "@")
+	case "https":
+		if u, err := url.Parse(uri.Address); err == nil {
+			host = u.Hostname()
+		}
+	}
+	return strings.ToLower(strings.TrimSuffix(host, "."))
+}
+
+// dmarcReportingDomains returns the distinct domains that receive the
+// aggregate and failure reports of a DMARC record, in the order they appear.
+// Destinations that do not parse are skipped, as validation reports them.
+func dmarcReportingDomains(record string) []string {
+	domains := []string{}
+	seen := make(map[string]bool)
+	tags := splitDMARCTags(record)
+	for _, tag := range []string{"rua", "ruf"} {
+		value, ok := dmarcTagValue(tags, tag)
+		if !ok {
+			continue
+		}
+		uris, err := parseReportURIs(tag, value)
+		if err != nil {
+			continue
+		}
+		for _, uri := range uris {
+			if host := reportURIHost(uri); host != "" && !seen[host] {
+				seen[host] = true
+				domains = append(domains, host)
+			}
+		}
+	}
+	return domains
+}
+
+// parseReportSize converts a report size limit such as "10m" to bytes. Units
+// are binary multiples, so "1k" is 1024 bytes.
+func parseReportSize(s string) (int64, error) {
+	m := reportSizeRe.FindStringSubmatch(strings.ToLower(s))
+	if m == nil {
+		return 0, fmt.Errorf("invalid size limit %q (expected a number with an optional k, m, g, or t unit)", s)
+	}
+
+	size, err := strconv.ParseInt(m[1], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size limit %q", s)
+	}
+
+	shift := strings.Index("kmgt", m[2])*10 + 10
+	if m[2] == "" {
+		shift = 0
+	}
+	if size > (1<<63-1)>>shift {
+		return 0, fmt.Errorf("size limit %q is too large", s)
+	}
+	return size << shift, nil
+}
+
+// dmarcTag is a single tag=value pair from a DMARC record.
+type dmarcTag struct {
+	Name  string
+	Value string
+}
+
+// splitDMARCTags splits a DMARC record into its tags in the order they appear,
+// keeping duplicates. Unlike dmarc.Parse it does not validate tag values, so
+// it can be used to report problems the parser hides or rejects generically.
+func splitDMARCTags(record string) []dmarcTag {
+	var tags []dmarcTag
+	for _, part := range strings.Split(record, ";") {
+		name, value, found := strings.Cut(part, "=")
+		if !found {
+			continue
+		}
+		tags = append(tags, dmarcTag{
+			Name:  strings.TrimSpace(name),
+			Value: strings.TrimSpace(value),
+		})
+	}
+	return tags
+}
+
+// dmarcTagValue returns the value of the first tag with the given name.
+func dmarcTagValue(tags []dmarcTag, name string) (string, bool) {
+	for _, t := range tags {
+		if t.Name == name {
+			return t.Value, true
+		}
+	}
+	return "", false
+}
+
+// dmarcRawTags returns every tag in the record keyed by name, including tags
+// the provider does not model. Like dmarcTagValue, the first occurrence of a
+// duplicated tag wins.
+func dmarcRawTags(tags []dmarcTag) map[string]string {
+	raw := make(map[string]string, len(tags))
+	for _, t := range tags {
+		if _, ok := raw[t.Name]; !ok {
+			raw[t.Name] = t.Value
+		}
+	}
+	return raw
+}
+
+// checkDMARCTagOrder enforces RFC 7489 section 6.4: the record must begin
+// with v=DMARC1, matched case-sensitively, and p must be the second tag.
+func checkDMARCTagOrder(record string) error {
+	tags := splitDMARCTags(record)
+	if len(tags) == 0 || tags[0].Name != "v" || tags[0].Value != "DMARC1" {
+		return fmt.Errorf("record must begin with v=DMARC1")
+	}
+	if len(tags) < 2 || tags[1].Name != "p" {
+		return fmt.Errorf("p must be the second tag, directly after v=DMARC1")
+	}
+	return nil
+}
+
+// parseDMARCPolicy validates a policy tag value. dmarc.Parse only handles p
+// and sp, so this is used for tags it does not know about, such as np.
+func parseDMARCPolicy(tag, value string) (dmarc.Policy, error) {
+	switch p := dmarc.Policy(value); p {
+	case dmarc.PolicyNone, dmarc.PolicyQuarantine, dmarc.PolicyReject:
+		return p, nil
+	default:
+		return "", fmt.Errorf("invalid policy %q in %s tag (expected none, quarantine, or reject)", value, tag)
+	}
+}
+
+// knownDMARCTags are the tags defined by RFC 7489 plus np from DMARCbis.
+var knownDMARCTags = map[string]bool{
+	"v": true, "p": true, "sp": true, "np": true, "adkim": true, "aspf": true,
+	"pct": true, "rua": true, "ruf": true, "fo": true, "rf": true, "ri": true,
+}
+
+// unknownDMARCTags returns the names of tags that are not in knownDMARCTags,
+// in the order they appear. Receivers ignore unknown tags, so they usually
+// indicate a typo.
+func unknownDMARCTags(tags []dmarcTag) []string {
+	var unknown []string
+	for _, t := range tags {
+		if !knownDMARCTags[t.Name] {
+			unknown = append(unknown, t.Name)
+		}
+	}
+	return unknown
+}
+
+// duplicateDMARCTags returns the names of tags that appear more than once, in
+// the order their first repetition appears. RFC 7489 does not allow any tag
+// to repeat, and receivers disagree on which value wins.
+func duplicateDMARCTags(tags []dmarcTag) []string {
+	var duplicates []string
+	seen := make(map[string]int)
+	for _, t := range tags {
+		seen[t.Name]++
+		if seen[t.Name] == 2 {
+			duplicates = append(duplicates, t.Name)
+		}
+	}
+	return duplicates
+}
+
+// parseFailureOptions splits the fo tag into its colon-separated options and
+// validates each one against RFC 7489 section 6.3.
+func parseFailureOptions(fo string) ([]string, error) {
+	options := parseTagList(fo)
+	for _, o := range options {
+		switch o {
+		case "0", "1", "d", "s":
+		default:
+			return nil, fmt.Errorf("invalid failure reporting option %q in fo tag (expected 0, 1, d, or s)", o)
+		}
+	}
+	return options, nil
+}
@@ -0,0 +1,52 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &DMARCPolicyFunction{}
+
+func NewDMARCPolicyFunction() function.Function {
+	return &DMARCPolicyFunction{}
+}
+
+// DMARCPolicyFunction defines the function implementation.
+type DMARCPolicyFunction struct{}
+
+func (f *DMARCPolicyFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "dmarc_policy"
+}
+
+func (f *DMARCPolicyFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Get the policy of a DMARC record",
+		MarkdownDescription: "Returns the policy (`none`, `quarantine`, or `reject`) of the `p` tag of a DMARC record, " +
+			"or `null` when the record is not valid according to `dmarc_is_valid`.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "record",
+				MarkdownDescription: "The DMARC record content (e.g., `v=DMARC1; p=reject; rua=mailto:dmarc@example.com`)",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *DMARCPolicyFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var record string
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &record))
+	if resp.Error != nil {
+		return
+	}
+
+	policy := types.StringNull()
+	if parsed, err := parseValidDMARCRecord(record); err == nil {
+		policy = types.StringValue(string(parsed.Policy))
+	}
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, policy))
+}
@@ -0,0 +1,37 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestDMARCPolicyFunction(t *testing.T) {
+	tests := []struct {
+		name   string
+		record string
+		want   types.String
+	}{
+		{name: "reject", record: "v=DMARC1; p=reject; sp=none", want: types.StringValue("reject")},
+		{name: "none", record: "v=DMARC1; p=none", want: types.StringValue("none")},
+		{name: "invalid", record: "v=DMARC1; p=rejectt", want: types.StringNull()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := function.RunRequest{Arguments: function.NewArgumentsData([]attr.Value{types.StringValue(tt.record)})}
+			resp := function.RunResponse{Result: function.NewResultData(types.StringUnknown())}
+			NewDMARCPolicyFunction().Run(context.Background(), req, &resp)
+
+			if resp.Error != nil {
+				t.Fatalf("Run() error = %v", resp.Error)
+			}
+			if got := resp.Result.Value(); !got.Equal(tt.want) {
+				t.Errorf("Run() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
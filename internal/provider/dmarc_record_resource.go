@@ -0,0 +1,423 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var (
+	_ resource.Resource                   = &DMARCRecordResource{}
+	_ resource.ResourceWithValidateConfig = &DMARCRecordResource{}
+	_ resource.ResourceWithModifyPlan     = &DMARCRecordResource{}
+)
+
+func NewDMARCRecordResource() resource.Resource {
+	return &DMARCRecordResource{}
+}
+
+// DMARCRecordResource assembles a DMARC record from its tags.
+type DMARCRecordResource struct{}
+
+// DMARCRecordResourceModel describes the resource data model.
+type DMARCRecordResourceModel struct {
+	ID                types.String `tfsdk:"id"`
+	Policy            types.String `tfsdk:"policy"`
+	SubdomainPolicy   types.String `tfsdk:"subdomain_policy"`
+	NonexistentPolicy types.String `tfsdk:"nonexistent_subdomain_policy"`
+	Percent           types.Int64  `tfsdk:"percent"`
+	DKIMAlignment     types.String `tfsdk:"dkim_alignment"`
+	SPFAlignment      types.String `tfsdk:"spf_alignment"`
+	RUA               types.List   `tfsdk:"rua"`
+	RUF               types.List   `tfsdk:"ruf"`
+	FailureOptions    types.List   `tfsdk:"failure_options"`
+	ReportFormat      types.String `tfsdk:"report_format"`
+	ReportInterval    types.Int64  `tfsdk:"report_interval"`
+	Record            types.String `tfsdk:"record"`
+}
+
+// dmarcRecordSettings holds the tag values of a DMARC record to build. Empty
+// strings, nil slices, and nil pointers leave the tag out of the record.
+type dmarcRecordSettings struct {
+	Policy            string
+	SubdomainPolicy   string
+	NonexistentPolicy string
+	Percent           *int64
+	DKIMAlignment     string
+	SPFAlignment      string
+	RUA               []string
+	RUF               []string
+	FailureOptions    []string
+	ReportFormat      string
+	ReportInterval    *int64
+}
+
+func (r *DMARCRecordResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_dmarc_record"
+}
+
+func (r *DMARCRecordResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Assembles a DMARC record from its tags, in canonical order, so records can be written declaratively instead of by joining strings. " +
+			"The record is checked with the same rules as the `emaildns_dmarc` data source, and terraform plan fails if it is invalid.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				MarkdownDescription: "The assembled record",
+				Computed:            true,
+			},
+			"policy": schema.StringAttribute{
+				MarkdownDescription: "The policy for the domain (p tag): `none`, `quarantine`, or `reject`",
+				Required:            true,
+			},
+			"subdomain_policy": schema.StringAttribute{
+				MarkdownDescription: "The policy for subdomains (sp tag): `none`, `quarantine`, or `reject`. Subdomains use `policy` when unset",
+				Optional:            true,
+			},
+			"nonexistent_subdomain_policy": schema.StringAttribute{
+				MarkdownDescription: "The policy for subdomains that do not exist (np tag, from DMARCbis): `none`, `quarantine`, or `reject`",
+				Optional:            true,
+			},
+			"percent": schema.Int64Attribute{
+				MarkdownDescription: "The percentage of failing messages the policy applies to (pct tag), from 0 to 100. Receivers assume 100 when unset",
+				Optional:            true,
+			},
+			"dkim_alignment": schema.StringAttribute{
+				MarkdownDescription: "The DKIM alignment mode (adkim tag): `r` for relaxed or `s` for strict. Receivers assume `r` when unset",
+				Optional:            true,
+			},
+			"spf_alignment": schema.StringAttribute{
+				MarkdownDescription: "The SPF alignment mode (aspf tag): `r` for relaxed or `s` for strict. Receivers assume `r` when unset",
+				Optional:            true,
+			},
+			"rua": schema.ListAttribute{
+				MarkdownDescription: "Aggregate report destinations (rua tag), as `mailto:` or `https:` URIs with an optional `!` size limit (e.g., `[\"mailto:dmarc@example.com\"]`)",
+				Optional:            true,
+				ElementType:         types.StringType,
+			},
+			"ruf": schema.ListAttribute{
+				MarkdownDescription: "Failure report destinations (ruf tag), in the same format as `rua`",
+				Optional:            true,
+				ElementType:         types.StringType,
+			},
+			"failure_options": schema.ListAttribute{
+				MarkdownDescription: "Failure reporting options (fo tag): `0`, `1`, `d`, or `s`. Requires `ruf`",
+				Optional:            true,
+				ElementType:         types.StringType,
+			},
+			"report_format": schema.StringAttribute{
+				MarkdownDescription: "Failure report format (rf tag), such as `afrf`",
+				Optional:            true,
+			},
+			"report_interval": schema.Int64Attribute{
+				MarkdownDescription: "Requested aggregate report interval in seconds (ri tag). Receivers assume `86400` (one day) when unset",
+				Optional:            true,
+			},
+			"record": schema.StringAttribute{
+				MarkdownDescription: "The assembled DMARC record, with tags in the order `v`, `p`, `sp`, `np`, `pct`, `adkim`, `aspf`, `rua`, `ruf`, `fo`, `rf`, `ri` " +
+					"(e.g., `v=DMARC1; p=reject; rua=mailto:dmarc@example.com`)",
+				Computed: true,
+			},
+		},
+	}
+}
+
+func (r *DMARCRecordResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	defer addDiagnosticCodes(&resp.Diagnostics)
+
+	var data DMARCRecordResourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	settings, known := data.settings(ctx, &resp.Diagnostics)
+	if !known || resp.Diagnostics.HasError() {
+		return
+	}
+	buildDMARCRecord(settings, &resp.Diagnostics)
+}
+
+// ModifyPlan fills in the record during planning, so that it shows in the
+// plan and can be used by other resources before apply.
+func (r *DMARCRecordResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	defer addDiagnosticCodes(&resp.Diagnostics)
+
+	// Nothing to do when the resource is being destroyed
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var data DMARCRecordResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// ValidateConfig has already reported any problem with the inputs
+	var diags diag.Diagnostics
+	if !data.build(ctx, &diags) {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.Plan.Set(ctx, &data)...)
+}
+
+func (r *DMARCRecordResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	defer addDiagnosticCodes(&resp.Diagnostics)
+
+	var data DMARCRecordResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !data.build(ctx, &resp.Diagnostics) {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// Read keeps the stored record, since it only depends on the configuration.
+func (r *DMARCRecordResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data DMARCRecordResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *DMARCRecordResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	defer addDiagnosticCodes(&resp.Diagnostics)
+
+	var data DMARCRecordResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !data.build(ctx, &resp.Diagnostics) {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// Delete only removes the record from state.
+func (r *DMARCRecordResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+}
+
+// settings converts the inputs of the model. It returns false when an input
+// is not yet known.
+func (m *DMARCRecordResourceModel) settings(ctx context.Context, diags *diag.Diagnostics) (dmarcRecordSettings, bool) {
+	for _, v := range []interface{ IsUnknown() bool }{
+		m.Policy, m.SubdomainPolicy, m.NonexistentPolicy, m.Percent, m.DKIMAlignment, m.SPFAlignment,
+		m.RUA, m.RUF, m.FailureOptions, m.ReportFormat, m.ReportInterval,
+	} {
+		if v.IsUnknown() {
+			return dmarcRecordSettings{}, false
+		}
+	}
+
+	settings := dmarcRecordSettings{
+		Policy:            m.Policy.ValueString(),
+		SubdomainPolicy:   m.SubdomainPolicy.ValueString(),
+		NonexistentPolicy: m.NonexistentPolicy.ValueString(),
+		Percent:           m.Percent.ValueInt64Pointer(),
+		DKIMAlignment:     m.DKIMAlignment.ValueString(),
+		SPFAlignment:      m.SPFAlignment.ValueString(),
+		ReportFormat:      m.ReportFormat.ValueString(),
+		ReportInterval:    m.ReportInterval.ValueInt64Pointer(),
+	}
+	for _, list := range []struct {
+		value  types.List
+		target *[]string
+	}{
+		{m.RUA, &settings.RUA},
+		{m.RUF, &settings.RUF},
+		{m.FailureOptions, &settings.FailureOptions},
+	} {
+		var values []types.String
+		diags.Append(list.value.ElementsAs(ctx, &values, false)...)
+		for _, v := range values {
+			if v.IsUnknown() {
+				return dmarcRecordSettings{}, false
+			}
+			*list.target = append(*list.target, v.ValueString())
+		}
+	}
+	return settings, true
+}
+
+// build assembles the record from the inputs of the model and sets the
+// computed attributes. It returns false when the record is invalid or an
+// input is not yet known.
+func (m *DMARCRecordResourceModel) build(ctx context.Context, diags *diag.Diagnostics) bool {
+	settings, known := m.settings(ctx, diags)
+	if !known || diags.HasError() {
+		return false
+	}
+
+	record := buildDMARCRecord(settings, diags)
+	if diags.HasError() {
+		return false
+	}
+
+	m.ID = types.StringValue(record)
+	m.Record = types.StringValue(record)
+	return true
+}
+
+// buildDMARCRecord assembles a DMARC record from settings, with its tags in
+// canonical order, and checks it with the validation of the emaildns_dmarc
+// data source. Invalid values are reported against the attribute holding
+// them.
+func buildDMARCRecord(s dmarcRecordSettings, diags *diag.Diagnostics) string {
+	tags := []string{"v=DMARC1"}
+	addTag := func(name, value string) {
+		if value != "" {
+			tags = append(tags, name+"="+value)
+		}
+	}
+
+	policies := []struct {
+		attribute, tag, value string
+	}{
+		{"policy", "p", s.Policy},
+		{"subdomain_policy", "sp", s.SubdomainPolicy},
+		{"nonexistent_subdomain_policy", "np", s.NonexistentPolicy},
+	}
+	for _, p := range policies {
+		if p.value == "" && p.tag != "p" {
+			continue
+		}
+		if _, err := parseDMARCPolicy(p.tag, p.value); err != nil {
+			diags.AddAttributeError(
+				path.Root(p.attribute),
+				summaryDMARCInvalidPolicy,
+				fmt.Sprintf("%s must be none, quarantine, or reject, got %q.", p.attribute, p.value),
+			)
+			continue
+		}
+		addTag(p.tag, p.value)
+	}
+
+	if s.Percent != nil {
+		if *s.Percent < 0 || *s.Percent > 100 {
+			diags.AddAttributeError(
+				path.Root("percent"),
+				summaryDMARCInvalidPercent,
+				fmt.Sprintf("percent must be from 0 to 100, got %d.", *s.Percent),
+			)
+		}
+		addTag("pct", strconv.FormatInt(*s.Percent, 10))
+	}
+
+	for _, a := range []struct{ attribute, tag, value string }{
+		{"dkim_alignment", "adkim", s.DKIMAlignment},
+		{"spf_alignment", "aspf", s.SPFAlignment},
+	} {
+		if a.value != "" && a.value != "r" && a.value != "s" {
+			diags.AddAttributeError(
+				path.Root(a.attribute),
+				summaryDMARCInvalidAlignment,
+				fmt.Sprintf("%s must be r (relaxed) or s (strict), got %q.", a.attribute, a.value),
+			)
+		}
+		addTag(a.tag, a.value)
+	}
+
+	for _, uris := range []struct {
+		attribute string
+		values    []string
+	}{
+		{"rua", s.RUA},
+		{"ruf", s.RUF},
+	} {
+		for i, uri := range uris.values {
+			_, err := parseReportURI(uri)
+			if err == nil && strings.ContainsAny(uri, ",;") {
+				err = fmt.Errorf("must not contain , or ;")
+			}
+			if err != nil {
+				diags.AddAttributeError(
+					path.Root(uris.attribute).AtListIndex(i),
+					summaryDMARCInvalidReportDestination,
+					fmt.Sprintf("The %s destination %q is invalid: %s", uris.attribute, uri, err.Error()),
+				)
+			}
+		}
+		addTag(uris.attribute, strings.Join(uris.values, ","))
+	}
+
+	if len(s.FailureOptions) > 0 {
+		fo := strings.Join(s.FailureOptions, ":")
+		if _, err := parseFailureOptions(fo); err != nil {
+			diags.AddAttributeError(
+				path.Root("failure_options"),
+				summaryDMARCInvalidFailureOptions,
+				fmt.Sprintf("failure_options may only contain 0, 1, d, and s, got %q.", s.FailureOptions),
+			)
+		}
+		if len(s.RUF) == 0 {
+			diags.AddAttributeError(
+				path.Root("failure_options"),
+				summaryDMARCFOWithoutRUF,
+				"failure_options only has an effect when failure reports are requested. Set ruf or remove failure_options.",
+			)
+		}
+		addTag("fo", fo)
+	}
+
+	if s.ReportFormat != "" {
+		if _, err := parseReportFormats(s.ReportFormat); err != nil {
+			diags.AddAttributeError(
+				path.Root("report_format"),
+				summaryDMARCInvalidReportFormat,
+				fmt.Sprintf("report_format is invalid: %s", err.Error()),
+			)
+		}
+		addTag("rf", s.ReportFormat)
+	}
+
+	if s.ReportInterval != nil {
+		if *s.ReportInterval <= 0 {
+			diags.AddAttributeError(
+				path.Root("report_interval"),
+				summaryDMARCInvalidReportInterval,
+				fmt.Sprintf("report_interval must be a positive number of seconds, got %d.", *s.ReportInterval),
+			)
+		}
+		addTag("ri", strconv.FormatInt(*s.ReportInterval, 10))
+	}
+
+	if diags.HasError() {
+		return ""
+	}
+
+	record := strings.Join(tags, "; ")
+	validateDMARCRecord(record, diags)
+	if _, err := parseDMARCRecord(record); err != nil && !diags.HasError() {
+		diags.AddError(
+			summaryDMARCInvalidRecord,
+			fmt.Sprintf("The assembled DMARC record is malformed: %s\n\nRecord: %s", err.Error(), record),
+		)
+	}
+	return record
+}
@@ -0,0 +1,121 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+)
+
+func TestBuildDMARCRecord(t *testing.T) {
+	int64Ptr := func(v int64) *int64 { return &v }
+
+	tests := []struct {
+		name     string
+		settings dmarcRecordSettings
+		want     string
+		wantErr  string
+		wantPath path.Path
+	}{
+		{
+			name:     "policy only",
+			settings: dmarcRecordSettings{Policy: "reject"},
+			want:     "v=DMARC1; p=reject",
+		},
+		{
+			name: "every tag",
+			settings: dmarcRecordSettings{
+				Policy: "quarantine", SubdomainPolicy: "reject", NonexistentPolicy: "reject", Percent: int64Ptr(50),
+				DKIMAlignment: "s", SPFAlignment: "r",
+				RUA: []string{"mailto:dmarc@example.com", "mailto:reports@example.com!10m"}, RUF: []string{"mailto:forensics@example.com"},
+				FailureOptions: []string{"1", "d"}, ReportFormat: "afrf", ReportInterval: int64Ptr(3600),
+			},
+			want: "v=DMARC1; p=quarantine; sp=reject; np=reject; pct=50; adkim=s; aspf=r; " +
+				"rua=mailto:dmarc@example.com,mailto:reports@example.com!10m; ruf=mailto:forensics@example.com; fo=1:d; rf=afrf; ri=3600",
+		},
+		{
+			name:     "missing policy",
+			settings: dmarcRecordSettings{},
+			wantErr:  "Invalid DMARC Policy",
+			wantPath: path.Root("policy"),
+		},
+		{
+			name:     "invalid subdomain policy",
+			settings: dmarcRecordSettings{Policy: "reject", SubdomainPolicy: "block"},
+			wantErr:  "Invalid DMARC Policy",
+			wantPath: path.Root("subdomain_policy"),
+		},
+		{
+			name:     "percent out of range",
+			settings: dmarcRecordSettings{Policy: "reject", Percent: int64Ptr(101)},
+			wantErr:  "Invalid DMARC Percentage",
+			wantPath: path.Root("percent"),
+		},
+		{
+			name:     "invalid alignment",
+			settings: dmarcRecordSettings{Policy: "reject", SPFAlignment: "strict"},
+			wantErr:  "Invalid DMARC Alignment Mode",
+			wantPath: path.Root("spf_alignment"),
+		},
+		{
+			name:     "invalid destination",
+			settings: dmarcRecordSettings{Policy: "reject", RUA: []string{"mailto:dmarc@example.com", "dmarc@example.com"}},
+			wantErr:  "Invalid DMARC Report Destination",
+			wantPath: path.Root("rua").AtListIndex(1),
+		},
+		{
+			name:     "destination with separator",
+			settings: dmarcRecordSettings{Policy: "reject", RUA: []string{"mailto:a@example.com,mailto:b@example.com"}},
+			wantErr:  "Invalid DMARC Report Destination",
+			wantPath: path.Root("rua").AtListIndex(0),
+		},
+		{
+			name:     "failure options without ruf",
+			settings: dmarcRecordSettings{Policy: "reject", FailureOptions: []string{"1"}},
+			wantErr:  "DMARC Failure Options Without ruf",
+			wantPath: path.Root("failure_options"),
+		},
+		{
+			name:     "invalid failure option",
+			settings: dmarcRecordSettings{Policy: "reject", RUF: []string{"mailto:f@example.com"}, FailureOptions: []string{"2"}},
+			wantErr:  "Invalid DMARC Failure Options",
+			wantPath: path.Root("failure_options"),
+		},
+		{
+			name:     "invalid report interval",
+			settings: dmarcRecordSettings{Policy: "reject", ReportInterval: int64Ptr(0)},
+			wantErr:  "Invalid DMARC Report Interval",
+			wantPath: path.Root("report_interval"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var diags diag.Diagnostics
+			got := buildDMARCRecord(tt.settings, &diags)
+
+			if tt.wantErr != "" {
+				if !diags.HasError() {
+					t.Fatalf("buildDMARCRecord() = %q, want error %q", got, tt.wantErr)
+				}
+				d := diags.Errors()[0]
+				if d.Summary() != tt.wantErr {
+					t.Errorf("error = %q, want %q", d.Summary(), tt.wantErr)
+				}
+				if withPath, ok := d.(diag.DiagnosticWithPath); !ok || !withPath.Path().Equal(tt.wantPath) {
+					t.Errorf("error %v is not reported against %s", d, tt.wantPath)
+				}
+				return
+			}
+			if diags.HasError() {
+				t.Fatalf("buildDMARCRecord() diagnostics = %v", diags)
+			}
+			if got != tt.want {
+				t.Errorf("buildDMARCRecord() = %q, want %q", got, tt.want)
+			}
+			if _, err := parseDMARCRecord(got); err != nil {
+				t.Errorf("parseDMARCRecord(%q) error = %v", got, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package provider
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// maxDomainNameLength is the RFC 1035 limit on the presentation form of a
+// domain name, excluding the trailing dot.
+const maxDomainNameLength = 253
+
+// checkHostname reports whether name is a valid host name per RFC 1123:
+// dot-separated labels of letters, digits, and hyphens that neither start nor
+// end with a hyphen. A single trailing dot marking the name as fully
+// qualified is allowed. IP literals are rejected, since records such as MX
+// and SRV must point at names.
+func checkHostname(name string) error {
+	if isIPLiteral(name) {
+		return fmt.Errorf("%q is an IP address, but a host name is required", name)
+	}
+
+	trimmed := strings.TrimSuffix(name, ".")
+	if trimmed == "" {
+		return fmt.Errorf("host name is empty")
+	}
+	if len(trimmed) > maxDomainNameLength {
+		return fmt.Errorf("%q is longer than %d characters", name, maxDomainNameLength)
+	}
+
+	labels := strings.Split(trimmed, ".")
+	for _, label := range labels {
+		if err := checkHostnameLabel(label); err != nil {
+			return fmt.Errorf("%q is not a valid host name: %w", name, err)
+		}
+	}
+	// RFC 3696 section 2: top-level domains are never all-numeric, which
+	// also catches addresses written with a trailing dot
+	if strings.Trim(labels[len(labels)-1], "0123456789") == "" {
+		return fmt.Errorf("%q is not a valid host name: top-level label is numeric", name)
+	}
+	return nil
+}
+
+// isWithinDomain reports whether host is domain or one of its subdomains.
+// Both are compared case-insensitively, ignoring a trailing dot.
+func isWithinDomain(host, domain string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	domain = strings.ToLower(strings.TrimSuffix(domain, "."))
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
+
+// organizationalDomain returns the registrable domain of host (e.g.,
+// example.co.uk for mail.example.co.uk), using the public suffix list as
+// DMARC does. Hosts without one, such as a bare public suffix, are returned
+// unchanged.
+func organizationalDomain(host string) string {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if org, err := publicsuffix.EffectiveTLDPlusOne(host); err == nil {
+		return org
+	}
+	return host
+}
+
+// checkHostnameLabel validates a single label of a host name.
+func checkHostnameLabel(label string) error {
+	switch {
+	case label == "":
+		return fmt.Errorf("empty label")
+	case len(label) > 63:
+		return fmt.Errorf("label %q is longer than 63 characters", label)
+	case label[0] == '-' || label[len(label)-1] == '-':
+		return fmt.Errorf("label %q starts or ends with a hyphen", label)
+	}
+	for _, r := range label {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-') {
+			return fmt.Errorf("label %q contains %q", label, r)
+		}
+	}
+	return nil
+}
+
+// isIPLiteral reports whether name is an IPv4 or IPv6 address, optionally in
+// the bracketed form used by address literals.
+func isIPLiteral(name string) bool {
+	_, err := netip.ParseAddr(strings.TrimSuffix(strings.TrimPrefix(name, "["), "]"))
+	return err == nil
+}
@@ -0,0 +1,76 @@
+package provider
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckHostname(t *testing.T) {
+	tests := []struct {
+		name    string
+		host    string
+		wantErr bool
+	}{
+		{name: "fqdn", host: "mx1.example.com."},
+		{name: "without trailing dot", host: "mx1.example.com"},
+		{name: "hyphen and digits", host: "mail-01.example.com"},
+		{name: "single label", host: "localhost"},
+		{name: "empty", host: "", wantErr: true},
+		{name: "root", host: ".", wantErr: true},
+		{name: "ipv4", host: "192.0.2.1", wantErr: true},
+		{name: "ipv4 with trailing dot", host: "192.0.2.1.", wantErr: true},
+		{name: "ipv6", host: "2001:db8::1", wantErr: true},
+		{name: "bracketed ipv6", host: "[2001:db8::1]", wantErr: true},
+		{name: "empty label", host: "mx..example.com", wantErr: true},
+		{name: "leading hyphen", host: "-mx.example.com", wantErr: true},
+		{name: "underscore", host: "mx_1.example.com", wantErr: true},
+		{name: "space", host: "mx 1.example.com", wantErr: true},
+		{name: "long label", host: strings.Repeat("a", 64) + ".example.com", wantErr: true},
+		{name: "long name", host: strings.Repeat("a.", 127) + "com", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkHostname(tt.host)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("checkHostname(%q) error = %v, wantErr %v", tt.host, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestIsWithinDomain(t *testing.T) {
+	tests := []struct {
+		host   string
+		domain string
+		want   bool
+	}{
+		{host: "example.com", domain: "example.com", want: true},
+		{host: "Images.Example.com.", domain: "example.com", want: true},
+		{host: "a.b.example.com", domain: "Example.COM.", want: true},
+		{host: "badexample.com", domain: "example.com", want: false},
+		{host: "example.com", domain: "mail.example.com", want: false},
+		{host: "cdn.example.net", domain: "example.com", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := isWithinDomain(tt.host, tt.domain); got != tt.want {
+			t.Errorf("isWithinDomain(%q, %q) = %v, want %v", tt.host, tt.domain, got, tt.want)
+		}
+	}
+}
+
+func TestOrganizationalDomain(t *testing.T) {
+	tests := map[string]string{
+		"mail.example.com":    "example.com",
+		"Example.com.":        "example.com",
+		"dmarc.example.co.uk": "example.co.uk",
+		"a.b.c.example.net":   "example.net",
+		"co.uk":               "co.uk",
+	}
+	for host, want := range tests {
+		if got := organizationalDomain(host); got != want {
+			t.Errorf("organizationalDomain(%q) = %q, want %q", host, got, want)
+		}
+	}
+}
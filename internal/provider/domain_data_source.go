@@ -0,0 +1,528 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/wttw/spf"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var (
+	_ datasource.DataSource                   = &DomainDataSource{}
+	_ datasource.DataSourceWithValidateConfig = &DomainDataSource{}
+	_ datasource.DataSourceWithConfigure      = &DomainDataSource{}
+)
+
+func NewDomainDataSource() datasource.DataSource {
+	return &DomainDataSource{}
+}
+
+// DomainDataSource defines the data source implementation. It validates the
+// SPF, DMARC, and DKIM records of a domain together by delegating to the
+// individual data sources.
+type DomainDataSource struct {
+	providerData *providerData
+}
+
+// DomainDataSourceModel describes the data source data model.
+type DomainDataSourceModel struct {
+	Domain        types.String `tfsdk:"domain"`
+	SPFRecord     types.String `tfsdk:"spf_record"`
+	DMARCRecord   types.String `tfsdk:"dmarc_record"`
+	DKIMSelectors types.Map    `tfsdk:"dkim_selectors"`
+	ChangeTicket  types.String `tfsdk:"change_ticket"`
+	SPF           types.Object `tfsdk:"spf"`
+	DMARC         types.Object `tfsdk:"dmarc"`
+	DKIM          types.Map    `tfsdk:"dkim"`
+	Valid         types.Bool   `tfsdk:"valid"`
+	Warnings      types.List   `tfsdk:"warnings"`
+}
+
+// domainSPFObjectType defines the Terraform object type for the parsed SPF
+// record of a domain.
+var domainSPFObjectType = types.ObjectType{
+	AttrTypes: map[string]attr.Type{
+		"dns_lookup_count": types.Int64Type,
+		"all_result":       types.StringType,
+		"redirect":         types.StringType,
+		"mechanisms":       types.ListType{ElemType: mechanismObjectType},
+	},
+}
+
+// domainDMARCObjectType defines the Terraform object type for the parsed
+// DMARC record of a domain.
+var domainDMARCObjectType = types.ObjectType{
+	AttrTypes: map[string]attr.Type{
+		"policy":                       types.StringType,
+		"subdomain_policy":             types.StringType,
+		"effective_subdomain_policy":   types.StringType,
+		"nonexistent_subdomain_policy": types.StringType,
+		"dkim_alignment":               types.StringType,
+		"spf_alignment":                types.StringType,
+		"subdomain_policy_strength":    types.StringType,
+		"percent":                      types.Int64Type,
+		"is_enforcing":                 types.BoolType,
+		"rua":                          types.ListType{ElemType: reportURIObjectType},
+		"ruf":                          types.ListType{ElemType: reportURIObjectType},
+		"reporting_domains":            types.ListType{ElemType: types.StringType},
+	},
+}
+
+// domainDKIMObjectType defines the Terraform object type for a parsed DKIM
+// record of a domain.
+var domainDKIMObjectType = types.ObjectType{
+	AttrTypes: map[string]attr.Type{
+		"key_type":        types.StringType,
+		"public_key":      types.StringType,
+		"key_format":      types.StringType,
+		"key_bits":        types.Int64Type,
+		"key_fingerprint": types.StringType,
+		"is_revoked":      types.BoolType,
+		"is_testing":      types.BoolType,
+		"hash_algorithms": types.ListType{ElemType: types.StringType},
+		"flags":           types.ListType{ElemType: types.StringType},
+	},
+}
+
+func (d *DomainDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_domain"
+}
+
+func (d *DomainDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Validates the SPF, DMARC, and DKIM records of a domain in one data source. " +
+			"Each record is checked exactly as by `emaildns_spf`, `emaildns_dmarc`, and `emaildns_dkim`, and terraform plan fails if any of them is invalid " +
+			"unless the provider sets `fail_on_error = false`.",
+
+		Attributes: map[string]schema.Attribute{
+			"domain": schema.StringAttribute{
+				MarkdownDescription: "The domain the records are published for (e.g., `example.com`). Passed to the DMARC checks to detect third-party report destinations, and compared with the DMARC reporting domains",
+				Optional:            true,
+			},
+			"spf_record": schema.StringAttribute{
+				MarkdownDescription: "The SPF TXT record content to validate",
+				Optional:            true,
+			},
+			"dmarc_record": schema.StringAttribute{
+				MarkdownDescription: "The DMARC TXT record content to validate",
+				Optional:            true,
+			},
+			"dkim_selectors": schema.MapAttribute{
+				MarkdownDescription: "DKIM TXT record content to validate, keyed by selector (e.g., `{ google = \"v=DKIM1; k=rsa; p=MIIB...\" }`)",
+				Optional:            true,
+				ElementType:         types.StringType,
+			},
+			"change_ticket": changeTicketAttribute(),
+			"valid":         validAttribute(),
+			"warnings":      warningsAttribute(),
+			"spf": schema.SingleNestedAttribute{
+				MarkdownDescription: "The parsed SPF record. Null when `spf_record` is not set",
+				Computed:            true,
+				Attributes: map[string]schema.Attribute{
+					"dns_lookup_count": schema.Int64Attribute{
+						MarkdownDescription: "Number of mechanisms that require DNS lookups (SPF allows max 10)",
+						Computed:            true,
+					},
+					"all_result": schema.StringAttribute{
+						MarkdownDescription: "The result of the `all` mechanism: `pass`, `fail`, `softfail`, or `neutral`. Null when the record has no `all` mechanism",
+						Computed:            true,
+					},
+					"redirect": schema.StringAttribute{
+						MarkdownDescription: "The redirect modifier value, if present",
+						Computed:            true,
+					},
+					"mechanisms": schema.ListNestedAttribute{
+						MarkdownDescription: "List of parsed SPF mechanisms, as returned by `emaildns_spf`",
+						Computed:            true,
+						NestedObject:        mechanismNestedObject(),
+					},
+				},
+			},
+			"dmarc": schema.SingleNestedAttribute{
+				MarkdownDescription: "The parsed DMARC record. Null when `dmarc_record` is not set",
+				Computed:            true,
+				Attributes: map[string]schema.Attribute{
+					"policy": schema.StringAttribute{
+						MarkdownDescription: "The policy value (none, quarantine, or reject)",
+						Computed:            true,
+					},
+					"subdomain_policy": schema.StringAttribute{
+						MarkdownDescription: "The subdomain policy value (sp tag)",
+						Computed:            true,
+					},
+					"effective_subdomain_policy": schema.StringAttribute{
+						MarkdownDescription: "The policy applied to subdomains: the sp tag when present, otherwise the inherited p tag",
+						Computed:            true,
+					},
+					"nonexistent_subdomain_policy": schema.StringAttribute{
+						MarkdownDescription: "The policy for subdomains that do not exist in DNS (np tag)",
+						Computed:            true,
+					},
+					"dkim_alignment": schema.StringAttribute{
+						MarkdownDescription: "The DKIM alignment mode (r for relaxed, s for strict)",
+						Computed:            true,
+					},
+					"spf_alignment": schema.StringAttribute{
+						MarkdownDescription: "The SPF alignment mode (r for relaxed, s for strict)",
+						Computed:            true,
+					},
+					"subdomain_policy_strength": schema.StringAttribute{
+						MarkdownDescription: "How the subdomain policy compares to the domain policy: `weaker`, `same`, or `stronger`",
+						Computed:            true,
+					},
+					"percent": schema.Int64Attribute{
+						MarkdownDescription: "The percentage of messages to which the policy applies (0-100)",
+						Computed:            true,
+					},
+					"is_enforcing": schema.BoolAttribute{
+						MarkdownDescription: "True if the policy is `quarantine` or `reject` and applies to all failing mail (`pct` absent or 100)",
+						Computed:            true,
+					},
+					"rua": schema.ListNestedAttribute{
+						MarkdownDescription: "Parsed aggregate report destinations, as returned by `emaildns_dmarc`",
+						Computed:            true,
+						NestedObject:        reportURINestedObject(),
+					},
+					"ruf": schema.ListNestedAttribute{
+						MarkdownDescription: "Parsed failure report destinations, as returned by `emaildns_dmarc`",
+						Computed:            true,
+						NestedObject:        reportURINestedObject(),
+					},
+					"reporting_domains": schema.ListAttribute{
+						MarkdownDescription: "The distinct domains that receive `rua` and `ruf` reports (e.g., `[\"example.com\", \"dmarc.example.net\"]`)",
+						Computed:            true,
+						ElementType:         types.StringType,
+					},
+				},
+			},
+			"dkim": schema.MapNestedAttribute{
+				MarkdownDescription: "The parsed DKIM records, keyed by selector",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"key_type": schema.StringAttribute{
+							MarkdownDescription: "The key algorithm type (rsa or ed25519)",
+							Computed:            true,
+						},
+						"public_key": schema.StringAttribute{
+							MarkdownDescription: "The base64-encoded public key",
+							Computed:            true,
+						},
+						"key_format": schema.StringAttribute{
+							MarkdownDescription: "Encoding of the published public key (`pkix`, `pkcs1`, or `raw`). Null when the key is revoked",
+							Computed:            true,
+						},
+						"key_bits": schema.Int64Attribute{
+							MarkdownDescription: "The key size in bits. Null when the key is revoked",
+							Computed:            true,
+						},
+						"key_fingerprint": schema.StringAttribute{
+							MarkdownDescription: "Hex-encoded SHA-256 hash of the public key as published. Null when the key is revoked",
+							Computed:            true,
+						},
+						"is_revoked": schema.BoolAttribute{
+							MarkdownDescription: "True if the key is revoked (empty p= tag)",
+							Computed:            true,
+						},
+						"is_testing": schema.BoolAttribute{
+							MarkdownDescription: "True if the `y` flag is set (t=y)",
+							Computed:            true,
+						},
+						"hash_algorithms": schema.ListAttribute{
+							MarkdownDescription: "List of acceptable hash algorithms (h tag)",
+							Computed:            true,
+							ElementType:         types.StringType,
+						},
+						"flags": schema.ListAttribute{
+							MarkdownDescription: "List of flags (t tag)",
+							Computed:            true,
+							ElementType:         types.StringType,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *DomainDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	d.providerData = configureProviderData(req, resp)
+}
+
+func (d *DomainDataSource) ValidateConfig(ctx context.Context, req datasource.ValidateConfigRequest, resp *datasource.ValidateConfigResponse) {
+	defer d.providerData.applyWarningSettings(&resp.Diagnostics)
+
+	var data DomainDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if data.SPFRecord.IsNull() && data.DMARCRecord.IsNull() && data.DKIMSelectors.IsNull() {
+		resp.Diagnostics.AddError(
+			summaryDomainNoRecords,
+			"At least one of spf_record, dmarc_record, or dkim_selectors must be set.",
+		)
+		return
+	}
+
+	var checkDiags diag.Diagnostics
+	validateDomainRecords(data, &checkDiags)
+	d.providerData.routeDiagnostics(checkDiags, &resp.Diagnostics)
+}
+
+func (d *DomainDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	defer d.providerData.applyWarningSettings(&resp.Diagnostics)
+
+	var data DomainDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data.ChangeTicket = d.providerData.checkChangeTicket(data.ChangeTicket, &resp.Diagnostics)
+
+	// Each record is only read when its own checks pass, so one invalid
+	// record does not hide the parsed values of the others
+	var checks []types.List
+	valid := true
+	check := func(validate, read func(*diag.Diagnostics)) {
+		recordValid, warnings := d.providerData.checkRecord(validate, read, &resp.Diagnostics)
+		valid = valid && recordValid.ValueBool()
+		checks = append(checks, warnings)
+	}
+
+	data.SPF = types.ObjectNull(domainSPFObjectType.AttrTypes)
+	if !data.SPFRecord.IsNull() {
+		check(
+			func(diags *diag.Diagnostics) {
+				validateDomainRecord(path.Root("spf_record"), data.SPFRecord, validateSPFRecord, diags)
+			},
+			func(diags *diag.Diagnostics) { data.SPF = d.readSPF(ctx, data.SPFRecord, diags) },
+		)
+	}
+
+	data.DMARC = types.ObjectNull(domainDMARCObjectType.AttrTypes)
+	if !data.DMARCRecord.IsNull() {
+		check(
+			func(diags *diag.Diagnostics) {
+				validateDomainRecord(path.Root("dmarc_record"), data.DMARCRecord, validateDMARCRecord, diags)
+			},
+			func(diags *diag.Diagnostics) { data.DMARC = d.readDMARC(ctx, data.Domain, data.DMARCRecord, diags) },
+		)
+	}
+
+	// Reports going to a domain unrelated to the sending infrastructure can
+	// mean a typo or a destination left behind after a provider change
+	if !data.DMARC.IsNull() {
+		check(
+			func(*diag.Diagnostics) {},
+			func(diags *diag.Diagnostics) { checkReportingDomains(data, diags) },
+		)
+	}
+
+	data.DKIM = types.MapNull(domainDKIMObjectType)
+	if !data.DKIMSelectors.IsNull() {
+		var selectors map[string]types.String
+		resp.Diagnostics.Append(data.DKIMSelectors.ElementsAs(ctx, &selectors, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		values := make(map[string]attr.Value, len(selectors))
+		for selector, record := range selectors {
+			values[selector] = types.ObjectNull(domainDKIMObjectType.AttrTypes)
+			check(
+				func(diags *diag.Diagnostics) {
+					validateDomainRecord(path.Root("dkim_selectors").AtMapKey(selector), record, validateDKIMRecord, diags)
+				},
+				func(diags *diag.Diagnostics) { values[selector] = d.readDKIM(ctx, selector, record, diags) },
+			)
+		}
+		dkim, diags := types.MapValue(domainDKIMObjectType, values)
+		resp.Diagnostics.Append(diags...)
+		data.DKIM = dkim
+	}
+
+	data.Valid = types.BoolValue(valid)
+	data.Warnings = concatWarnings(checks)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// checkReportingDomains warns about DMARC report destinations whose
+// organizational domain is neither that of the domain nor that of any domain
+// the SPF record refers to. Nothing is reported when neither is known.
+func checkReportingDomains(data DomainDataSourceModel, diags *diag.Diagnostics) {
+	var sending []string
+	if !data.Domain.IsNull() {
+		sending = append(sending, data.Domain.ValueString())
+	}
+	if !data.SPF.IsNull() {
+		if parsed, err := spf.ParseSPF(data.SPFRecord.ValueString()); err == nil {
+			sending = append(sending, spfTargetDomains(parsed)...)
+		}
+	}
+	if len(sending) == 0 {
+		return
+	}
+
+	related := make(map[string]bool, len(sending))
+	for _, domain := range sending {
+		related[organizationalDomain(domain)] = true
+	}
+	for _, domain := range dmarcReportingDomains(data.DMARCRecord.ValueString()) {
+		if related[organizationalDomain(domain)] {
+			continue
+		}
+		diags.AddAttributeWarning(
+			path.Root("dmarc_record"),
+			summaryDMARCUnrelatedReportingDomain,
+			fmt.Sprintf("DMARC reports are sent to %s, which is unrelated to %s. "+
+				"Whoever controls that domain receives data about your mail, so check that it is not a typo or a destination left behind "+
+				"after changing providers. Ignore this warning if it is your DMARC reporting service.",
+				domain, strings.Join(sending, ", ")),
+		)
+	}
+}
+
+// validateDomainRecords runs the static checks of every known record in data,
+// attaching the diagnostics to the attribute that holds each record.
+func validateDomainRecords(data DomainDataSourceModel, diags *diag.Diagnostics) {
+	validateDomainRecord(path.Root("spf_record"), data.SPFRecord, validateSPFRecord, diags)
+	validateDomainRecord(path.Root("dmarc_record"), data.DMARCRecord, validateDMARCRecord, diags)
+
+	if data.DKIMSelectors.IsNull() || data.DKIMSelectors.IsUnknown() {
+		return
+	}
+	for selector, value := range data.DKIMSelectors.Elements() {
+		if record, ok := value.(types.String); ok {
+			validateDomainRecord(path.Root("dkim_selectors").AtMapKey(selector), record, validateDKIMRecord, diags)
+		}
+	}
+}
+
+// validateDomainRecord runs validate on record, attaching the diagnostics to
+// p. Records that are null or unknown (e.g., depend on another resource) are
+// skipped.
+func validateDomainRecord(p path.Path, record types.String, validate func(string, *diag.Diagnostics), diags *diag.Diagnostics) {
+	if record.IsNull() || record.IsUnknown() {
+		return
+	}
+	var recordDiags diag.Diagnostics
+	validate(record.ValueString(), &recordDiags)
+	appendWithPath(diags, p, recordDiags)
+}
+
+// concatWarnings joins the warnings attribute values of several record
+// checks.
+func concatWarnings(lists []types.List) types.List {
+	warnings := []attr.Value{}
+	for _, list := range lists {
+		warnings = append(warnings, list.Elements()...)
+	}
+	return types.ListValueMust(types.StringType, warnings)
+}
+
+// readSPF parses the SPF record with the emaildns_spf logic and returns the
+// nested spf object.
+func (d *DomainDataSource) readSPF(ctx context.Context, record types.String, diags *diag.Diagnostics) types.Object {
+	sub := SPFDataSourceModel{Record: record}
+	var subDiags diag.Diagnostics
+	(&SPFDataSource{providerData: d.providerData}).read(ctx, &sub, &subDiags)
+	appendWithPath(diags, path.Root("spf_record"), subDiags)
+	if subDiags.HasError() {
+		return types.ObjectNull(domainSPFObjectType.AttrTypes)
+	}
+
+	allResult := types.StringNull()
+	if parsed, err := spf.ParseSPF(record.ValueString()); err == nil {
+		for _, m := range parsed.Mechanisms {
+			if all, ok := m.(spf.MechanismAll); ok {
+				allResult = types.StringValue(all.Qualifier.String())
+			}
+		}
+	}
+
+	obj, objDiags := types.ObjectValue(domainSPFObjectType.AttrTypes, map[string]attr.Value{
+		"dns_lookup_count": sub.DNSLookupCount,
+		"all_result":       allResult,
+		"redirect":         sub.Redirect,
+		"mechanisms":       sub.Mechanisms,
+	})
+	diags.Append(objDiags...)
+	return obj
+}
+
+// readDMARC parses the DMARC record with the emaildns_dmarc logic and returns
+// the nested dmarc object.
+func (d *DomainDataSource) readDMARC(ctx context.Context, domain, record types.String, diags *diag.Diagnostics) types.Object {
+	sub := DMARCDataSourceModel{Record: record, Domain: domain}
+	var subDiags diag.Diagnostics
+	(&DMARCDataSource{providerData: d.providerData}).read(ctx, &sub, &subDiags)
+	appendWithPath(diags, path.Root("dmarc_record"), subDiags)
+	if subDiags.HasError() {
+		return types.ObjectNull(domainDMARCObjectType.AttrTypes)
+	}
+
+	obj, objDiags := types.ObjectValue(domainDMARCObjectType.AttrTypes, map[string]attr.Value{
+		"policy":                       sub.Policy,
+		"subdomain_policy":             sub.SubdomainPolicy,
+		"effective_subdomain_policy":   sub.EffectiveSubdomain,
+		"nonexistent_subdomain_policy": sub.NonexistentPolicy,
+		"dkim_alignment":               sub.DKIMAlignment,
+		"spf_alignment":                sub.SPFAlignment,
+		"subdomain_policy_strength":    sub.SubdomainStrength,
+		"percent":                      sub.Percent,
+		"is_enforcing":                 sub.IsEnforcing,
+		"rua":                          sub.RUA,
+		"ruf":                          sub.RUF,
+		"reporting_domains":            convertStringSliceToList(ctx, dmarcReportingDomains(record.ValueString()), diags),
+	})
+	diags.Append(objDiags...)
+	return obj
+}
+
+// readDKIM parses a DKIM record with the emaildns_dkim logic and returns its
+// entry in the nested dkim map.
+func (d *DomainDataSource) readDKIM(ctx context.Context, selector string, record types.String, diags *diag.Diagnostics) types.Object {
+	sub := DKIMDataSourceModel{Record: record}
+	var subDiags diag.Diagnostics
+	(&DKIMDataSource{providerData: d.providerData}).read(ctx, &sub, &subDiags)
+	appendWithPath(diags, path.Root("dkim_selectors").AtMapKey(selector), subDiags)
+	if subDiags.HasError() {
+		return types.ObjectNull(domainDKIMObjectType.AttrTypes)
+	}
+
+	obj, objDiags := types.ObjectValue(domainDKIMObjectType.AttrTypes, map[string]attr.Value{
+		"key_type":        sub.KeyType,
+		"public_key":      sub.PublicKey,
+		"key_format":      sub.KeyFormat,
+		"key_bits":        sub.KeyBits,
+		"key_fingerprint": sub.KeyFingerprint,
+		"is_revoked":      sub.IsRevoked,
+		"is_testing":      sub.IsTesting,
+		"hash_algorithms": sub.HashAlgorithms,
+		"flags":           sub.Flags,
+	})
+	diags.Append(objDiags...)
+	return obj
+}
+
+// appendWithPath adds the diagnostics of a component record, attaching them
+// to the attribute that holds the record.
+func appendWithPath(diags *diag.Diagnostics, p path.Path, from diag.Diagnostics) {
+	for _, d := range from {
+		diags.Append(diag.WithPath(p, d))
+	}
+}
@@ -0,0 +1,168 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+func TestDomainDataSourceComponents(t *testing.T) {
+	ctx := context.Background()
+	d := &DomainDataSource{}
+
+	t.Run("spf", func(t *testing.T) {
+		var diags diag.Diagnostics
+		obj := d.readSPF(ctx, types.StringValue("v=spf1 include:_spf.google.com mx ~all"), &diags)
+		if diags.HasError() {
+			t.Fatalf("readSPF() diagnostics = %v", diags)
+		}
+		attrs := obj.Attributes()
+		if got := attrs["dns_lookup_count"].(types.Int64).ValueInt64(); got != 2 {
+			t.Errorf("dns_lookup_count = %d, want 2", got)
+		}
+		if got := attrs["all_result"].(types.String).ValueString(); got != "softfail" {
+			t.Errorf("all_result = %q, want %q", got, "softfail")
+		}
+	})
+
+	t.Run("dmarc", func(t *testing.T) {
+		var diags diag.Diagnostics
+		obj := d.readDMARC(ctx, types.StringNull(), types.StringValue("v=DMARC1; p=reject; rua=mailto:dmarc@example.com!10m"), &diags)
+		if diags.HasError() {
+			t.Fatalf("readDMARC() diagnostics = %v", diags)
+		}
+		attrs := obj.Attributes()
+		if got := attrs["effective_subdomain_policy"].(types.String).ValueString(); got != "reject" {
+			t.Errorf("effective_subdomain_policy = %q, want %q", got, "reject")
+		}
+		if got := len(attrs["rua"].(types.List).Elements()); got != 1 {
+			t.Errorf("len(rua) = %d, want 1", got)
+		}
+		if !attrs["is_enforcing"].(types.Bool).ValueBool() {
+			t.Error("is_enforcing = false, want true")
+		}
+		if got := attrs["subdomain_policy_strength"].(types.String).ValueString(); got != "same" {
+			t.Errorf("subdomain_policy_strength = %q, want %q", got, "same")
+		}
+	})
+
+	t.Run("dkim", func(t *testing.T) {
+		var diags diag.Diagnostics
+		obj := d.readDKIM(ctx, "ed", types.StringValue(testDKIMEd25519), &diags)
+		if diags.HasError() {
+			t.Fatalf("readDKIM() diagnostics = %v", diags)
+		}
+		attrs := obj.Attributes()
+		if got := attrs["key_bits"].(types.Int64).ValueInt64(); got != 256 {
+			t.Errorf("key_bits = %d, want 256", got)
+		}
+		if got := attrs["key_format"].(types.String).ValueString(); got != "raw" {
+			t.Errorf("key_format = %q, want %q", got, "raw")
+		}
+		if attrs["key_fingerprint"].(types.String).ValueString() == "" {
+			t.Error("key_fingerprint is empty")
+		}
+	})
+
+	t.Run("invalid dkim", func(t *testing.T) {
+		var diags diag.Diagnostics
+		obj := d.readDKIM(ctx, "google", types.StringValue("v=DKIM1; k=dsa; p=abc"), &diags)
+		if !diags.HasError() {
+			t.Fatal("readDKIM() expected an error")
+		}
+		if !obj.IsNull() {
+			t.Errorf("readDKIM() = %v, want null", obj)
+		}
+		for _, d := range diags {
+			if _, ok := d.(diag.DiagnosticWithPath); !ok {
+				t.Errorf("diagnostic %q has no attribute path", d.Summary())
+			}
+		}
+	})
+}
+
+func TestDomainDataSourceReportOnly(t *testing.T) {
+	ctx := context.Background()
+	d := &DomainDataSource{providerData: &providerData{failOnError: false}}
+	config := testDataSourceConfig(t, d, map[string]tftypes.Value{
+		"spf_record":   tftypes.NewValue(tftypes.String, "v=spf1 bogus"),
+		"dmarc_record": tftypes.NewValue(tftypes.String, "v=DMARC1; p=reject"),
+	})
+
+	req := datasource.ReadRequest{Config: config}
+	resp := datasource.ReadResponse{State: tfsdk.State{Schema: config.Schema}}
+	d.Read(ctx, req, &resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("Read() diagnostics = %v", resp.Diagnostics)
+	}
+
+	var data DomainDataSourceModel
+	if diags := resp.State.Get(ctx, &data); diags.HasError() {
+		t.Fatalf("State.Get() diagnostics = %v", diags)
+	}
+	if data.Valid.ValueBool() || len(data.Warnings.Elements()) == 0 {
+		t.Errorf("valid = %s, warnings = %s, want the SPF error reported", data.Valid, data.Warnings)
+	}
+	// The invalid SPF record does not hide the valid DMARC record
+	if !data.SPF.IsNull() || data.DMARC.IsNull() {
+		t.Errorf("spf = %s, dmarc = %s, want only dmarc set", data.SPF, data.DMARC)
+	}
+}
+
+func TestCheckReportingDomains(t *testing.T) {
+	spfObject := types.ObjectValueMust(domainSPFObjectType.AttrTypes, map[string]attr.Value{
+		"dns_lookup_count": types.Int64Value(1),
+		"all_result":       types.StringValue("fail"),
+		"redirect":         types.StringNull(),
+		"mechanisms":       types.ListNull(mechanismObjectType),
+	})
+
+	tests := []struct {
+		name  string
+		data  DomainDataSourceModel
+		wantN int
+	}{
+		{
+			name: "reports to the domain",
+			data: DomainDataSourceModel{Domain: types.StringValue("example.com"), SPF: types.ObjectNull(domainSPFObjectType.AttrTypes),
+				DMARCRecord: types.StringValue("v=DMARC1; p=reject; rua=mailto:dmarc@reports.example.com")},
+		},
+		{
+			name: "reports to the sending provider",
+			data: DomainDataSourceModel{Domain: types.StringNull(), SPF: spfObject, SPFRecord: types.StringValue("v=spf1 include:_spf.google.com -all"),
+				DMARCRecord: types.StringValue("v=DMARC1; p=reject; rua=mailto:dmarc@reports.google.com")},
+		},
+		{
+			name: "unrelated destinations",
+			data: DomainDataSourceModel{Domain: types.StringValue("example.com"), SPF: spfObject, SPFRecord: types.StringValue("v=spf1 include:_spf.google.com -all"),
+				DMARCRecord: types.StringValue("v=DMARC1; p=reject; rua=mailto:dmarc@example.com,mailto:x@examp1e.com; ruf=https://collector.example.net/r")},
+			wantN: 2,
+		},
+		{
+			name: "nothing to compare against",
+			data: DomainDataSourceModel{Domain: types.StringNull(), SPF: types.ObjectNull(domainSPFObjectType.AttrTypes),
+				DMARCRecord: types.StringValue("v=DMARC1; p=reject; rua=mailto:dmarc@example.net")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var diags diag.Diagnostics
+			checkReportingDomains(tt.data, &diags)
+			if len(diags) != tt.wantN {
+				t.Fatalf("checkReportingDomains() diagnostics = %v, want %d warnings", diags, tt.wantN)
+			}
+			for _, d := range diags {
+				if d.Severity() != diag.SeverityWarning || d.Summary() != "Unrelated DMARC Reporting Domain" {
+					t.Errorf("diagnostic = %s %q", d.Severity(), d.Summary())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// minRecommendedMTASTSMaxAge is the policy lifetime below which a warning is
+// produced. RFC 8461 section 3.2 recommends a max_age of weeks or more.
+const minRecommendedMTASTSMaxAge = 86400
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var (
+	_ datasource.DataSource                   = &MTASTSDataSource{}
+	_ datasource.DataSourceWithValidateConfig = &MTASTSDataSource{}
+	_ datasource.DataSourceWithConfigure      = &MTASTSDataSource{}
+)
+
+func NewMTASTSDataSource() datasource.DataSource {
+	return &MTASTSDataSource{}
+}
+
+// MTASTSDataSource defines the data source implementation.
+type MTASTSDataSource struct {
+	providerData *providerData
+}
+
+// MTASTSDataSourceModel describes the data source data model.
+type MTASTSDataSourceModel struct {
+	Record       types.String `tfsdk:"record"`
+	Policy       types.String `tfsdk:"policy"`
+	ChangeTicket types.String `tfsdk:"change_ticket"`
+	ID           types.String `tfsdk:"id"`
+	Mode         types.String `tfsdk:"mode"`
+	MaxAge       types.Int64  `tfsdk:"max_age"`
+	MX           types.List   `tfsdk:"mx"`
+}
+
+func (d *MTASTSDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_mta_sts"
+}
+
+func (d *MTASTSDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Validates an MTA-STS (RFC 8461) `_mta-sts` TXT record and, optionally, the policy file it announces. " +
+			"If either is invalid, terraform plan will fail with a specific error message.",
+
+		Attributes: map[string]schema.Attribute{
+			"record": schema.StringAttribute{
+				MarkdownDescription: "The TXT record content published at `_mta-sts.<domain>` (e.g., `v=STSv1; id=20240101T000000`)",
+				Required:            true,
+			},
+			"policy": schema.StringAttribute{
+				MarkdownDescription: "The policy file content served at `https://mta-sts.<domain>/.well-known/mta-sts.txt`",
+				Optional:            true,
+			},
+			"change_ticket": changeTicketAttribute(),
+			"id": schema.StringAttribute{
+				MarkdownDescription: "The policy id from the TXT record. Change it whenever the policy changes",
+				Computed:            true,
+			},
+			"mode": schema.StringAttribute{
+				MarkdownDescription: "The policy mode: `enforce`, `testing`, or `none`. Null when `policy` is not set",
+				Computed:            true,
+			},
+			"max_age": schema.Int64Attribute{
+				MarkdownDescription: "How long senders may cache the policy, in seconds. Null when `policy` is not set",
+				Computed:            true,
+			},
+			"mx": schema.ListAttribute{
+				MarkdownDescription: "The mx patterns of the policy (e.g., `mail.example.com` or `*.example.net`). Null when `policy` is not set",
+				Computed:            true,
+				ElementType:         types.StringType,
+			},
+		},
+	}
+}
+
+func (d *MTASTSDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	d.providerData = configureProviderData(req, resp)
+}
+
+func (d *MTASTSDataSource) ValidateConfig(ctx context.Context, req datasource.ValidateConfigRequest, resp *datasource.ValidateConfigResponse) {
+	defer d.providerData.applyWarningSettings(&resp.Diagnostics)
+
+	var data MTASTSDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Values that are unknown (e.g., depend on another resource) are skipped
+	if !data.Record.IsUnknown() && !data.Record.IsNull() {
+		if _, err := parseMTASTSRecord(data.Record.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("record"),
+				summaryMTASTSInvalidRecord,
+				fmt.Sprintf("The MTA-STS record is malformed: %s\n\nRecord: %s", err.Error(), data.Record.ValueString()),
+			)
+		}
+	}
+
+	if !data.Policy.IsUnknown() && !data.Policy.IsNull() {
+		if _, err := parseMTASTSPolicy(data.Policy.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("policy"),
+				summaryMTASTSInvalidPolicy,
+				fmt.Sprintf("The MTA-STS policy is malformed: %s", err.Error()),
+			)
+		}
+	}
+}
+
+func (d *MTASTSDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	defer d.providerData.applyWarningSettings(&resp.Diagnostics)
+
+	var data MTASTSDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data.ChangeTicket = d.providerData.checkChangeTicket(data.ChangeTicket, &resp.Diagnostics)
+
+	rec, err := parseMTASTSRecord(data.Record.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			summaryMTASTSInvalidRecord,
+			fmt.Sprintf("The MTA-STS record is malformed: %s", err.Error()),
+		)
+		return
+	}
+	data.ID = types.StringValue(rec.ID)
+
+	data.Mode = types.StringNull()
+	data.MaxAge = types.Int64Null()
+	data.MX = types.ListNull(types.StringType)
+	if !data.Policy.IsNull() {
+		policy, err := parseMTASTSPolicy(data.Policy.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddError(
+				summaryMTASTSInvalidPolicy,
+				fmt.Sprintf("The MTA-STS policy is malformed: %s", err.Error()),
+			)
+			return
+		}
+
+		if policy.Mode != "none" && policy.MaxAge < minRecommendedMTASTSMaxAge {
+			resp.Diagnostics.AddWarning(
+				summaryMTASTSShortMaxAge,
+				fmt.Sprintf("The MTA-STS policy sets max_age to %d seconds, so senders forget it quickly and are exposed to downgrade attacks between fetches. "+
+					"RFC 8461 recommends a max_age of weeks or more, such as 604800 (one week).", policy.MaxAge),
+			)
+		}
+
+		data.Mode = types.StringValue(policy.Mode)
+		data.MaxAge = types.Int64Value(policy.MaxAge)
+		data.MX = convertStringSliceToList(ctx, policy.MX, &resp.Diagnostics)
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
@@ -0,0 +1,158 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// maxMTASTSMaxAge is the RFC 8461 section 3.2 upper bound on max_age, about
+// one year in seconds.
+const maxMTASTSMaxAge = 31557600
+
+var (
+	// mtaSTSIDRe matches the id field of the TXT record: 1 to 32
+	// alphanumeric characters (RFC 8461 section 3.1).
+	mtaSTSIDRe = regexp.MustCompile(`^[A-Za-z0-9]{1,32}$`)
+
+	// mtaSTSExtNameRe matches the names of extension fields.
+	mtaSTSExtNameRe = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]{0,31}$`)
+
+	// mtaSTSMaxAgeRe matches max_age values, which are at most 10 digits.
+	mtaSTSMaxAgeRe = regexp.MustCompile(`^[0-9]{1,10}$`)
+)
+
+// mtaSTSRecord holds the parsed _mta-sts TXT record.
+type mtaSTSRecord struct {
+	ID string
+}
+
+// mtaSTSPolicy holds the parsed MTA-STS policy file.
+type mtaSTSPolicy struct {
+	Version string
+	Mode    string
+	MaxAge  int64
+	MX      []string
+}
+
+// parseMTASTSRecord parses the TXT record published at _mta-sts.<domain>, as
+// described in RFC 8461 section 3.1.
+func parseMTASTSRecord(record string) (*mtaSTSRecord, error) {
+	fields := strings.Split(record, ";")
+	// A trailing separator is allowed
+	if strings.TrimSpace(fields[len(fields)-1]) == "" {
+		fields = fields[:len(fields)-1]
+	}
+
+	if len(fields) == 0 || strings.TrimSpace(fields[0]) != "v=STSv1" {
+		return nil, fmt.Errorf("record must begin with v=STSv1")
+	}
+
+	rec := &mtaSTSRecord{}
+	seen := make(map[string]bool)
+	for _, field := range fields[1:] {
+		name, value, found := strings.Cut(strings.TrimSpace(field), "=")
+		if !found {
+			return nil, fmt.Errorf("invalid field %q (missing '=')", strings.TrimSpace(field))
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("duplicate %s field", name)
+		}
+		seen[name] = true
+
+		switch {
+		case name == "id":
+			if !mtaSTSIDRe.MatchString(value) {
+				return nil, fmt.Errorf("id must be 1 to 32 letters and digits, got %q", value)
+			}
+			rec.ID = value
+		case name == "v":
+			return nil, fmt.Errorf("v must only appear once, as the first field")
+		case !mtaSTSExtNameRe.MatchString(name):
+			return nil, fmt.Errorf("invalid field name %q", name)
+		}
+	}
+
+	if rec.ID == "" {
+		return nil, fmt.Errorf("missing required id field")
+	}
+	return rec, nil
+}
+
+// parseMTASTSPolicy parses the policy file served at
+// https://mta-sts.<domain>/.well-known/mta-sts.txt, as described in RFC 8461
+// section 3.2. Unknown keys are ignored, as the RFC requires.
+func parseMTASTSPolicy(body string) (*mtaSTSPolicy, error) {
+	policy := &mtaSTSPolicy{}
+	seen := make(map[string]bool)
+
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			return nil, fmt.Errorf("invalid line %q (missing ':')", line)
+		}
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+
+		if key != "mx" && seen[key] {
+			return nil, fmt.Errorf("duplicate %s key", key)
+		}
+		seen[key] = true
+
+		switch key {
+		case "version":
+			if value != "STSv1" {
+				return nil, fmt.Errorf("version must be STSv1, got %q", value)
+			}
+			policy.Version = value
+		case "mode":
+			switch value {
+			case "enforce", "testing", "none":
+			default:
+				return nil, fmt.Errorf("mode must be enforce, testing, or none, got %q", value)
+			}
+			policy.Mode = value
+		case "max_age":
+			if !mtaSTSMaxAgeRe.MatchString(value) {
+				return nil, fmt.Errorf("max_age must be a number of seconds, got %q", value)
+			}
+			maxAge, _ := strconv.ParseInt(value, 10, 64)
+			if maxAge > maxMTASTSMaxAge {
+				return nil, fmt.Errorf("max_age must be at most %d seconds, got %d", maxMTASTSMaxAge, maxAge)
+			}
+			policy.MaxAge = maxAge
+		case "mx":
+			if err := checkMTASTSMXPattern(value); err != nil {
+				return nil, err
+			}
+			policy.MX = append(policy.MX, value)
+		}
+	}
+
+	switch {
+	case policy.Version == "":
+		return nil, fmt.Errorf("missing required version key")
+	case policy.Mode == "":
+		return nil, fmt.Errorf("missing required mode key")
+	case !seen["max_age"]:
+		return nil, fmt.Errorf("missing required max_age key")
+	case len(policy.MX) == 0 && policy.Mode != "none":
+		return nil, fmt.Errorf("at least one mx key is required unless mode is none")
+	}
+	return policy, nil
+}
+
+// checkMTASTSMXPattern validates an mx pattern: a host name, or a wildcard
+// matching a single leftmost label such as *.mail.example.com.
+func checkMTASTSMXPattern(pattern string) error {
+	if err := checkHostname(strings.TrimPrefix(pattern, "*.")); err != nil {
+		return fmt.Errorf("invalid mx pattern: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseMTASTSRecord(t *testing.T) {
+	tests := []struct {
+		name    string
+		record  string
+		wantID  string
+		wantErr bool
+	}{
+		{name: "valid", record: "v=STSv1; id=20240101T000000", wantID: "20240101T000000"},
+		{name: "trailing separator", record: "v=STSv1; id=abc123;", wantID: "abc123"},
+		{name: "no spaces", record: "v=STSv1;id=abc123", wantID: "abc123"},
+		{name: "extension field", record: "v=STSv1; id=abc123; ext_1=value", wantID: "abc123"},
+		{name: "missing id", record: "v=STSv1;", wantErr: true},
+		{name: "wrong version", record: "v=STSv2; id=abc123", wantErr: true},
+		{name: "version not first", record: "id=abc123; v=STSv1", wantErr: true},
+		{name: "id too long", record: "v=STSv1; id=" + "a123456789012345678901234567890123", wantErr: true},
+		{name: "id with punctuation", record: "v=STSv1; id=2024-01-01", wantErr: true},
+		{name: "duplicate id", record: "v=STSv1; id=abc; id=def", wantErr: true},
+		{name: "field without value", record: "v=STSv1; id=abc; bogus", wantErr: true},
+		{name: "empty", record: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec, err := parseMTASTSRecord(tt.record)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseMTASTSRecord() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && rec.ID != tt.wantID {
+				t.Errorf("parseMTASTSRecord() ID = %q, want %q", rec.ID, tt.wantID)
+			}
+		})
+	}
+}
+
+func TestParseMTASTSPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  string
+		want    *mtaSTSPolicy
+		wantErr bool
+	}{
+		{
+			name:   "enforce",
+			policy: "version: STSv1\r\nmode: enforce\r\nmx: mail.example.com\r\nmx: *.example.net\r\nmax_age: 604800\r\n",
+			want:   &mtaSTSPolicy{Version: "STSv1", Mode: "enforce", MaxAge: 604800, MX: []string{"mail.example.com", "*.example.net"}},
+		},
+		{
+			name:   "lf line endings and unknown key",
+			policy: "version: STSv1\nmode: testing\nmx: mail.example.com\nmax_age: 86400\nfoo: bar\n",
+			want:   &mtaSTSPolicy{Version: "STSv1", Mode: "testing", MaxAge: 86400, MX: []string{"mail.example.com"}},
+		},
+		{
+			name:   "none without mx",
+			policy: "version: STSv1\nmode: none\nmax_age: 0\n",
+			want:   &mtaSTSPolicy{Version: "STSv1", Mode: "none", MaxAge: 0},
+		},
+		{name: "missing version", policy: "mode: enforce\nmx: mail.example.com\nmax_age: 86400\n", wantErr: true},
+		{name: "invalid mode", policy: "version: STSv1\nmode: strict\nmx: mail.example.com\nmax_age: 86400\n", wantErr: true},
+		{name: "missing max_age", policy: "version: STSv1\nmode: enforce\nmx: mail.example.com\n", wantErr: true},
+		{name: "max_age too large", policy: "version: STSv1\nmode: enforce\nmx: mail.example.com\nmax_age: 31557601\n", wantErr: true},
+		{name: "max_age not a number", policy: "version: STSv1\nmode: enforce\nmx: mail.example.com\nmax_age: 1d\n", wantErr: true},
+		{name: "enforce without mx", policy: "version: STSv1\nmode: enforce\nmax_age: 86400\n", wantErr: true},
+		{name: "ip mx", policy: "version: STSv1\nmode: enforce\nmx: 192.0.2.25\nmax_age: 86400\n", wantErr: true},
+		{name: "nested wildcard", policy: "version: STSv1\nmode: enforce\nmx: *.*.example.com\nmax_age: 86400\n", wantErr: true},
+		{name: "duplicate mode", policy: "version: STSv1\nmode: enforce\nmode: none\nmx: mail.example.com\nmax_age: 86400\n", wantErr: true},
+		{name: "line without colon", policy: "version: STSv1\nmode enforce\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseMTASTSPolicy(tt.policy)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseMTASTSPolicy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseMTASTSPolicy() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,328 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var (
+	_ datasource.DataSource                   = &MXDataSource{}
+	_ datasource.DataSourceWithValidateConfig = &MXDataSource{}
+	_ datasource.DataSourceWithConfigure      = &MXDataSource{}
+)
+
+func NewMXDataSource() datasource.DataSource {
+	return &MXDataSource{}
+}
+
+// MXDataSource defines the data source implementation.
+type MXDataSource struct {
+	providerData *providerData
+}
+
+// MXDataSourceModel describes the data source data model.
+type MXDataSourceModel struct {
+	Domain       types.String `tfsdk:"domain"`
+	Records      types.List   `tfsdk:"records"`
+	ExpectNullMX types.Bool   `tfsdk:"expect_null_mx"`
+	ChangeTicket types.String `tfsdk:"change_ticket"`
+	Hosts        types.List   `tfsdk:"hosts"`
+	IsNullMX     types.Bool   `tfsdk:"is_null_mx"`
+}
+
+// mxRecordModel describes one entry of the records attribute.
+type mxRecordModel struct {
+	Preference types.Int64  `tfsdk:"preference"`
+	Host       types.String `tfsdk:"host"`
+}
+
+// mxRecordObjectType defines the Terraform object type for an MX record.
+var mxRecordObjectType = types.ObjectType{
+	AttrTypes: map[string]attr.Type{
+		"preference": types.Int64Type,
+		"host":       types.StringType,
+	},
+}
+
+// mxRecord is a single MX record. Preference is an int64 so that values
+// outside the 16-bit range can be reported rather than silently truncated.
+type mxRecord struct {
+	Preference int64
+	Host       string
+}
+
+func (d *MXDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_mx"
+}
+
+func (d *MXDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Validates the MX records of a domain, either given directly or looked up in live DNS. " +
+			"If the records are invalid, terraform plan will fail with a specific error message.",
+
+		Attributes: map[string]schema.Attribute{
+			"domain": schema.StringAttribute{
+				MarkdownDescription: "A domain whose published MX records are looked up and validated (e.g., `example.com`). Conflicts with `records`",
+				Optional:            true,
+			},
+			"records": schema.ListNestedAttribute{
+				MarkdownDescription: "The MX records to validate. When `domain` is set instead, holds the records found in DNS",
+				Optional:            true,
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"preference": schema.Int64Attribute{
+							MarkdownDescription: "The preference (0-65535). Lower values are tried first",
+							Required:            true,
+						},
+						"host": schema.StringAttribute{
+							MarkdownDescription: "The mail server host name (e.g., `mx1.example.com.`), or `.` for a null MX",
+							Required:            true,
+						},
+					},
+				},
+			},
+			"expect_null_mx": schema.BoolAttribute{
+				MarkdownDescription: "When `true`, the records must be exactly one null MX (`0 .`), as published by domains that never send or receive mail. Defaults to `false`",
+				Optional:            true,
+			},
+			"change_ticket": changeTicketAttribute(),
+			"hosts": schema.ListAttribute{
+				MarkdownDescription: "The mail server host names ordered by preference, as senders try them",
+				Computed:            true,
+				ElementType:         types.StringType,
+			},
+			"is_null_mx": schema.BoolAttribute{
+				MarkdownDescription: "True if the records are a null MX (`0 .`, RFC 7505) declaring that the domain accepts no mail",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (d *MXDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	d.providerData = configureProviderData(req, resp)
+}
+
+func (d *MXDataSource) ValidateConfig(ctx context.Context, req datasource.ValidateConfigRequest, resp *datasource.ValidateConfigResponse) {
+	defer d.providerData.applyWarningSettings(&resp.Diagnostics)
+
+	var data MXDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if data.Domain.IsNull() == data.Records.IsNull() {
+		resp.Diagnostics.AddError(
+			summaryMXInvalidConfiguration,
+			"Exactly one of domain or records must be set.",
+		)
+	}
+}
+
+func (d *MXDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	defer d.providerData.applyWarningSettings(&resp.Diagnostics)
+
+	var data MXDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data.ChangeTicket = d.providerData.checkChangeTicket(data.ChangeTicket, &resp.Diagnostics)
+
+	var records []mxRecord
+	var diags diag.Diagnostics
+	if !data.Domain.IsNull() {
+		domain := data.Domain.ValueString()
+		found, err := lookupMXRecords(ctx, d.providerData.dnsResolver(), domain)
+		if err != nil {
+			resp.Diagnostics.AddError(lookupFailure(
+				summaryMXLookupFailed,
+				fmt.Sprintf("Unable to look up the MX records of %s: %s", domain, err.Error()),
+				err,
+			))
+			return
+		}
+		if len(found) == 0 {
+			resp.Diagnostics.AddWarning(
+				summaryMXNoRecords,
+				fmt.Sprintf("%s publishes no MX records, so senders fall back to delivering to its A and AAAA records. "+
+					"Publish MX records, or a null MX (0 .) if the domain does not receive mail.", domain),
+			)
+		}
+		records = found
+		validateMXRecords(records, path.Empty(), &diags)
+	} else {
+		var models []mxRecordModel
+		resp.Diagnostics.Append(data.Records.ElementsAs(ctx, &models, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		for _, m := range models {
+			records = append(records, mxRecord{Preference: m.Preference.ValueInt64(), Host: m.Host.ValueString()})
+		}
+		validateMXRecords(records, path.Root("records"), &diags)
+	}
+	resp.Diagnostics.Append(diags...)
+	if diags.HasError() {
+		return
+	}
+
+	if data.ExpectNullMX.ValueBool() && !isOnlyNullMX(records) {
+		resp.Diagnostics.AddError(
+			summaryMXNullExpected,
+			fmt.Sprintf("expect_null_mx is set, but the records are not a single null MX (0 .): %s. "+
+				"A domain that never receives mail must publish exactly one MX record with preference 0 and host \".\" (RFC 7505).", describeMXRecords(records)),
+		)
+		return
+	}
+
+	sort.SliceStable(records, func(i, j int) bool { return records[i].Preference < records[j].Preference })
+
+	values := make([]attr.Value, len(records))
+	hosts := make([]string, len(records))
+	for i, r := range records {
+		values[i] = types.ObjectValueMust(mxRecordObjectType.AttrTypes, map[string]attr.Value{
+			"preference": types.Int64Value(r.Preference),
+			"host":       types.StringValue(r.Host),
+		})
+		hosts[i] = r.Host
+	}
+	list, listDiags := types.ListValue(mxRecordObjectType, values)
+	resp.Diagnostics.Append(listDiags...)
+	data.Records = list
+	data.Hosts = convertStringSliceToList(ctx, hosts, &resp.Diagnostics)
+	data.IsNullMX = types.BoolValue(isOnlyNullMX(records))
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// lookupMXRecords returns the MX records published for domain. A domain
+// without MX records yields no records rather than an error.
+func lookupMXRecords(ctx context.Context, resolver dnsResolver, domain string) ([]mxRecord, error) {
+	mxs, err := resolver.LookupMX(ctx, domain)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	records := make([]mxRecord, len(mxs))
+	for i, mx := range mxs {
+		records[i] = mxRecord{Preference: int64(mx.Pref), Host: mx.Host}
+	}
+	return records, nil
+}
+
+// isOnlyNullMX reports whether records consist of a single null MX, which
+// declares that the domain accepts no mail.
+func isOnlyNullMX(records []mxRecord) bool {
+	return len(records) == 1 && isNullMX(records[0])
+}
+
+// describeMXRecords formats records for diagnostics, such as "10 mx1.example.com.".
+func describeMXRecords(records []mxRecord) string {
+	if len(records) == 0 {
+		return "no MX records"
+	}
+	described := make([]string, len(records))
+	for i, r := range records {
+		described[i] = fmt.Sprintf("%d %s", r.Preference, r.Host)
+	}
+	return strings.Join(described, ", ")
+}
+
+// isNullMX reports whether a record is the RFC 7505 null MX "0 .".
+func isNullMX(r mxRecord) bool {
+	return r.Host == "." && r.Preference == 0
+}
+
+// validateMXRecords reports problems with a set of MX records. When the
+// records come from configuration, list is the attribute holding them and
+// diagnostics for a single record are attached to its index; pass an empty
+// path for records found in DNS.
+func validateMXRecords(records []mxRecord, list path.Path, diags *diag.Diagnostics) {
+	addError := func(i int, attribute, summary, detail string) {
+		if list.Equal(path.Empty()) {
+			diags.AddError(summary, detail)
+			return
+		}
+		diags.AddAttributeError(list.AtListIndex(i).AtName(attribute), summary, detail)
+	}
+
+	nullMX := -1
+	byPreference := make(map[int64][]string)
+	for i, r := range records {
+		if r.Preference < 0 || r.Preference > 65535 {
+			addError(i, "preference",
+				"Invalid MX Preference",
+				fmt.Sprintf("The MX preference %d for %s is out of range; preferences must be between 0 and 65535.", r.Preference, r.Host),
+			)
+		}
+
+		if r.Host == "." {
+			nullMX = i
+			if r.Preference != 0 {
+				addError(i, "preference",
+					"Invalid Null MX",
+					fmt.Sprintf("A null MX (host .) must use preference 0 (RFC 7505), got %d.", r.Preference),
+				)
+			}
+			continue
+		}
+
+		if err := checkHostname(r.Host); err != nil {
+			addError(i, "host",
+				"Invalid MX Host",
+				fmt.Sprintf("The MX host is invalid: %s", err.Error()),
+			)
+			continue
+		}
+		if !strings.Contains(strings.TrimSuffix(r.Host, "."), ".") {
+			diags.AddWarning(
+				summaryMXRelativeHost,
+				fmt.Sprintf("The MX host %q is a single label, which most DNS providers treat as relative to the zone. "+
+					"Use the fully qualified name, ending in a dot (e.g., %s.example.com.).", r.Host, r.Host),
+			)
+		}
+		byPreference[r.Preference] = append(byPreference[r.Preference], r.Host)
+	}
+
+	if nullMX >= 0 && len(records) > 1 {
+		addError(nullMX, "host",
+			"Null MX With Other Hosts",
+			"The records include a null MX (0 .) alongside other MX records, which is contradictory. RFC 7505 requires a null MX to be the only MX record; "+
+				"remove it if the domain receives mail, or remove the other records if it does not.",
+		)
+	}
+
+	preferences := make([]int64, 0, len(byPreference))
+	for p := range byPreference {
+		preferences = append(preferences, p)
+	}
+	sort.Slice(preferences, func(i, j int) bool { return preferences[i] < preferences[j] })
+	for _, p := range preferences {
+		if hosts := byPreference[p]; len(hosts) > 1 {
+			diags.AddWarning(
+				summaryMXEqualPreferences,
+				fmt.Sprintf("The MX hosts %s share preference %d, so senders spread mail randomly across them. "+
+					"If one is meant as a backup, give it a higher preference.", strings.Join(hosts, ", "), p),
+			)
+		}
+	}
+}
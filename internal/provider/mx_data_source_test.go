@@ -0,0 +1,142 @@
+package provider
+
+import (
+	"context"
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+)
+
+func TestValidateMXRecords(t *testing.T) {
+	tests := []struct {
+		name         string
+		records      []mxRecord
+		wantErrors   []string
+		wantWarnings []string
+	}{
+		{
+			name:    "valid",
+			records: []mxRecord{{10, "mx1.example.com."}, {20, "mx2.example.com"}},
+		},
+		{
+			name:    "null mx",
+			records: []mxRecord{{0, "."}},
+		},
+		{
+			name:       "preference out of range",
+			records:    []mxRecord{{65536, "mx1.example.com."}, {-1, "mx2.example.com."}},
+			wantErrors: []string{"Invalid MX Preference", "Invalid MX Preference"},
+		},
+		{
+			name:       "ip literal",
+			records:    []mxRecord{{10, "192.0.2.25"}},
+			wantErrors: []string{"Invalid MX Host"},
+		},
+		{
+			name:       "null mx with preference",
+			records:    []mxRecord{{10, "."}},
+			wantErrors: []string{"Invalid Null MX"},
+		},
+		{
+			name:         "relative host",
+			records:      []mxRecord{{10, "mail"}},
+			wantWarnings: []string{"Relative MX Host"},
+		},
+		{
+			name:       "null mx with other hosts",
+			records:    []mxRecord{{0, "."}, {10, "mx1.example.com."}},
+			wantErrors: []string{"Null MX With Other Hosts"},
+		},
+		{
+			name:         "equal preferences",
+			records:      []mxRecord{{10, "mx1.example.com."}, {10, "mx2.example.com."}, {20, "mx3.example.com."}},
+			wantWarnings: []string{"Equal MX Preferences"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var diags diag.Diagnostics
+			validateMXRecords(tt.records, path.Root("records"), &diags)
+
+			var gotErrors, gotWarnings []string
+			for _, d := range diags {
+				if d.Severity() == diag.SeverityError {
+					gotErrors = append(gotErrors, d.Summary())
+				} else {
+					gotWarnings = append(gotWarnings, d.Summary())
+				}
+			}
+			if !reflect.DeepEqual(gotErrors, tt.wantErrors) {
+				t.Errorf("errors = %v, want %v", gotErrors, tt.wantErrors)
+			}
+			if !reflect.DeepEqual(gotWarnings, tt.wantWarnings) {
+				t.Errorf("warnings = %v, want %v", gotWarnings, tt.wantWarnings)
+			}
+		})
+	}
+}
+
+func TestValidateMXRecordsPaths(t *testing.T) {
+	records := []mxRecord{{10, "mx1.example.com."}, {10, "192.0.2.25"}}
+
+	var diags diag.Diagnostics
+	validateMXRecords(records, path.Root("records"), &diags)
+	want := path.Root("records").AtListIndex(1).AtName("host")
+	if len(diags) != 1 {
+		t.Fatalf("diagnostics = %v, want one", diags)
+	}
+	if d, ok := diags[0].(diag.DiagnosticWithPath); !ok || !d.Path().Equal(want) {
+		t.Errorf("diagnostic %v is not attached to %s", diags[0], want)
+	}
+
+	diags = nil
+	validateMXRecords(records, path.Empty(), &diags)
+	if _, ok := diags[0].(diag.DiagnosticWithPath); ok {
+		t.Errorf("diagnostic %v for records found in DNS has a path", diags[0])
+	}
+}
+
+func TestLookupMXRecords(t *testing.T) {
+	resolver := &fakeResolver{mx: map[string][]*net.MX{
+		"example.com": {{Host: "mx1.example.com.", Pref: 10}},
+	}}
+
+	got, err := lookupMXRecords(context.Background(), resolver, "example.com")
+	if err != nil {
+		t.Fatalf("lookupMXRecords() error = %v", err)
+	}
+	if want := []mxRecord{{10, "mx1.example.com."}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("lookupMXRecords() = %v, want %v", got, want)
+	}
+
+	got, err = lookupMXRecords(context.Background(), resolver, "nomail.example.com")
+	if err != nil || got != nil {
+		t.Errorf("lookupMXRecords() = %v, %v for a domain without MX records, want no records", got, err)
+	}
+}
+
+func TestIsOnlyNullMX(t *testing.T) {
+	tests := []struct {
+		name    string
+		records []mxRecord
+		want    bool
+	}{
+		{name: "null mx", records: []mxRecord{{0, "."}}, want: true},
+		{name: "no records"},
+		{name: "null mx with preference", records: []mxRecord{{10, "."}}},
+		{name: "null mx with other hosts", records: []mxRecord{{0, "."}, {10, "mx1.example.com."}}},
+		{name: "mail server", records: []mxRecord{{0, "mx1.example.com."}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isOnlyNullMX(tt.records); got != tt.want {
+				t.Errorf("isOnlyNullMX() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &NormalizeSPFFunction{}
+
+func NewNormalizeSPFFunction() function.Function {
+	return &NormalizeSPFFunction{}
+}
+
+// NormalizeSPFFunction defines the function implementation.
+type NormalizeSPFFunction struct{}
+
+func (f *NormalizeSPFFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "normalize_spf"
+}
+
+func (f *NormalizeSPFFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Normalize an SPF record",
+		MarkdownDescription: "Returns an SPF record in canonical form, the same as the `normalized_record` attribute of the `emaildns_spf` data source: " +
+			"single spaces between terms, an explicit qualifier on every mechanism, lowercase mechanism types, and modifiers last. " +
+			"Mechanism order is preserved. Two records that normalize to the same string are evaluated the same way. " +
+			"Fails if the record is not a valid SPF record.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "record",
+				MarkdownDescription: "The SPF record content (e.g., `v=spf1 include:_spf.google.com ~all`)",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *NormalizeSPFFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var record string
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &record))
+	if resp.Error != nil {
+		return
+	}
+
+	parsed, err := parseSPFArgument(record)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, "Invalid SPF record: "+err.Error())
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, normalizeSPFRecord(parsed)))
+}
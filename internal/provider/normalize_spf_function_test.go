@@ -0,0 +1,46 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestNormalizeSPFFunction(t *testing.T) {
+	tests := []struct {
+		name    string
+		record  string
+		want    string
+		wantErr bool
+	}{
+		{name: "canonical", record: "v=spf1 +mx -all", want: "v=spf1 +mx -all"},
+		{name: "implicit qualifiers", record: "v=spf1 mx a ~all", want: "v=spf1 +mx +a ~all"},
+		{name: "spacing and case", record: "  V=SPF1   Include:_spf.Example.com   IP4:192.0.2.1  -ALL ", want: "v=spf1 +include:_spf.Example.com +ip4:192.0.2.1 -all"},
+		{name: "order preserved", record: "v=spf1 include:b.example include:a.example -all", want: "v=spf1 +include:b.example +include:a.example -all"},
+		{name: "prefixes", record: "v=spf1 ip4:192.0.2.0/24 ip6:2001:db8::/32 a:mail.example.com/28 mx//64 -all", want: "v=spf1 +ip4:192.0.2.0/24 +ip6:2001:db8::/32 +a:mail.example.com/28 +mx//64 -all"},
+		{name: "modifiers last", record: "v=spf1 exp=explain.example.com redirect=_spf.example.com", want: "v=spf1 redirect=_spf.example.com exp=explain.example.com"},
+		{name: "macros kept", record: "v=spf1 exists:%{I}.%{d}.example.com -all", want: "v=spf1 +exists:%{I}.%{d}.example.com -all"},
+		{name: "invalid", record: "v=spf1 bogus", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := function.RunRequest{Arguments: function.NewArgumentsData([]attr.Value{types.StringValue(tt.record)})}
+			resp := function.RunResponse{Result: function.NewResultData(types.StringUnknown())}
+			NewNormalizeSPFFunction().Run(context.Background(), req, &resp)
+
+			if (resp.Error != nil) != tt.wantErr {
+				t.Fatalf("Run() error = %v, wantErr %v", resp.Error, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := resp.Result.Value(); !got.Equal(types.StringValue(tt.want)) {
+				t.Errorf("Run() = %v, want %q", got, tt.want)
+			}
+		})
+	}
+}
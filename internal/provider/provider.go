@@ -2,15 +2,32 @@ package provider
 
 import (
 	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	dsschema "github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
 // Ensure EmailDNSProvider satisfies various provider interfaces.
-var _ provider.Provider = &EmailDNSProvider{}
+var (
+	_ provider.Provider              = &EmailDNSProvider{}
+	_ provider.ProviderWithFunctions = &EmailDNSProvider{}
+)
 
 // EmailDNSProvider defines the provider implementation.
 type EmailDNSProvider struct {
@@ -22,7 +39,268 @@ type EmailDNSProvider struct {
 
 // EmailDNSProviderModel describes the provider data model.
 type EmailDNSProviderModel struct {
-	// No configuration needed - this is a validation-only provider
+	DNSAPIURL           types.String `tfsdk:"dns_api_url"`
+	DOHEndpoint         types.String `tfsdk:"doh_endpoint"`
+	DNSAuthToken        types.String `tfsdk:"dns_auth_token"`
+	DNSServers          types.List   `tfsdk:"dns_servers"`
+	DNSProtocol         types.String `tfsdk:"dns_protocol"`
+	DNSTimeout          types.String `tfsdk:"dns_timeout"`
+	DNSRetries          types.Int64  `tfsdk:"dns_retries"`
+	RequireChangeTicket types.Bool   `tfsdk:"require_change_ticket"`
+	WarnOnMonitoring    types.Bool   `tfsdk:"warn_on_monitoring"`
+	FailOnError         types.Bool   `tfsdk:"fail_on_error"`
+	MinDKIMKeyBits      types.Int64  `tfsdk:"min_dkim_key_bits"`
+	StrictMode          types.Bool   `tfsdk:"strict_mode"`
+	IgnoredWarnings     types.List   `tfsdk:"ignored_warnings"`
+}
+
+// providerData is handed to data sources through Configure and carries the
+// settings that live DNS checks need.
+type providerData struct {
+	resolver            dnsResolver
+	requireChangeTicket bool
+	warnOnMonitoring    bool
+	failOnError         bool
+	minDKIMKeyBits      int64
+	strictMode          bool
+	ignoredWarnings     map[string]bool
+}
+
+// dnsResolver returns the configured resolver, falling back to the system
+// resolver when the provider has not been configured.
+func (p *providerData) dnsResolver() dnsResolver {
+	if p == nil || p.resolver == nil {
+		return net.DefaultResolver
+	}
+	return p.resolver
+}
+
+// changeTicketAttribute returns the schema for the change_ticket input shared
+// by every data source.
+func changeTicketAttribute() dsschema.StringAttribute {
+	return dsschema.StringAttribute{
+		MarkdownDescription: "Identifier of the change ticket or approval this record belongs to (e.g., `CHG-1234`). " +
+			"Required to be non-empty when the provider sets `require_change_ticket`.",
+		Optional: true,
+		Computed: true,
+	}
+}
+
+// checkChangeTicket enforces require_change_ticket and returns the trimmed
+// ticket for state, or null when none was given.
+func (p *providerData) checkChangeTicket(ticket types.String, diags *diag.Diagnostics) types.String {
+	value := strings.TrimSpace(ticket.ValueString())
+
+	if value == "" && p != nil && p.requireChangeTicket {
+		diags.AddAttributeError(
+			path.Root("change_ticket"),
+			summaryChangeTicketMissing,
+			"The provider is configured with require_change_ticket = true, so change_ticket must be set to the ticket or approval for this record.",
+		)
+	}
+
+	if value == "" {
+		return types.StringNull()
+	}
+	return types.StringValue(value)
+}
+
+// recordFileAttribute returns the schema for the record_file input of the
+// record data sources, which is an alternative to record.
+func recordFileAttribute() dsschema.StringAttribute {
+	return dsschema.StringAttribute{
+		MarkdownDescription: "Path of a local file holding the TXT record content to validate, for records kept in version-controlled text files. " +
+			"A single trailing line break is ignored. Exactly one of `record` and `record_file` must be set",
+		Optional: true,
+	}
+}
+
+// recordInput returns the record a data source validates: record itself, or
+// the content of record_file. It reports an error unless exactly one of them
+// is set, and returns an unknown value while record_file is unknown.
+func recordInput(record, recordFile types.String, diags *diag.Diagnostics) types.String {
+	if recordFile.IsNull() {
+		if record.IsNull() {
+			diags.AddAttributeError(
+				path.Root("record"),
+				summaryRecordMissing,
+				"One of record or record_file must be set.",
+			)
+		}
+		return record
+	}
+	if !record.IsNull() {
+		diags.AddAttributeError(
+			path.Root("record_file"),
+			summaryRecordConflictingSettings,
+			"Only one of record and record_file can be set. Remove record to validate the content of the file, or remove record_file.",
+		)
+		return record
+	}
+	if recordFile.IsUnknown() {
+		return types.StringUnknown()
+	}
+
+	content, err := os.ReadFile(recordFile.ValueString())
+	if err != nil {
+		diags.AddAttributeError(
+			path.Root("record_file"),
+			summaryRecordFileUnreadable,
+			fmt.Sprintf("Unable to read the record from %s: %s", recordFile.ValueString(), err.Error()),
+		)
+		return types.StringNull()
+	}
+	value := strings.TrimSuffix(strings.TrimSuffix(string(content), "\n"), "\r")
+	return types.StringValue(value)
+}
+
+// checkLookupInput reports configuration errors of a record data source with
+// lookup = true. The record is then looked up under domain, so domain must be
+// set and neither record nor record_file may be.
+func checkLookupInput(record, recordFile, domain types.String, diags *diag.Diagnostics) {
+	if domain.IsNull() {
+		diags.AddAttributeError(
+			path.Root("domain"),
+			summaryDomainMissing,
+			"domain must be set when lookup is true, since the published record is looked up under it.",
+		)
+	}
+	if !record.IsNull() {
+		diags.AddAttributeError(
+			path.Root("record"),
+			summaryRecordConflictingSettings,
+			"record cannot be set when lookup is true. Remove record to validate the published record, or remove lookup.",
+		)
+	}
+	if !recordFile.IsNull() {
+		diags.AddAttributeError(
+			path.Root("record_file"),
+			summaryRecordConflictingSettings,
+			"record_file cannot be set when lookup is true. Remove record_file to validate the published record, or remove lookup.",
+		)
+	}
+}
+
+// validAttribute and warningsAttribute return the schemas for the validation
+// results shared by the record data sources.
+func validAttribute() dsschema.BoolAttribute {
+	return dsschema.BoolAttribute{
+		MarkdownDescription: "True if the record passed validation. Only false when the provider sets `fail_on_error = false`, since errors otherwise fail the plan",
+		Computed:            true,
+	}
+}
+
+func warningsAttribute() dsschema.ListAttribute {
+	return dsschema.ListAttribute{
+		MarkdownDescription: "Every problem found while validating the record, including errors when the provider sets `fail_on_error = false`",
+		Computed:            true,
+		ElementType:         types.StringType,
+	}
+}
+
+// routeDiagnostics copies the diagnostics of a record check into diags. When
+// the provider sets fail_on_error = false, errors are downgraded to warnings
+// so that invalid records are reported without failing the plan.
+//
+// The provider is not configured while Terraform validates the configuration
+// (terraform validate, and the validate walk that plan runs first), so p is
+// nil then. Errors are kept in that case, matching the fail_on_error default,
+// so that an invalid record always fails validation.
+func (p *providerData) routeDiagnostics(from diag.Diagnostics, diags *diag.Diagnostics) {
+	for _, d := range from {
+		if d.Severity() == diag.SeverityError && p != nil && !p.failOnError {
+			warning := diag.NewWarningDiagnostic(d.Summary(), d.Detail())
+			if withPath, ok := d.(diag.DiagnosticWithPath); ok {
+				diags.Append(diag.WithPath(withPath.Path(), warning))
+				continue
+			}
+			diags.Append(warning)
+			continue
+		}
+		diags.Append(d)
+	}
+}
+
+// applyWarningSettings drops the warnings listed in ignored_warnings from
+// diags and, when the provider sets strict_mode, promotes every remaining
+// warning to an error. It also adds the diagnostic code to the detail of
+// every diagnostic. Data sources defer it at the start of ValidateConfig and
+// Read so that it sees all of their diagnostics.
+func (p *providerData) applyWarningSettings(diags *diag.Diagnostics) {
+	addDiagnosticCodes(diags)
+	if p == nil {
+		return
+	}
+	*diags = p.withoutIgnoredWarnings(*diags)
+	if !p.strictMode {
+		return
+	}
+	for i, d := range *diags {
+		if d.Severity() != diag.SeverityWarning {
+			continue
+		}
+		promoted := diag.NewErrorDiagnostic(d.Summary(), d.Detail())
+		if withPath, ok := d.(diag.DiagnosticWithPath); ok {
+			(*diags)[i] = diag.WithPath(withPath.Path(), promoted)
+			continue
+		}
+		(*diags)[i] = promoted
+	}
+}
+
+// checkRecord runs the static validation and then the read of a record data
+// source. ValidateConfig has already reported what validate finds, so those
+// diagnostics only feed the returned valid and warnings attribute values,
+// while those of read are also added to diags.
+func (p *providerData) checkRecord(validate, read func(*diag.Diagnostics), diags *diag.Diagnostics) (types.Bool, types.List) {
+	var validateDiags, readDiags diag.Diagnostics
+	validate(&validateDiags)
+	if !validateDiags.HasError() {
+		read(&readDiags)
+	}
+	p.routeDiagnostics(readDiags, diags)
+	return validationResult(p.withoutIgnoredWarnings(append(validateDiags, readDiags...)))
+}
+
+// validationResult returns the values of the valid and warnings attributes
+// for the diagnostics of a record check.
+func validationResult(checks diag.Diagnostics) (types.Bool, types.List) {
+	warnings := make([]attr.Value, 0, len(checks))
+	for _, d := range checks {
+		warnings = append(warnings, types.StringValue(d.Summary()+": "+d.Detail()))
+	}
+	return types.BoolValue(!checks.HasError()), types.ListValueMust(types.StringType, warnings)
+}
+
+// setInvalidRecordState stores the configuration as the state of a data
+// source whose record could not be read, so every computed attribute is null
+// apart from change_ticket and the validation results.
+func setInvalidRecordState(ctx context.Context, config tfsdk.Config, state *tfsdk.State, changeTicket types.String, valid types.Bool, warnings types.List) diag.Diagnostics {
+	var diags diag.Diagnostics
+	state.Raw = config.Raw
+	diags.Append(state.SetAttribute(ctx, path.Root("change_ticket"), changeTicket)...)
+	diags.Append(state.SetAttribute(ctx, path.Root("valid"), valid)...)
+	diags.Append(state.SetAttribute(ctx, path.Root("warnings"), warnings)...)
+	return diags
+}
+
+// configureProviderData extracts the providerData passed to a data source's
+// Configure method. It returns nil when the provider is not yet configured.
+func configureProviderData(req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) *providerData {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return nil
+	}
+
+	data, ok := req.ProviderData.(*providerData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *providerData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return nil
+	}
+	return data
 }
 
 func (p *EmailDNSProvider) Metadata(ctx context.Context, req provider.MetadataRequest, resp *provider.MetadataResponse) {
@@ -34,16 +312,317 @@ func (p *EmailDNSProvider) Schema(ctx context.Context, req provider.SchemaReques
 	resp.Schema = schema.Schema{
 		MarkdownDescription: "The Email DNS provider validates email-related DNS TXT records (DMARC, SPF, DKIM) during the Terraform planning phase. " +
 			"This ensures malformed records are caught before they are applied to your DNS provider.",
+
+		Attributes: map[string]schema.Attribute{
+			"dns_api_url": schema.StringAttribute{
+				MarkdownDescription: "Deprecated alias of `doh_endpoint`.",
+				Optional:            true,
+				DeprecationMessage:  "Use doh_endpoint instead. dns_api_url will be removed in a future major version.",
+			},
+			"doh_endpoint": schema.StringAttribute{
+				MarkdownDescription: "URL of an RFC 8484 DNS-over-HTTPS endpoint used for all live DNS lookups (e.g., `https://dns.google/dns-query`), " +
+					"for environments that only allow outbound HTTPS. Queries are sent as `POST` requests with an `application/dns-message` body. " +
+					"When unset, the system resolver is used. Conflicts with `dns_servers`.",
+				Optional: true,
+			},
+			"dns_auth_token": schema.StringAttribute{
+				MarkdownDescription: "Bearer token sent in the `Authorization` header of requests to `doh_endpoint`, for resolvers that require authentication.",
+				Optional:            true,
+				Sensitive:           true,
+			},
+			"dns_servers": schema.ListAttribute{
+				MarkdownDescription: "DNS servers used for live DNS lookups instead of the system resolver, as `host:port` (e.g., `10.0.0.53:53`). " +
+					"The port defaults to 53 for bare IP addresses. Servers are tried in turn. Conflicts with `doh_endpoint`.",
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+			"dns_protocol": schema.StringAttribute{
+				MarkdownDescription: "Protocol used to query `dns_servers`: `udp` (with TCP fallback for truncated responses) or `tcp`. Defaults to `udp`.",
+				Optional:            true,
+			},
+			"dns_timeout": schema.StringAttribute{
+				MarkdownDescription: "How long a single live DNS lookup may take before it is abandoned, as a duration (e.g., `5s` or `1500ms`). Defaults to `5s`.",
+				Optional:            true,
+			},
+			"dns_retries": schema.Int64Attribute{
+				MarkdownDescription: "How many times a live DNS lookup that times out or fails temporarily is retried. " +
+					"When every attempt fails, a `Temporary DNS Failure` diagnostic is reported. Defaults to `2`.",
+				Optional: true,
+			},
+			"require_change_ticket": schema.BoolAttribute{
+				MarkdownDescription: "When `true`, every data source must set a non-empty `change_ticket`, so that each email DNS change can be traced to an approval. Defaults to `false`.",
+				Optional:            true,
+			},
+			"fail_on_error": schema.BoolAttribute{
+				MarkdownDescription: "When `false`, invalid SPF, DMARC, and DKIM records are reported as warnings and through the `valid` and `warnings` attributes instead of failing the plan, for reporting dashboards and gradual remediation. Defaults to `true`.",
+				Optional:            true,
+			},
+			"min_dkim_key_bits": schema.Int64Attribute{
+				MarkdownDescription: "Minimum RSA key size in bits for DKIM records. Shorter keys are errors instead of warnings. " +
+					"When unset, RSA keys under 2048 bits only produce a warning.",
+				Optional: true,
+			},
+			"strict_mode": schema.BoolAttribute{
+				MarkdownDescription: "When `true`, every warning produced by a data source is reported as an error, so that any finding fails the plan. " +
+					"Cannot be combined with `fail_on_error = false`. Defaults to `false`.",
+				Optional: true,
+			},
+			"ignored_warnings": schema.ListAttribute{
+				MarkdownDescription: "Codes of warnings to suppress (e.g., `DMARC_POLICY_NONE`), for documented exceptions that should not be reported on every plan. " +
+					"Ignored warnings are also left out of the `warnings` attribute and are not promoted by `strict_mode`. " +
+					"See the provider documentation for the list of codes.",
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+			"warn_on_monitoring": schema.BoolAttribute{
+				MarkdownDescription: "When `true`, DMARC records with a monitoring-only `p=none` policy produce a warning, for audits that require enforcing policies. Defaults to `false`.",
+				Optional:            true,
+			},
+		},
 	}
 }
 
 func (p *EmailDNSProvider) Configure(ctx context.Context, req provider.ConfigureRequest, resp *provider.ConfigureResponse) {
-	// No configuration needed - this is a validation-only provider
+	var config EmailDNSProviderModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if config.DNSAPIURL.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("dns_api_url"),
+			"Unknown DNS API URL",
+			"The provider cannot be configured because dns_api_url is not known until apply. "+
+				"Set it to a static value or remove it to use the system resolver.",
+		)
+	}
+	if config.DOHEndpoint.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("doh_endpoint"),
+			"Unknown DoH Endpoint",
+			"The provider cannot be configured because doh_endpoint is not known until apply. "+
+				"Set it to a static value or remove it to use the system resolver.",
+		)
+	}
+	if config.DNSAuthToken.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("dns_auth_token"),
+			"Unknown DNS Auth Token",
+			"The provider cannot be configured because dns_auth_token is not known until apply.",
+		)
+	}
+	if config.DNSServers.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("dns_servers"),
+			"Unknown DNS Servers",
+			"The provider cannot be configured because dns_servers is not known until apply. "+
+				"Set it to a static value or remove it to use the system resolver.",
+		)
+	}
+	if config.DNSProtocol.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("dns_protocol"),
+			"Unknown DNS Protocol",
+			"The provider cannot be configured because dns_protocol is not known until apply.",
+		)
+	}
+	if protocol := config.DNSProtocol.ValueString(); protocol != "" && protocol != "udp" && protocol != "tcp" {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("dns_protocol"),
+			"Invalid DNS Protocol",
+			fmt.Sprintf("dns_protocol must be udp or tcp, got %q.", protocol),
+		)
+	}
+	dnsTimeout := defaultDNSTimeout
+	if config.DNSTimeout.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("dns_timeout"),
+			"Unknown DNS Timeout",
+			"The provider cannot be configured because dns_timeout is not known until apply.",
+		)
+	} else if !config.DNSTimeout.IsNull() {
+		timeout, err := time.ParseDuration(config.DNSTimeout.ValueString())
+		if err != nil || timeout <= 0 {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("dns_timeout"),
+				"Invalid DNS Timeout",
+				fmt.Sprintf("dns_timeout must be a positive duration such as 5s or 1500ms, got %q.", config.DNSTimeout.ValueString()),
+			)
+		}
+		dnsTimeout = timeout
+	}
+	dnsRetries := int64(defaultDNSRetries)
+	if config.DNSRetries.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("dns_retries"),
+			"Unknown DNS Retries",
+			"The provider cannot be configured because dns_retries is not known until apply.",
+		)
+	} else if !config.DNSRetries.IsNull() {
+		dnsRetries = config.DNSRetries.ValueInt64()
+		if dnsRetries < 0 {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("dns_retries"),
+				"Invalid DNS Retries",
+				fmt.Sprintf("dns_retries must not be negative, got %d.", dnsRetries),
+			)
+		}
+	}
+	if config.StrictMode.ValueBool() && !config.FailOnError.IsNull() && !config.FailOnError.ValueBool() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("strict_mode"),
+			"Conflicting Error Handling Settings",
+			"strict_mode turns warnings into errors while fail_on_error = false turns errors into warnings, so they cannot be combined.",
+		)
+	}
+	if config.MinDKIMKeyBits.ValueInt64() < 0 {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("min_dkim_key_bits"),
+			"Invalid Minimum DKIM Key Size",
+			fmt.Sprintf("min_dkim_key_bits must not be negative, got %d.", config.MinDKIMKeyBits.ValueInt64()),
+		)
+	}
+	var ignoredWarnings []string
+	if config.IgnoredWarnings.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("ignored_warnings"),
+			"Unknown Ignored Warnings",
+			"The provider cannot be configured because ignored_warnings is not known until apply.",
+		)
+	} else {
+		resp.Diagnostics.Append(config.IgnoredWarnings.ElementsAs(ctx, &ignoredWarnings, false)...)
+	}
+	for i, code := range ignoredWarnings {
+		if !isKnownWarningCode(code) {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("ignored_warnings").AtListIndex(i),
+				"Unknown Warning Code",
+				fmt.Sprintf("%q is not a warning code. Valid codes are: %s.", code, strings.Join(knownWarningCodes(), ", ")),
+			)
+		}
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data := &providerData{
+		resolver:            net.DefaultResolver,
+		requireChangeTicket: config.RequireChangeTicket.ValueBool(),
+		warnOnMonitoring:    config.WarnOnMonitoring.ValueBool(),
+		failOnError:         config.FailOnError.IsNull() || config.FailOnError.ValueBool(),
+		minDKIMKeyBits:      config.MinDKIMKeyBits.ValueInt64(),
+		strictMode:          config.StrictMode.ValueBool(),
+		ignoredWarnings:     make(map[string]bool, len(ignoredWarnings)),
+	}
+	for _, code := range ignoredWarnings {
+		data.ignoredWarnings[code] = true
+	}
+
+	// dns_api_url is the deprecated name of doh_endpoint
+	dohAttribute, dohEndpoint := "doh_endpoint", config.DOHEndpoint.ValueString()
+	if apiURL := config.DNSAPIURL.ValueString(); apiURL != "" {
+		if dohEndpoint != "" {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("dns_api_url"),
+				"Conflicting DNS Resolver Settings",
+				"dns_api_url is a deprecated alias of doh_endpoint and cannot be set together with it. Remove dns_api_url.",
+			)
+			return
+		}
+		dohAttribute, dohEndpoint = "dns_api_url", apiURL
+	}
+	authToken := config.DNSAuthToken.ValueString()
+
+	var servers []string
+	resp.Diagnostics.Append(config.DNSServers.ElementsAs(ctx, &servers, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	for i, server := range servers {
+		normalized, err := normalizeDNSServer(server)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("dns_servers").AtListIndex(i),
+				"Invalid DNS Server",
+				fmt.Sprintf("The DNS server is invalid: %s", err.Error()),
+			)
+			continue
+		}
+		servers[i] = normalized
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if len(servers) > 0 {
+		if dohEndpoint != "" {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("dns_servers"),
+				"Conflicting DNS Resolver Settings",
+				fmt.Sprintf("dns_servers and %s cannot both be set. Use dns_servers to query DNS servers directly, or %s to query a DNS-over-HTTPS endpoint.", dohAttribute, dohAttribute),
+			)
+			return
+		}
+		data.resolver = newServerResolver(servers, config.DNSProtocol.ValueString())
+	} else if !config.DNSProtocol.IsNull() {
+		resp.Diagnostics.AddAttributeWarning(
+			path.Root("dns_protocol"),
+			"DNS Protocol Ignored",
+			"dns_protocol is only used together with dns_servers. Live lookups will use the system resolver.",
+		)
+	}
+
+	if dohEndpoint != "" {
+		u, err := url.Parse(dohEndpoint)
+		if err != nil || u.Scheme != "https" || u.Host == "" {
+			resp.Diagnostics.AddAttributeError(
+				path.Root(dohAttribute),
+				"Invalid DoH Endpoint",
+				fmt.Sprintf("%s must be an absolute https:// URL of an RFC 8484 DNS-over-HTTPS endpoint.", dohAttribute),
+			)
+			return
+		}
+		data.resolver = &dohResolver{
+			endpoint:  dohEndpoint,
+			authToken: authToken,
+			client:    http.DefaultClient,
+		}
+	} else if authToken != "" {
+		resp.Diagnostics.AddAttributeWarning(
+			path.Root("dns_auth_token"),
+			"DNS Auth Token Ignored",
+			"dns_auth_token is only used together with doh_endpoint. Live lookups will use the system resolver without authentication.",
+		)
+	}
+
+	data.resolver = &retryingResolver{
+		resolver: data.resolver,
+		timeout:  dnsTimeout,
+		retries:  int(dnsRetries),
+	}
+
+	resp.DataSourceData = data
+}
+
+func (p *EmailDNSProvider) Functions(ctx context.Context) []func() function.Function {
+	return []func() function.Function{
+		NewSPFLookupCountFunction,
+		NewDMARCIsValidFunction,
+		NewDMARCPolicyFunction,
+		NewNormalizeSPFFunction,
+		NewDKIMKeyBitsFunction,
+		NewSPFIncludesFunction,
+		NewBuildDMARCFunction,
+	}
 }
 
 func (p *EmailDNSProvider) Resources(ctx context.Context) []func() resource.Resource {
 	return []func() resource.Resource{
-		// No resources - this provider only has data sources
+		NewDKIMKeyPairResource,
+		NewSPFRecordResource,
+		NewDMARCRecordResource,
 	}
 }
 
@@ -52,6 +631,21 @@ func (p *EmailDNSProvider) DataSources(ctx context.Context) []func() datasource.
 		NewDMARCDataSource,
 		NewSPFDataSource,
 		NewDKIMDataSource,
+		NewSPFMergeDataSource,
+		NewDMARCExternalCheckDataSource,
+		NewDomainDataSource,
+		NewMXDataSource,
+		NewMTASTSDataSource,
+		NewTLSRPTDataSource,
+		NewBIMIDataSource,
+		NewCAADataSource,
+		NewPTRDataSource,
+		NewSRVDataSource,
+		NewTXTDataSource,
+		NewTLSADataSource,
+		NewDKIMSelectorsDataSource,
+		NewADSPDataSource,
+		NewDKIMLookupDataSource,
 	}
 }
 
@@ -0,0 +1,517 @@
+package provider
+
+import (
+	"context"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/provider"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+func TestCheckRecord(t *testing.T) {
+	validate := func(diags *diag.Diagnostics) { diags.AddWarning("Static Warning", "found by validate") }
+	read := func(diags *diag.Diagnostics) { diags.AddError("Read Error", "found by read") }
+
+	tests := []struct {
+		name         string
+		provider     *providerData
+		wantSeverity diag.Severity
+	}{
+		{name: "unconfigured", provider: nil, wantSeverity: diag.SeverityError},
+		{name: "fail on error", provider: &providerData{failOnError: true}, wantSeverity: diag.SeverityError},
+		{name: "report only", provider: &providerData{failOnError: false}, wantSeverity: diag.SeverityWarning},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var diags diag.Diagnostics
+			valid, warnings := tt.provider.checkRecord(validate, read, &diags)
+
+			if valid.ValueBool() {
+				t.Error("valid = true, want false")
+			}
+			if got := len(warnings.Elements()); got != 2 {
+				t.Errorf("len(warnings) = %d, want 2", got)
+			}
+			// Only the read diagnostics are reported; validate's were already
+			// reported by ValidateConfig
+			if len(diags) != 1 || diags[0].Severity() != tt.wantSeverity {
+				t.Errorf("diagnostics = %v, want one with severity %v", diags, tt.wantSeverity)
+			}
+		})
+	}
+}
+
+func TestApplyWarningSettingsStrictMode(t *testing.T) {
+	newDiags := func() diag.Diagnostics {
+		var diags diag.Diagnostics
+		diags.AddWarning("Plain Warning", "no path")
+		diags.AddAttributeWarning(path.Root("record"), "Attribute Warning", "with path")
+		diags.AddError("Existing Error", "unchanged")
+		return diags
+	}
+
+	tests := []struct {
+		name     string
+		provider *providerData
+		wantErrs int
+	}{
+		{name: "unconfigured", provider: nil, wantErrs: 1},
+		{name: "not strict", provider: &providerData{}, wantErrs: 1},
+		{name: "strict", provider: &providerData{strictMode: true}, wantErrs: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diags := newDiags()
+			tt.provider.applyWarningSettings(&diags)
+
+			if got := diags.ErrorsCount(); got != tt.wantErrs {
+				t.Errorf("ErrorsCount() = %d, want %d", got, tt.wantErrs)
+			}
+			if d, ok := diags[1].(diag.DiagnosticWithPath); !ok || !d.Path().Equal(path.Root("record")) {
+				t.Errorf("diagnostic %v lost its path", diags[1])
+			}
+			if diags[0].Summary() != "Plain Warning" || diags[0].Detail() != "no path" {
+				t.Errorf("diagnostic %v changed its text", diags[0])
+			}
+		})
+	}
+}
+
+func TestApplyWarningSettingsIgnoredWarnings(t *testing.T) {
+	provider := &providerData{strictMode: true, ignoredWarnings: map[string]bool{"DMARC_POLICY_NONE": true}}
+
+	var diags diag.Diagnostics
+	diags.AddWarning("Monitoring-Only DMARC Policy", "ignored")
+	diags.AddWarning("No DMARC Aggregate Reporting", "not ignored")
+	diags.AddError("Monitoring-Only DMARC Policy", "errors are never ignored")
+	provider.applyWarningSettings(&diags)
+
+	if len(diags) != 2 {
+		t.Fatalf("diagnostics = %v, want 2", diags)
+	}
+	for _, d := range diags {
+		if d.Severity() != diag.SeverityError || d.Detail() == "ignored" {
+			t.Errorf("unexpected diagnostic %v", d)
+		}
+	}
+
+	validate := func(diags *diag.Diagnostics) { diags.AddWarning("Monitoring-Only DMARC Policy", "ignored") }
+	read := func(diags *diag.Diagnostics) {}
+	valid, warnings := provider.checkRecord(validate, read, &diags)
+	if !valid.ValueBool() || len(warnings.Elements()) != 0 {
+		t.Errorf("checkRecord() = %v, %v, want valid without warnings", valid, warnings)
+	}
+}
+
+func TestCheckChangeTicket(t *testing.T) {
+	required := &providerData{requireChangeTicket: true}
+
+	tests := []struct {
+		name     string
+		provider *providerData
+		ticket   types.String
+		want     types.String
+		wantErr  bool
+	}{
+		{name: "required and missing", provider: required, ticket: types.StringNull(), want: types.StringNull(), wantErr: true},
+		{name: "required and blank", provider: required, ticket: types.StringValue("  "), want: types.StringNull(), wantErr: true},
+		{name: "required and present", provider: required, ticket: types.StringValue(" CHG-1234 "), want: types.StringValue("CHG-1234")},
+		{name: "not required", provider: &providerData{}, ticket: types.StringNull(), want: types.StringNull()},
+		{name: "not required but present", provider: &providerData{}, ticket: types.StringValue("CHG-1234"), want: types.StringValue("CHG-1234")},
+		{name: "unconfigured", provider: nil, ticket: types.StringNull(), want: types.StringNull()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var diags diag.Diagnostics
+			got := tt.provider.checkChangeTicket(tt.ticket, &diags)
+			if diags.HasError() != tt.wantErr {
+				t.Fatalf("checkChangeTicket() diagnostics = %v, wantErr %v", diags, tt.wantErr)
+			}
+			if tt.wantErr {
+				d, ok := diags[0].(diag.DiagnosticWithPath)
+				if diags[0].Summary() != "Missing Change Ticket" || !ok || !d.Path().Equal(path.Root("change_ticket")) {
+					t.Errorf("checkChangeTicket() diagnostics = %v, want Missing Change Ticket on change_ticket", diags)
+				}
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("checkChangeTicket() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	resp := testConfigureProvider(t, map[string]tftypes.Value{"require_change_ticket": tftypes.NewValue(tftypes.Bool, true)})
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("Configure() diagnostics = %v", resp.Diagnostics)
+	}
+	if !resp.DataSourceData.(*providerData).requireChangeTicket {
+		t.Error("require_change_ticket = true did not require a change ticket")
+	}
+}
+
+func TestWarningCodes(t *testing.T) {
+	seen := make(map[string]string, len(warningCodes))
+	for summary, code := range warningCodes {
+		if other, ok := seen[code]; ok {
+			t.Errorf("code %s is used by both %q and %q", code, summary, other)
+		}
+		seen[code] = summary
+	}
+
+	if !isKnownWarningCode("SPF_PTR_DEPRECATED") {
+		t.Error("isKnownWarningCode(SPF_PTR_DEPRECATED) = false, want true")
+	}
+	if isKnownWarningCode("Monitoring-Only DMARC Policy") {
+		t.Error("isKnownWarningCode() accepted a summary")
+	}
+}
+
+func TestErrorCodes(t *testing.T) {
+	seen := make(map[string]string, len(errorCodes)+len(warningCodes))
+	for summary, code := range warningCodes {
+		seen[code] = summary
+	}
+	for summary, code := range errorCodes {
+		if _, ok := warningCodes[summary]; ok {
+			t.Errorf("%q has both a warning and an error code", summary)
+		}
+		if other, ok := seen[code]; ok {
+			t.Errorf("code %s is used by both %q and %q", code, summary, other)
+		}
+		seen[code] = summary
+	}
+}
+
+// diagnosticSummaryArgs maps the functions that report diagnostics to the
+// position of their summary argument.
+var diagnosticSummaryArgs = map[string]int{
+	"AddError":                      0,
+	"AddWarning":                    0,
+	"NewErrorDiagnostic":            0,
+	"NewWarningDiagnostic":          0,
+	"lookupFailure":                 0,
+	"AddAttributeError":             1,
+	"AddAttributeWarning":           1,
+	"NewAttributeErrorDiagnostic":   1,
+	"NewAttributeWarningDiagnostic": 1,
+}
+
+func TestDiagnosticSummariesHaveCodes(t *testing.T) {
+	files, err := filepath.Glob("*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fset := token.NewFileSet()
+	var parsed []*ast.File
+	constants := make(map[string]string)
+	for _, name := range files {
+		if strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(fset, name, nil, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		parsed = append(parsed, file)
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.CONST {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				value := spec.(*ast.ValueSpec)
+				for i, ident := range value.Names {
+					if i < len(value.Values) {
+						if lit, ok := value.Values[i].(*ast.BasicLit); ok && lit.Kind == token.STRING {
+							constants[ident.Name], _ = strconv.Unquote(lit.Value)
+						}
+					}
+				}
+			}
+		}
+	}
+
+	for _, file := range parsed {
+		// Provider configuration errors have no code
+		if fset.Position(file.Pos()).Filename == "provider.go" {
+			continue
+		}
+		ast.Inspect(file, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			var fn string
+			switch f := call.Fun.(type) {
+			case *ast.Ident:
+				fn = f.Name
+			case *ast.SelectorExpr:
+				fn = f.Sel.Name
+			}
+			i, ok := diagnosticSummaryArgs[fn]
+			if !ok || i >= len(call.Args) {
+				return true
+			}
+
+			pos := fset.Position(call.Pos())
+			switch arg := call.Args[i].(type) {
+			case *ast.BasicLit:
+				t.Errorf("%s: %s reports the literal summary %s; use a summary constant with a code", pos, fn, arg.Value)
+			case *ast.Ident:
+				summary, ok := constants[arg.Name]
+				if !ok {
+					return true
+				}
+				if _, ok := diagnosticCode(summary); !ok {
+					t.Errorf("%s: %s reports %q, which has no diagnostic code", pos, fn, summary)
+				}
+			}
+			return true
+		})
+	}
+}
+
+func TestAddDiagnosticCodes(t *testing.T) {
+	var diags diag.Diagnostics
+	diags.AddAttributeError(path.Root("record"), "Invalid SPF Record", "malformed")
+	diags.AddWarning("Deprecated SPF ptr Mechanism", "ptr")
+	diags.AddWarning("Plain Warning", "no code")
+
+	addDiagnosticCodes(&diags)
+	addDiagnosticCodes(&diags)
+
+	want := []struct {
+		severity diag.Severity
+		detail   string
+	}{
+		{severity: diag.SeverityError, detail: "malformed\n\nDiagnostic code: SPF_PARSE_ERROR"},
+		{severity: diag.SeverityWarning, detail: "ptr\n\nDiagnostic code: SPF_PTR_DEPRECATED"},
+		{severity: diag.SeverityWarning, detail: "no code"},
+	}
+	for i, w := range want {
+		if diags[i].Severity() != w.severity || diags[i].Detail() != w.detail {
+			t.Errorf("diagnostic %d = %v %q, want %v %q", i, diags[i].Severity(), diags[i].Detail(), w.severity, w.detail)
+		}
+	}
+	if d, ok := diags[0].(diag.DiagnosticWithPath); !ok || !d.Path().Equal(path.Root("record")) {
+		t.Errorf("diagnostic %v lost its path", diags[0])
+	}
+}
+
+func TestSetInvalidRecordState(t *testing.T) {
+	ctx := context.Background()
+
+	var schemaResp datasource.SchemaResponse
+	NewSPFDataSource().Schema(ctx, datasource.SchemaRequest{}, &schemaResp)
+	objectType := schemaResp.Schema.Type().TerraformType(ctx).(tftypes.Object)
+
+	configValues := make(map[string]tftypes.Value, len(objectType.AttributeTypes))
+	for name, typ := range objectType.AttributeTypes {
+		configValues[name] = tftypes.NewValue(typ, nil)
+	}
+	configValues["record"] = tftypes.NewValue(tftypes.String, "v=spf1 bogus")
+	configValues["resolve"] = tftypes.NewValue(tftypes.Bool, true)
+
+	config := tfsdk.Config{Schema: schemaResp.Schema, Raw: tftypes.NewValue(objectType, configValues)}
+	state := tfsdk.State{Schema: schemaResp.Schema, Raw: tftypes.NewValue(objectType, nil)}
+
+	valid, warnings := validationResult(diag.Diagnostics{diag.NewErrorDiagnostic("Invalid SPF Record", "bogus")})
+	diags := setInvalidRecordState(ctx, config, &state, types.StringNull(), valid, warnings)
+	if diags.HasError() {
+		t.Fatalf("setInvalidRecordState() diagnostics = %v", diags)
+	}
+
+	var got SPFDataSourceModel
+	if diags := state.Get(ctx, &got); diags.HasError() {
+		t.Fatalf("state.Get() diagnostics = %v", diags)
+	}
+	if got.Record.ValueString() != "v=spf1 bogus" || !got.Resolve.ValueBool() {
+		t.Errorf("configured values not preserved: record = %s, resolve = %s", got.Record, got.Resolve)
+	}
+	if got.Valid.ValueBool() || len(got.Warnings.Elements()) != 1 {
+		t.Errorf("valid = %s, warnings = %s", got.Valid, got.Warnings)
+	}
+	if !got.Mechanisms.IsNull() {
+		t.Errorf("mechanisms = %s, want null", got.Mechanisms)
+	}
+}
+
+// testDataSourceConfig returns a configuration of ds with the given
+// attributes set and every other attribute null.
+func testDataSourceConfig(t *testing.T, ds datasource.DataSource, values map[string]tftypes.Value) tfsdk.Config {
+	t.Helper()
+	ctx := context.Background()
+
+	var schemaResp datasource.SchemaResponse
+	ds.Schema(ctx, datasource.SchemaRequest{}, &schemaResp)
+	objectType := schemaResp.Schema.Type().TerraformType(ctx).(tftypes.Object)
+
+	configValues := make(map[string]tftypes.Value, len(objectType.AttributeTypes))
+	for name, typ := range objectType.AttributeTypes {
+		configValues[name] = tftypes.NewValue(typ, nil)
+	}
+	for name, value := range values {
+		configValues[name] = value
+	}
+	return tfsdk.Config{Schema: schemaResp.Schema, Raw: tftypes.NewValue(objectType, configValues)}
+}
+
+// testConfigureProvider configures the provider with the given attributes set
+// and every other attribute null.
+func testConfigureProvider(t *testing.T, values map[string]tftypes.Value) provider.ConfigureResponse {
+	t.Helper()
+	ctx := context.Background()
+
+	p := New("test")()
+	var schemaResp provider.SchemaResponse
+	p.Schema(ctx, provider.SchemaRequest{}, &schemaResp)
+	objectType := schemaResp.Schema.Type().TerraformType(ctx).(tftypes.Object)
+
+	configValues := make(map[string]tftypes.Value, len(objectType.AttributeTypes))
+	for name, typ := range objectType.AttributeTypes {
+		configValues[name] = tftypes.NewValue(typ, nil)
+	}
+	for name, value := range values {
+		configValues[name] = value
+	}
+
+	req := provider.ConfigureRequest{Config: tfsdk.Config{Schema: schemaResp.Schema, Raw: tftypes.NewValue(objectType, configValues)}}
+	var resp provider.ConfigureResponse
+	p.Configure(ctx, req, &resp)
+	return resp
+}
+
+// hasDiagnostic reports whether diags contains a diagnostic with the given
+// severity and summary.
+func hasDiagnostic(diags diag.Diagnostics, severity diag.Severity, summary string) bool {
+	for _, d := range diags {
+		if d.Severity() == severity && d.Summary() == summary {
+			return true
+		}
+	}
+	return false
+}
+
+func TestValidateConfigInvalidRecord(t *testing.T) {
+	record := func(value string) map[string]tftypes.Value {
+		return map[string]tftypes.Value{"record": tftypes.NewValue(tftypes.String, value)}
+	}
+	txt := map[string]tftypes.Value{
+		"type":   tftypes.NewValue(tftypes.String, "spf"),
+		"record": tftypes.NewValue(tftypes.String, "v=spf1 bogus"),
+	}
+
+	tests := []struct {
+		name   string
+		source func() datasource.DataSource
+		values map[string]tftypes.Value
+	}{
+		{name: "spf", source: NewSPFDataSource, values: record("v=spf1 bogus")},
+		{name: "dmarc", source: NewDMARCDataSource, values: record("v=DMARC1; p=bogus")},
+		{name: "dkim", source: NewDKIMDataSource, values: record("v=DKIM1; k=dsa; p=abc")},
+		{name: "txt", source: NewTXTDataSource, values: txt},
+		{name: "domain", source: NewDomainDataSource, values: map[string]tftypes.Value{"spf_record": tftypes.NewValue(tftypes.String, "v=spf1 bogus")}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, c := range []struct {
+				provider *providerData
+				wantErr  bool
+			}{
+				// terraform validate runs before the provider is configured
+				{provider: nil, wantErr: true},
+				{provider: &providerData{failOnError: true}, wantErr: true},
+				{provider: &providerData{failOnError: false}, wantErr: false},
+			} {
+				ds := tt.source()
+				if c.provider != nil {
+					var configureResp datasource.ConfigureResponse
+					ds.(datasource.DataSourceWithConfigure).Configure(context.Background(), datasource.ConfigureRequest{ProviderData: c.provider}, &configureResp)
+				}
+				req := datasource.ValidateConfigRequest{Config: testDataSourceConfig(t, ds, tt.values)}
+				var resp datasource.ValidateConfigResponse
+				ds.(datasource.DataSourceWithValidateConfig).ValidateConfig(context.Background(), req, &resp)
+
+				if resp.Diagnostics.HasError() != c.wantErr {
+					t.Errorf("provider %+v: diagnostics = %v, want error %v", c.provider, resp.Diagnostics, c.wantErr)
+				}
+				if !c.wantErr && resp.Diagnostics.WarningsCount() == 0 {
+					t.Errorf("provider %+v: diagnostics = %v, want the error as a warning", c.provider, resp.Diagnostics)
+				}
+			}
+		})
+	}
+}
+
+func TestRecordInput(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "spf.txt")
+	if err := os.WriteFile(file, []byte("v=spf1 -all\r\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		record     types.String
+		recordFile types.String
+		want       types.String
+		wantErr    string
+		wantPath   path.Path
+	}{
+		{name: "record", record: types.StringValue("v=spf1 -all"), recordFile: types.StringNull(), want: types.StringValue("v=spf1 -all")},
+		{name: "unknown record", record: types.StringUnknown(), recordFile: types.StringNull(), want: types.StringUnknown()},
+		{name: "file", record: types.StringNull(), recordFile: types.StringValue(file), want: types.StringValue("v=spf1 -all")},
+		{name: "unknown file", record: types.StringNull(), recordFile: types.StringUnknown(), want: types.StringUnknown()},
+		{
+			name: "neither", record: types.StringNull(), recordFile: types.StringNull(), want: types.StringNull(),
+			wantErr: "Missing Record", wantPath: path.Root("record"),
+		},
+		{
+			name: "both", record: types.StringValue("v=spf1 -all"), recordFile: types.StringValue(file), want: types.StringValue("v=spf1 -all"),
+			wantErr: "Conflicting Record Settings", wantPath: path.Root("record_file"),
+		},
+		{
+			name: "missing file", record: types.StringNull(), recordFile: types.StringValue(filepath.Join(dir, "missing.txt")), want: types.StringNull(),
+			wantErr: "Unable to Read Record File", wantPath: path.Root("record_file"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var diags diag.Diagnostics
+			got := recordInput(tt.record, tt.recordFile, &diags)
+			if !got.Equal(tt.want) {
+				t.Errorf("recordInput() = %s, want %s", got, tt.want)
+			}
+
+			if tt.wantErr == "" {
+				if diags.HasError() {
+					t.Errorf("recordInput() diagnostics = %v", diags)
+				}
+				return
+			}
+			if !diags.HasError() || diags.Errors()[0].Summary() != tt.wantErr {
+				t.Fatalf("recordInput() diagnostics = %v, want %q", diags, tt.wantErr)
+			}
+			if withPath, ok := diags.Errors()[0].(diag.DiagnosticWithPath); !ok || !withPath.Path().Equal(tt.wantPath) {
+				t.Errorf("error is not reported against %s", tt.wantPath)
+			}
+		})
+	}
+}
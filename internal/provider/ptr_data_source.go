@@ -0,0 +1,220 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var (
+	_ datasource.DataSource                   = &PTRDataSource{}
+	_ datasource.DataSourceWithValidateConfig = &PTRDataSource{}
+	_ datasource.DataSourceWithConfigure      = &PTRDataSource{}
+)
+
+func NewPTRDataSource() datasource.DataSource {
+	return &PTRDataSource{}
+}
+
+// PTRDataSource defines the data source implementation.
+type PTRDataSource struct {
+	providerData *providerData
+}
+
+// PTRDataSourceModel describes the data source data model.
+type PTRDataSourceModel struct {
+	IP               types.String `tfsdk:"ip"`
+	Hostname         types.String `tfsdk:"hostname"`
+	ChangeTicket     types.String `tfsdk:"change_ticket"`
+	ResolvedHostname types.String `tfsdk:"resolved_hostname"`
+	Matches          types.Bool   `tfsdk:"matches"`
+}
+
+// reverseDNS holds the result of a forward-confirmed reverse DNS check.
+// Confirmed lists the PTR names that resolve back to the address.
+type reverseDNS struct {
+	Names     []string
+	Confirmed []string
+}
+
+func (d *PTRDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_ptr"
+}
+
+func (d *PTRDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Checks the reverse DNS of a mail server address in live DNS. The PTR record of the address must name a host " +
+			"that resolves back to the same address (forward-confirmed reverse DNS), which many receivers require before accepting mail.",
+
+		Attributes: map[string]schema.Attribute{
+			"ip": schema.StringAttribute{
+				MarkdownDescription: "The IPv4 or IPv6 address of the mail server (e.g., `192.0.2.25`)",
+				Required:            true,
+			},
+			"hostname": schema.StringAttribute{
+				MarkdownDescription: "The host name the PTR record is expected to name (e.g., `mail.example.com`)",
+				Optional:            true,
+			},
+			"change_ticket": changeTicketAttribute(),
+			"resolved_hostname": schema.StringAttribute{
+				MarkdownDescription: "The host name found in the PTR record, without a trailing dot. When there are several, " +
+					"one that resolves back to `ip` is preferred. Null when the address has no PTR record",
+				Computed: true,
+			},
+			"matches": schema.BoolAttribute{
+				MarkdownDescription: "True if a PTR host name resolves back to `ip` and, when `hostname` is set, that host name is `hostname`",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (d *PTRDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	d.providerData = configureProviderData(req, resp)
+}
+
+func (d *PTRDataSource) ValidateConfig(ctx context.Context, req datasource.ValidateConfigRequest, resp *datasource.ValidateConfigResponse) {
+	defer d.providerData.applyWarningSettings(&resp.Diagnostics)
+
+	var data PTRDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Values that are unknown (e.g., depend on another resource) are skipped
+	if !data.IP.IsUnknown() && !data.IP.IsNull() {
+		if _, err := netip.ParseAddr(data.IP.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("ip"),
+				summaryPTRInvalidIPAddress,
+				fmt.Sprintf("%q is not a valid IPv4 or IPv6 address.", data.IP.ValueString()),
+			)
+		}
+	}
+
+	if !data.Hostname.IsUnknown() && !data.Hostname.IsNull() {
+		if err := checkHostname(data.Hostname.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("hostname"),
+				summaryPTRInvalidHostname,
+				fmt.Sprintf("The expected hostname is invalid: %s", err.Error()),
+			)
+		}
+	}
+}
+
+func (d *PTRDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	defer d.providerData.applyWarningSettings(&resp.Diagnostics)
+
+	var data PTRDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data.ChangeTicket = d.providerData.checkChangeTicket(data.ChangeTicket, &resp.Diagnostics)
+
+	ip, err := netip.ParseAddr(data.IP.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			summaryPTRInvalidIPAddress,
+			fmt.Sprintf("%q is not a valid IPv4 or IPv6 address.", data.IP.ValueString()),
+		)
+		return
+	}
+
+	result, err := lookupReverseDNS(ctx, d.providerData.dnsResolver(), ip)
+	if err != nil {
+		resp.Diagnostics.AddError(lookupFailure(
+			summaryPTRLookupFailed,
+			fmt.Sprintf("Unable to check the reverse DNS of %s: %s", ip, err.Error()),
+			err,
+		))
+		return
+	}
+
+	expected := strings.ToLower(strings.TrimSuffix(data.Hostname.ValueString(), "."))
+	data.ResolvedHostname = types.StringNull()
+	data.Matches = types.BoolValue(false)
+
+	switch {
+	case len(result.Names) == 0:
+		resp.Diagnostics.AddWarning(
+			summaryPTRMissing,
+			fmt.Sprintf("%s has no PTR record. Many receivers reject or penalize mail from addresses without reverse DNS; "+
+				"ask the owner of the address block to publish a PTR record naming the mail server.", ip),
+		)
+	case len(result.Confirmed) == 0:
+		data.ResolvedHostname = types.StringValue(result.Names[0])
+		resp.Diagnostics.AddWarning(
+			summaryPTRNotForwardConfirmed,
+			fmt.Sprintf("The PTR record of %s names %s, but no such host resolves back to %s. "+
+				"Publish an A or AAAA record for the PTR host name pointing to the address.", ip, strings.Join(result.Names, ", "), ip),
+		)
+	default:
+		data.ResolvedHostname = types.StringValue(result.Confirmed[0])
+		data.Matches = types.BoolValue(true)
+	}
+
+	if expected != "" && len(result.Confirmed) > 0 {
+		found := false
+		for _, name := range result.Confirmed {
+			if name == expected {
+				data.ResolvedHostname = types.StringValue(name)
+				found = true
+			}
+		}
+		if !found {
+			data.Matches = types.BoolValue(false)
+			resp.Diagnostics.AddWarning(
+				summaryPTRUnexpectedHostname,
+				fmt.Sprintf("Expected %s to have forward-confirmed reverse DNS for %s, but its PTR record names %s.",
+					ip, expected, strings.Join(result.Names, ", ")),
+			)
+		}
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// lookupReverseDNS looks up the PTR names of ip and resolves each one to
+// find those pointing back to it. Names are lowercased without a trailing
+// dot. Missing PTR or address records are not errors.
+func lookupReverseDNS(ctx context.Context, resolver dnsResolver, ip netip.Addr) (*reverseDNS, error) {
+	ip = ip.Unmap()
+	names, err := resolver.LookupAddr(ctx, ip.String())
+	if err != nil && !isNotFound(err) {
+		return nil, err
+	}
+
+	result := &reverseDNS{}
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSuffix(name, "."))
+		result.Names = append(result.Names, name)
+
+		addrs, err := resolver.LookupIPAddr(ctx, name)
+		if err != nil {
+			if isNotFound(err) {
+				continue
+			}
+			return nil, fmt.Errorf("resolving %s: %w", name, err)
+		}
+		for _, addr := range addrs {
+			if a, ok := netip.AddrFromSlice(addr.IP); ok && a.Unmap() == ip {
+				result.Confirmed = append(result.Confirmed, name)
+				break
+			}
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,75 @@
+package provider
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"reflect"
+	"testing"
+)
+
+func TestLookupReverseDNS(t *testing.T) {
+	resolver := &fakeResolver{
+		ptr: map[string][]string{
+			"192.0.2.25":  {"Mail.Example.com."},
+			"192.0.2.26":  {"stale.example.com.", "mx2.example.com."},
+			"192.0.2.27":  {"unresolved.example.com."},
+			"2001:db8::1": {"mx6.example.com."},
+		},
+		addr: map[string][]net.IPAddr{
+			"mail.example.com":  {{IP: net.ParseIP("192.0.2.25")}},
+			"stale.example.com": {{IP: net.ParseIP("192.0.2.99")}},
+			"mx2.example.com":   {{IP: net.ParseIP("192.0.2.26")}},
+			"mx6.example.com":   {{IP: net.ParseIP("2001:db8::1")}},
+		},
+	}
+
+	tests := []struct {
+		name string
+		ip   string
+		want *reverseDNS
+	}{
+		{
+			name: "confirmed",
+			ip:   "192.0.2.25",
+			want: &reverseDNS{Names: []string{"mail.example.com"}, Confirmed: []string{"mail.example.com"}},
+		},
+		{
+			name: "mapped address",
+			ip:   "::ffff:192.0.2.25",
+			want: &reverseDNS{Names: []string{"mail.example.com"}, Confirmed: []string{"mail.example.com"}},
+		},
+		{
+			name: "one of several confirmed",
+			ip:   "192.0.2.26",
+			want: &reverseDNS{Names: []string{"stale.example.com", "mx2.example.com"}, Confirmed: []string{"mx2.example.com"}},
+		},
+		{
+			name: "not confirmed",
+			ip:   "192.0.2.27",
+			want: &reverseDNS{Names: []string{"unresolved.example.com"}},
+		},
+		{
+			name: "ipv6",
+			ip:   "2001:db8::1",
+			want: &reverseDNS{Names: []string{"mx6.example.com"}, Confirmed: []string{"mx6.example.com"}},
+		},
+		{
+			name: "no ptr",
+			ip:   "192.0.2.1",
+			want: &reverseDNS{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := lookupReverseDNS(context.Background(), resolver, netip.MustParseAddr(tt.ip))
+			if err != nil {
+				t.Fatalf("lookupReverseDNS() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("lookupReverseDNS() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
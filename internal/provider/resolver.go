@@ -0,0 +1,358 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+// dnsResolver is the subset of DNS lookups used by live validation. It matches
+// the method set of *net.Resolver so the system resolver can be used directly.
+type dnsResolver interface {
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+	LookupMX(ctx context.Context, name string) ([]*net.MX, error)
+	LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error)
+	LookupIP(ctx context.Context, network, host string) ([]net.IP, error)
+	LookupAddr(ctx context.Context, addr string) ([]string, error)
+}
+
+// Defaults for the dns_timeout and dns_retries provider attributes.
+const (
+	defaultDNSTimeout = 5 * time.Second
+	defaultDNSRetries = 2
+)
+
+// Ensure resolvers satisfy the dnsResolver interface.
+var (
+	_ dnsResolver = &net.Resolver{}
+	_ dnsResolver = &dohResolver{}
+	_ dnsResolver = &retryingResolver{}
+	_ dnsResolver = &txtCachingResolver{}
+)
+
+// retryingResolver bounds every lookup of the wrapped resolver by timeout and
+// retries lookups that time out or fail temporarily, so that a slow or
+// unreachable server cannot stall a plan.
+type retryingResolver struct {
+	resolver dnsResolver
+	timeout  time.Duration
+	retries  int
+}
+
+func (r *retryingResolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	return withRetries(ctx, r, func(ctx context.Context) ([]string, error) { return r.resolver.LookupTXT(ctx, name) })
+}
+
+func (r *retryingResolver) LookupMX(ctx context.Context, name string) ([]*net.MX, error) {
+	return withRetries(ctx, r, func(ctx context.Context) ([]*net.MX, error) { return r.resolver.LookupMX(ctx, name) })
+}
+
+func (r *retryingResolver) LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error) {
+	return withRetries(ctx, r, func(ctx context.Context) ([]net.IPAddr, error) { return r.resolver.LookupIPAddr(ctx, host) })
+}
+
+func (r *retryingResolver) LookupIP(ctx context.Context, network, host string) ([]net.IP, error) {
+	return withRetries(ctx, r, func(ctx context.Context) ([]net.IP, error) { return r.resolver.LookupIP(ctx, network, host) })
+}
+
+func (r *retryingResolver) LookupAddr(ctx context.Context, addr string) ([]string, error) {
+	return withRetries(ctx, r, func(ctx context.Context) ([]string, error) { return r.resolver.LookupAddr(ctx, addr) })
+}
+
+// txtCachingResolver remembers the TXT answers of the wrapped resolver, so
+// that an SPF record reached several times while reading one data source,
+// such as a redirect target that is also walked for lookup counts, is only
+// fetched once. It is not safe for concurrent use.
+type txtCachingResolver struct {
+	dnsResolver
+	txt map[string]txtAnswer
+}
+
+// txtAnswer is the cached result of a TXT lookup.
+type txtAnswer struct {
+	records []string
+	err     error
+}
+
+func newTXTCachingResolver(resolver dnsResolver) *txtCachingResolver {
+	return &txtCachingResolver{dnsResolver: resolver, txt: make(map[string]txtAnswer)}
+}
+
+func (r *txtCachingResolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	key := strings.ToLower(strings.TrimSuffix(name, "."))
+	if answer, ok := r.txt[key]; ok {
+		return answer.records, answer.err
+	}
+	records, err := r.dnsResolver.LookupTXT(ctx, name)
+	r.txt[key] = txtAnswer{records: records, err: err}
+	return records, err
+}
+
+// withRetries runs lookup with a per-attempt timeout until it succeeds, fails
+// permanently, or runs out of retries.
+func withRetries[T any](ctx context.Context, r *retryingResolver, lookup func(context.Context) (T, error)) (T, error) {
+	var result T
+	var err error
+	for attempt := 0; attempt <= r.retries; attempt++ {
+		attemptCtx, cancel := context.WithTimeout(ctx, r.timeout)
+		result, err = lookup(attemptCtx)
+		cancel()
+		if err == nil || !isTemporaryDNSError(err) || ctx.Err() != nil {
+			return result, err
+		}
+	}
+	return result, fmt.Errorf("%w (gave up after %d attempts)", err, r.retries+1)
+}
+
+// isTemporaryDNSError reports whether err is a timeout or another transient
+// failure that may succeed when retried, which SPF and DMARC call a
+// temperror.
+func isTemporaryDNSError(err error) bool {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return dnsErr.IsTimeout || dnsErr.IsTemporary
+	}
+	return errors.Is(err, context.DeadlineExceeded)
+}
+
+// newServerResolver returns a resolver that sends queries to the given
+// host:port servers instead of those of the system configuration. Servers
+// are used in turn, so a query that times out is retried on the next one.
+// With protocol "tcp" every query uses TCP; otherwise queries use UDP and
+// fall back to TCP for truncated responses.
+func newServerResolver(servers []string, protocol string) *net.Resolver {
+	var next atomic.Uint32
+	dialer := &net.Dialer{}
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			if protocol == "tcp" {
+				network = "tcp"
+			}
+			server := servers[int(next.Add(1)-1)%len(servers)]
+			return dialer.DialContext(ctx, network, server)
+		},
+	}
+}
+
+// normalizeDNSServer validates a dns_servers entry and returns it as
+// host:port. A bare IP address uses port 53.
+func normalizeDNSServer(server string) (string, error) {
+	host, port, err := net.SplitHostPort(server)
+	if err != nil {
+		if _, err := netip.ParseAddr(server); err == nil {
+			return net.JoinHostPort(server, "53"), nil
+		}
+		return "", fmt.Errorf("%q must be an IP address or host:port", server)
+	}
+
+	if n, err := strconv.ParseUint(port, 10, 16); err != nil || n == 0 {
+		return "", fmt.Errorf("%q has an invalid port %q", server, port)
+	}
+	if _, err := netip.ParseAddr(host); err != nil {
+		if err := checkHostname(host); err != nil {
+			return "", fmt.Errorf("%q has an invalid host: %w", server, err)
+		}
+	}
+	return net.JoinHostPort(host, port), nil
+}
+
+// dohResolver performs DNS lookups over HTTPS using the RFC 8484 wire format.
+// When authToken is set it is sent as a bearer token, which allows querying
+// internal resolvers that require authentication.
+type dohResolver struct {
+	endpoint  string
+	authToken string
+	client    *http.Client
+}
+
+func (r *dohResolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	answers, err := r.query(ctx, name, dns.TypeTXT)
+	if err != nil {
+		return nil, err
+	}
+
+	var txts []string
+	for _, rr := range answers {
+		if txt, ok := rr.(*dns.TXT); ok {
+			// Multiple strings within one TXT record are concatenated, as net.LookupTXT does
+			txts = append(txts, strings.Join(txt.Txt, ""))
+		}
+	}
+	return dohFound(r, name, txts)
+}
+
+func (r *dohResolver) LookupMX(ctx context.Context, name string) ([]*net.MX, error) {
+	answers, err := r.query(ctx, name, dns.TypeMX)
+	if err != nil {
+		return nil, err
+	}
+
+	var mxs []*net.MX
+	for _, rr := range answers {
+		if mx, ok := rr.(*dns.MX); ok {
+			mxs = append(mxs, &net.MX{Host: mx.Mx, Pref: mx.Preference})
+		}
+	}
+	return dohFound(r, name, mxs)
+}
+
+func (r *dohResolver) LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error) {
+	ips, err := r.LookupIP(ctx, "ip", host)
+	if err != nil {
+		return nil, err
+	}
+
+	addrs := make([]net.IPAddr, len(ips))
+	for i, ip := range ips {
+		addrs[i] = net.IPAddr{IP: ip}
+	}
+	return addrs, nil
+}
+
+// LookupIP looks up the A records of host for network "ip4", its AAAA records
+// for "ip6", and both for "ip", as net.Resolver.LookupIP does.
+func (r *dohResolver) LookupIP(ctx context.Context, network, host string) ([]net.IP, error) {
+	var qtypes []uint16
+	switch network {
+	case "ip":
+		qtypes = []uint16{dns.TypeA, dns.TypeAAAA}
+	case "ip4":
+		qtypes = []uint16{dns.TypeA}
+	case "ip6":
+		qtypes = []uint16{dns.TypeAAAA}
+	default:
+		return nil, &net.DNSError{Err: "unsupported network " + network, Name: host}
+	}
+
+	var ips []net.IP
+	for _, qtype := range qtypes {
+		answers, err := r.query(ctx, host, qtype)
+		if err != nil {
+			if dnsErr, ok := err.(*net.DNSError); ok && dnsErr.IsNotFound {
+				continue
+			}
+			return nil, err
+		}
+		for _, rr := range answers {
+			switch v := rr.(type) {
+			case *dns.A:
+				ips = append(ips, v.A)
+			case *dns.AAAA:
+				ips = append(ips, v.AAAA)
+			}
+		}
+	}
+	return dohFound(r, host, ips)
+}
+
+func (r *dohResolver) LookupAddr(ctx context.Context, addr string) ([]string, error) {
+	name, err := dns.ReverseAddr(addr)
+	if err != nil {
+		return nil, &net.DNSError{Err: "unrecognized address", Name: addr}
+	}
+
+	answers, err := r.query(ctx, name, dns.TypePTR)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, rr := range answers {
+		if ptr, ok := rr.(*dns.PTR); ok {
+			names = append(names, ptr.Ptr)
+		}
+	}
+	return dohFound(r, addr, names)
+}
+
+// query sends a single DNS question to the DoH endpoint and returns the
+// answer section. NXDOMAIN is reported as a not-found *net.DNSError.
+func (r *dohResolver) query(ctx context.Context, name string, qtype uint16) ([]dns.RR, error) {
+	msg := new(dns.Msg)
+	msg.SetQuestion(dns.Fqdn(name), qtype)
+	// RFC 8484 section 4.1: use an ID of 0 to maximize cache friendliness
+	msg.Id = 0
+
+	body, err := msg.Pack()
+	if err != nil {
+		return nil, &net.DNSError{Err: err.Error(), Name: name}
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, r.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, &net.DNSError{Err: err.Error(), Name: name, Server: r.endpoint}
+	}
+	httpReq.Header.Set("Content-Type", "application/dns-message")
+	httpReq.Header.Set("Accept", "application/dns-message")
+	if r.authToken != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+r.authToken)
+	}
+
+	httpResp, err := r.client.Do(httpReq)
+	if err != nil {
+		return nil, &net.DNSError{Err: err.Error(), Name: name, Server: r.endpoint, IsTemporary: true}
+	}
+	defer httpResp.Body.Close()
+
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, &net.DNSError{
+			Err:         fmt.Sprintf("DoH server returned HTTP %d", httpResp.StatusCode),
+			Name:        name,
+			Server:      r.endpoint,
+			IsTemporary: httpResp.StatusCode >= 500,
+		}
+	}
+
+	respBody, err := io.ReadAll(io.LimitReader(httpResp.Body, dns.MaxMsgSize))
+	if err != nil {
+		return nil, &net.DNSError{Err: err.Error(), Name: name, Server: r.endpoint, IsTemporary: true}
+	}
+
+	reply := new(dns.Msg)
+	if err := reply.Unpack(respBody); err != nil {
+		return nil, &net.DNSError{Err: fmt.Sprintf("invalid DNS response: %s", err), Name: name, Server: r.endpoint}
+	}
+
+	switch reply.Rcode {
+	case dns.RcodeSuccess:
+		return reply.Answer, nil
+	case dns.RcodeNameError:
+		return nil, &net.DNSError{Err: "no such host", Name: name, Server: r.endpoint, IsNotFound: true}
+	case dns.RcodeServerFailure:
+		return nil, &net.DNSError{Err: "server misbehaving", Name: name, Server: r.endpoint, IsTemporary: true}
+	default:
+		return nil, &net.DNSError{Err: dns.RcodeToString[reply.Rcode], Name: name, Server: r.endpoint}
+	}
+}
+
+// dohFound returns a not-found *net.DNSError when no records of the requested
+// type were returned, matching the behavior of the system resolver.
+func dohFound[T any](r *dohResolver, name string, records []T) ([]T, error) {
+	if len(records) == 0 {
+		return nil, &net.DNSError{Err: "no such host", Name: name, Server: r.endpoint, IsNotFound: true}
+	}
+	return records, nil
+}
+
+// lookupFailure returns the summary and detail of a diagnostic for a failed
+// lookup. Temporary failures get a distinct summary so that transient DNS
+// problems can be told apart from misconfigured records.
+func lookupFailure(summary, detail string, err error) (string, string) {
+	if !isTemporaryDNSError(err) {
+		return summary, detail
+	}
+	return summaryDNSTemporaryFailure, detail + "\n\nThis is a transient DNS failure (temperror) rather than a problem with the records, so retrying may succeed. " +
+		"If it persists, check that the DNS servers are reachable or raise dns_timeout or dns_retries on the provider."
+}
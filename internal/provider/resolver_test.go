@@ -0,0 +1,350 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/miekg/dns"
+)
+
+// startTestDNSServer serves a single TXT record for example.com on the given
+// network and returns the server's address.
+func startTestDNSServer(t *testing.T, network string) string {
+	t.Helper()
+
+	handler := dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+		reply := new(dns.Msg)
+		reply.SetReply(r)
+		if r.Question[0].Name == "example.com." && r.Question[0].Qtype == dns.TypeTXT {
+			reply.Answer = append(reply.Answer, &dns.TXT{
+				Hdr: dns.RR_Header{Name: "example.com.", Rrtype: dns.TypeTXT, Class: dns.ClassINET, Ttl: 300},
+				Txt: []string{"v=spf1 -all"},
+			})
+		} else {
+			reply.Rcode = dns.RcodeNameError
+		}
+		_ = w.WriteMsg(reply)
+	})
+
+	server := &dns.Server{Handler: handler}
+	if network == "udp" {
+		conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("net.ListenPacket() error = %v", err)
+		}
+		server.PacketConn = conn
+	} else {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("net.Listen() error = %v", err)
+		}
+		server.Listener = listener
+	}
+
+	started := make(chan struct{})
+	server.NotifyStartedFunc = func() { close(started) }
+	go func() { _ = server.ActivateAndServe() }()
+	<-started
+	t.Cleanup(func() { _ = server.Shutdown() })
+
+	if server.PacketConn != nil {
+		return server.PacketConn.LocalAddr().String()
+	}
+	return server.Listener.Addr().String()
+}
+
+func TestServerResolver(t *testing.T) {
+	for _, protocol := range []string{"udp", "tcp"} {
+		t.Run(protocol, func(t *testing.T) {
+			addr := startTestDNSServer(t, protocol)
+			resolver := newServerResolver([]string{addr}, protocol)
+
+			got, err := resolver.LookupTXT(context.Background(), "example.com")
+			if err != nil {
+				t.Fatalf("LookupTXT() error = %v", err)
+			}
+			if want := []string{"v=spf1 -all"}; !reflect.DeepEqual(got, want) {
+				t.Errorf("LookupTXT() = %v, want %v", got, want)
+			}
+
+			if _, err := resolver.LookupTXT(context.Background(), "missing.example.com"); !isNotFound(err) {
+				t.Errorf("LookupTXT() error = %v, want not found", err)
+			}
+		})
+	}
+}
+
+func TestNormalizeDNSServer(t *testing.T) {
+	tests := []struct {
+		server  string
+		want    string
+		wantErr bool
+	}{
+		{server: "10.0.0.53:53", want: "10.0.0.53:53"},
+		{server: "10.0.0.53", want: "10.0.0.53:53"},
+		{server: "2001:db8::53", want: "[2001:db8::53]:53"},
+		{server: "[2001:db8::53]:5353", want: "[2001:db8::53]:5353"},
+		{server: "ns1.example.com:53", want: "ns1.example.com:53"},
+		{server: "ns1.example.com", wantErr: true},
+		{server: "10.0.0.53:0", wantErr: true},
+		{server: "10.0.0.53:65536", wantErr: true},
+		{server: "10.0.0.53:dns", wantErr: true},
+		{server: ":53", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.server, func(t *testing.T) {
+			got, err := normalizeDNSServer(tt.server)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("normalizeDNSServer() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("normalizeDNSServer() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// flakyResolver fails the first failures TXT lookups with err before
+// answering from fakeResolver.
+type flakyResolver struct {
+	fakeResolver
+	failures int
+	err      error
+	attempts int
+}
+
+func (r *flakyResolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	r.attempts++
+	if _, ok := ctx.Deadline(); !ok {
+		return nil, errors.New("lookup has no deadline")
+	}
+	if r.attempts <= r.failures {
+		return nil, r.err
+	}
+	return r.fakeResolver.LookupTXT(ctx, name)
+}
+
+func TestRetryingResolver(t *testing.T) {
+	timeout := &net.DNSError{Err: "i/o timeout", Name: "example.com", IsTimeout: true}
+	refused := &net.DNSError{Err: "connection refused", Name: "example.com"}
+
+	tests := []struct {
+		name         string
+		failures     int
+		err          error
+		wantAttempts int
+		wantErr      bool
+		wantTemp     bool
+	}{
+		{name: "success", wantAttempts: 1},
+		{name: "retried timeout", failures: 2, err: timeout, wantAttempts: 3},
+		{name: "persistent timeout", failures: 5, err: timeout, wantAttempts: 3, wantErr: true, wantTemp: true},
+		{name: "permanent failure", failures: 5, err: refused, wantAttempts: 1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flaky := &flakyResolver{
+				fakeResolver: fakeResolver{txt: map[string][]string{"example.com": {"v=spf1 -all"}}},
+				failures:     tt.failures,
+				err:          tt.err,
+			}
+			resolver := &retryingResolver{resolver: flaky, timeout: time.Second, retries: 2}
+
+			_, err := resolver.LookupTXT(context.Background(), "example.com")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LookupTXT() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if flaky.attempts != tt.wantAttempts {
+				t.Errorf("attempts = %d, want %d", flaky.attempts, tt.wantAttempts)
+			}
+			if got := isTemporaryDNSError(err); got != tt.wantTemp {
+				t.Errorf("isTemporaryDNSError() = %v, want %v", got, tt.wantTemp)
+			}
+		})
+	}
+}
+
+func TestLookupFailure(t *testing.T) {
+	summary, detail := lookupFailure("MX Lookup Failed", "Unable to look up the MX records.", &net.DNSError{Err: "no such host", IsNotFound: true})
+	if summary != "MX Lookup Failed" || detail != "Unable to look up the MX records." {
+		t.Errorf("lookupFailure() = %q, %q, want the inputs unchanged", summary, detail)
+	}
+
+	summary, detail = lookupFailure("MX Lookup Failed", "Unable to look up the MX records.", context.DeadlineExceeded)
+	if summary != "Temporary DNS Failure" || !strings.Contains(detail, "temperror") {
+		t.Errorf("lookupFailure() = %q, %q, want a temporary failure", summary, detail)
+	}
+}
+
+func TestDoHResolver(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/dns-message" || r.Header.Get("Accept") != "application/dns-message" {
+			http.Error(w, "not an RFC 8484 request", http.StatusBadRequest)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		query := new(dns.Msg)
+		if err := query.Unpack(body); err != nil || query.Id != 0 || len(query.Question) != 1 {
+			http.Error(w, "malformed DNS query", http.StatusBadRequest)
+			return
+		}
+
+		q := query.Question[0]
+		reply := new(dns.Msg)
+		reply.SetReply(query)
+		switch {
+		case q.Name == "unavailable.example.com.":
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		case q.Name == "example.com." && q.Qtype == dns.TypeTXT:
+			reply.Answer = append(reply.Answer, &dns.TXT{
+				Hdr: dns.RR_Header{Name: q.Name, Rrtype: dns.TypeTXT, Class: dns.ClassINET, Ttl: 300},
+				Txt: []string{"v=spf1 ", "-all"},
+			})
+		case q.Name == "example.com." && q.Qtype == dns.TypeA:
+			reply.Answer = append(reply.Answer, &dns.A{
+				Hdr: dns.RR_Header{Name: q.Name, Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: 300},
+				A:   net.ParseIP("192.0.2.1"),
+			})
+		case q.Name == "example.com." && q.Qtype == dns.TypeAAAA:
+			reply.Answer = append(reply.Answer, &dns.AAAA{
+				Hdr:  dns.RR_Header{Name: q.Name, Rrtype: dns.TypeAAAA, Class: dns.ClassINET, Ttl: 300},
+				AAAA: net.ParseIP("2001:db8::1"),
+			})
+		case q.Name == "servfail.example.com.":
+			reply.Rcode = dns.RcodeServerFailure
+		default:
+			reply.Rcode = dns.RcodeNameError
+		}
+		packed, err := reply.Pack()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/dns-message")
+		_, _ = w.Write(packed)
+	}))
+	t.Cleanup(server.Close)
+
+	tests := []struct {
+		name         string
+		token        string
+		host         string
+		want         []string
+		wantNotFound bool
+		wantTemp     bool
+		wantErr      bool
+	}{
+		{name: "answer", token: "secret", host: "example.com", want: []string{"v=spf1 -all"}},
+		{name: "nxdomain", token: "secret", host: "missing.example.com", wantErr: true, wantNotFound: true},
+		{name: "servfail", token: "secret", host: "servfail.example.com", wantErr: true, wantTemp: true},
+		{name: "server error", token: "secret", host: "unavailable.example.com", wantErr: true, wantTemp: true},
+		{name: "missing token", host: "example.com", wantErr: true},
+		{name: "wrong token", token: "other", host: "example.com", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver := &dohResolver{endpoint: server.URL, authToken: tt.token, client: server.Client()}
+			got, err := resolver.LookupTXT(context.Background(), tt.host)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LookupTXT() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("LookupTXT() = %q, want %q", got, tt.want)
+			}
+			if got := isNotFound(err); got != tt.wantNotFound {
+				t.Errorf("isNotFound() = %v, want %v", got, tt.wantNotFound)
+			}
+			if got := isTemporaryDNSError(err); got != tt.wantTemp {
+				t.Errorf("isTemporaryDNSError() = %v, want %v", got, tt.wantTemp)
+			}
+		})
+	}
+
+	resolver := &dohResolver{endpoint: server.URL, authToken: "secret", client: server.Client()}
+	for network, want := range map[string]int{"ip": 2, "ip4": 1, "ip6": 1} {
+		ips, err := resolver.LookupIP(context.Background(), network, "example.com")
+		if err != nil || len(ips) != want {
+			t.Errorf("LookupIP(%q) = %v, %v, want %d addresses", network, ips, err, want)
+		}
+	}
+}
+
+func TestConfigureDoHEndpoint(t *testing.T) {
+	str := func(s string) tftypes.Value { return tftypes.NewValue(tftypes.String, s) }
+
+	tests := []struct {
+		name         string
+		values       map[string]tftypes.Value
+		wantEndpoint string
+		wantToken    string
+		wantErr      bool
+	}{
+		{
+			name:         "doh_endpoint",
+			values:       map[string]tftypes.Value{"doh_endpoint": str("https://dns.example.com/dns-query"), "dns_auth_token": str("secret")},
+			wantEndpoint: "https://dns.example.com/dns-query",
+			wantToken:    "secret",
+		},
+		{
+			name:         "deprecated dns_api_url",
+			values:       map[string]tftypes.Value{"dns_api_url": str("https://dns.example.com/dns-query")},
+			wantEndpoint: "https://dns.example.com/dns-query",
+		},
+		{
+			name:    "both set",
+			values:  map[string]tftypes.Value{"doh_endpoint": str("https://a.example.com/dns-query"), "dns_api_url": str("https://b.example.com/dns-query")},
+			wantErr: true,
+		},
+		{
+			name:    "plain http",
+			values:  map[string]tftypes.Value{"doh_endpoint": str("http://dns.example.com/dns-query")},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := testConfigureProvider(t, tt.values)
+			if resp.Diagnostics.HasError() != tt.wantErr {
+				t.Fatalf("Configure() diagnostics = %v, wantErr %v", resp.Diagnostics, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			retrying, ok := resp.DataSourceData.(*providerData).resolver.(*retryingResolver)
+			if !ok {
+				t.Fatalf("resolver = %T, want *retryingResolver", resp.DataSourceData.(*providerData).resolver)
+			}
+			doh, ok := retrying.resolver.(*dohResolver)
+			if !ok {
+				t.Fatalf("wrapped resolver = %T, want *dohResolver", retrying.resolver)
+			}
+			if doh.endpoint != tt.wantEndpoint || doh.authToken != tt.wantToken {
+				t.Errorf("dohResolver = %q with token %q, want %q with token %q", doh.endpoint, doh.authToken, tt.wantEndpoint, tt.wantToken)
+			}
+		})
+	}
+}
@@ -2,11 +2,21 @@ package provider
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net"
+	"net/netip"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/wttw/spf"
 )
@@ -15,6 +25,7 @@ import (
 var (
 	_ datasource.DataSource                   = &SPFDataSource{}
 	_ datasource.DataSourceWithValidateConfig = &SPFDataSource{}
+	_ datasource.DataSourceWithConfigure      = &SPFDataSource{}
 )
 
 func NewSPFDataSource() datasource.DataSource {
@@ -22,22 +33,99 @@ func NewSPFDataSource() datasource.DataSource {
 }
 
 // SPFDataSource defines the data source implementation.
-type SPFDataSource struct{}
+type SPFDataSource struct {
+	providerData *providerData
+}
 
 // SPFDataSourceModel describes the data source data model.
 type SPFDataSourceModel struct {
-	Record         types.String `tfsdk:"record"`
-	Mechanisms     types.List   `tfsdk:"mechanisms"`
-	Redirect       types.String `tfsdk:"redirect"`
-	DNSLookupCount types.Int64  `tfsdk:"dns_lookup_count"`
+	Record            types.String `tfsdk:"record"`
+	RecordFile        types.String `tfsdk:"record_file"`
+	Domain            types.String `tfsdk:"domain"`
+	Lookup            types.Bool   `tfsdk:"lookup"`
+	ParsedJSON        types.String `tfsdk:"parsed_json"`
+	ChangeTicket      types.String `tfsdk:"change_ticket"`
+	Valid             types.Bool   `tfsdk:"valid"`
+	Warnings          types.List   `tfsdk:"warnings"`
+	Resolve           types.Bool   `tfsdk:"resolve"`
+	OrderingHints     types.Bool   `tfsdk:"ordering_hints"`
+	MaxRedirectDepth  types.Int64  `tfsdk:"max_redirect_depth"`
+	MaxDepth          types.Int64  `tfsdk:"max_depth"`
+	Mechanisms        types.List   `tfsdk:"mechanisms"`
+	MechanismCounts   types.Map    `tfsdk:"mechanism_counts"`
+	Redirect          types.String `tfsdk:"redirect"`
+	RedirectTarget    types.String `tfsdk:"redirect_target_record"`
+	DNSLookupCount    types.Int64  `tfsdk:"dns_lookup_count"`
+	NormalizedRecord  types.String `tfsdk:"normalized_record"`
+	VoidLookupCount   types.Int64  `tfsdk:"void_lookup_count"`
+	CombinedLookups   types.Int64  `tfsdk:"combined_lookup_count"`
+	MaxIncludeDepth   types.Int64  `tfsdk:"max_include_depth"`
+	LookupBreakdown   types.List   `tfsdk:"lookup_breakdown"`
+	OptimizationHints types.List   `tfsdk:"optimization_hints"`
 }
 
 // mechanismObjectType defines the Terraform object type for SPF mechanisms.
 var mechanismObjectType = types.ObjectType{
 	AttrTypes: map[string]attr.Type{
-		"qualifier": types.StringType,
-		"type":      types.StringType,
-		"value":     types.StringType,
+		"qualifier":          types.StringType,
+		"type":               types.StringType,
+		"value":              types.StringType,
+		"explicit_qualifier": types.BoolType,
+		"resolved_count":     types.Int64Type,
+		"raw":                types.StringType,
+		"ip4_cidr":           types.Int64Type,
+		"ip6_cidr":           types.Int64Type,
+	},
+}
+
+// mechanismNestedObject returns the schema of a parsed SPF mechanism, shared
+// by every data source that exposes mechanisms.
+func mechanismNestedObject() schema.NestedAttributeObject {
+	return schema.NestedAttributeObject{
+		Attributes: map[string]schema.Attribute{
+			"qualifier": schema.StringAttribute{
+				MarkdownDescription: "The qualifier (+ for pass, - for fail, ~ for softfail, ? for neutral)",
+				Computed:            true,
+			},
+			"type": schema.StringAttribute{
+				MarkdownDescription: "The mechanism type (all, include, a, mx, ip4, ip6, exists, ptr)",
+				Computed:            true,
+			},
+			"value": schema.StringAttribute{
+				MarkdownDescription: "The mechanism value (domain, IP range, etc.)",
+				Computed:            true,
+			},
+			"explicit_qualifier": schema.BoolAttribute{
+				MarkdownDescription: "True if the qualifier was written in the record, false if the default `+` was implied. Null if the term as written could not be matched to the parsed mechanism",
+				Computed:            true,
+			},
+			"resolved_count": schema.Int64Attribute{
+				MarkdownDescription: "Number of records the mechanism resolved to (MX records for `mx`, A and AAAA records for `a`, A records for `exists`). Only set when `resolve` is `true`.",
+				Computed:            true,
+			},
+			"raw": schema.StringAttribute{
+				MarkdownDescription: "The mechanism as the parser understood it, including mechanisms the provider does not break down into `type` and `value`",
+				Computed:            true,
+			},
+			"ip4_cidr": schema.Int64Attribute{
+				MarkdownDescription: "The IPv4 prefix length written on an `a` or `mx` mechanism (e.g., `24` for `a:example.com/24//64`). Null when none is written",
+				Computed:            true,
+			},
+			"ip6_cidr": schema.Int64Attribute{
+				MarkdownDescription: "The IPv6 prefix length written on an `a` or `mx` mechanism (e.g., `64` for `a:example.com/24//64`). Null when none is written",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+// lookupBreakdownObjectType defines the Terraform object type for the
+// per-term DNS lookup cost breakdown.
+var lookupBreakdownObjectType = types.ObjectType{
+	AttrTypes: map[string]attr.Type{
+		"mechanism":      types.StringType,
+		"costs_lookup":   types.BoolType,
+		"nested_lookups": types.Int64Type,
 	},
 }
 
@@ -52,42 +140,126 @@ func (d *SPFDataSource) Schema(ctx context.Context, req datasource.SchemaRequest
 
 		Attributes: map[string]schema.Attribute{
 			"record": schema.StringAttribute{
-				MarkdownDescription: "The SPF TXT record content to validate (e.g., `v=spf1 include:_spf.google.com ~all`)",
-				Required:            true,
+				MarkdownDescription: "The SPF TXT record content to validate (e.g., `v=spf1 include:_spf.google.com ~all`). " +
+					"When `record_file` or `lookup` is set instead, this is the content of the file or the published record",
+				Optional: true,
+				Computed: true,
+			},
+			"record_file": recordFileAttribute(),
+			"domain": schema.StringAttribute{
+				MarkdownDescription: "The domain whose SPF record is looked up when `lookup` is `true` (e.g., `example.com`)",
+				Optional:            true,
+			},
+			"lookup": schema.BoolAttribute{
+				MarkdownDescription: "Set to `true` to validate the SPF record currently published at `domain` in live DNS instead of `record` or `record_file`. " +
+					"The record found is returned in `record`. Defaults to `false`.",
+				Optional: true,
+			},
+			"change_ticket": changeTicketAttribute(),
+			"valid":         validAttribute(),
+			"warnings":      warningsAttribute(),
+			"parsed_json": schema.StringAttribute{
+				MarkdownDescription: "The parsed record as a JSON object, for use with `jsondecode`, with `mechanisms` (each with `qualifier`, `type`, and `value`), " +
+					"`redirect`, and `exp` keys. Keys are sorted so the value only changes with the record. Matches `parsed_json` of `emaildns_txt`",
+				Computed: true,
+			},
+			"resolve": schema.BoolAttribute{
+				MarkdownDescription: "Set to `true` to perform live DNS lookups for checks that cannot be done statically, such as the per-mechanism MX record limit and the void lookup limit. Defaults to `false`.",
+				Optional:            true,
+			},
+			"ordering_hints": schema.BoolAttribute{
+				MarkdownDescription: "Set to `true` to add mechanism ordering suggestions to `optimization_hints`. " +
+					"The provider cannot know which mechanisms match most often, so these hints assume local `ip4`/`ip6` ranges match more " +
+					"senders than third-party includes. Defaults to `false`.",
+				Optional: true,
+			},
+			"max_redirect_depth": schema.Int64Attribute{
+				MarkdownDescription: "Maximum number of `redirect=` hops to follow when `resolve` is `true`. Defaults to `10`.",
+				Optional:            true,
+			},
+			"max_depth": schema.Int64Attribute{
+				MarkdownDescription: "Maximum allowed nesting depth of `include` mechanisms when `resolve` is `true`. " +
+					"The plan fails if `max_include_depth` exceeds this value. Not enforced when unset.",
+				Optional: true,
 			},
 			"mechanisms": schema.ListNestedAttribute{
 				MarkdownDescription: "List of parsed SPF mechanisms",
 				Computed:            true,
+				NestedObject:        mechanismNestedObject(),
+			},
+			"mechanism_counts": schema.MapAttribute{
+				MarkdownDescription: "Number of mechanisms of each type in the record, keyed by type (e.g., `{ include = 2, ip4 = 1, all = 1 }`). " +
+					"Types that do not appear are left out. Modifiers such as `redirect` are not counted",
+				Computed:    true,
+				ElementType: types.Int64Type,
+			},
+			"redirect": schema.StringAttribute{
+				MarkdownDescription: "The redirect modifier value, if present",
+				Computed:            true,
+			},
+			"redirect_target_record": schema.StringAttribute{
+				MarkdownDescription: "The SPF record published at the redirect target. Only set when `resolve` is `true` and the record has a redirect modifier.",
+				Computed:            true,
+			},
+			"dns_lookup_count": schema.Int64Attribute{
+				MarkdownDescription: "Number of mechanisms that require DNS lookups (SPF allows max 10)",
+				Computed:            true,
+			},
+			"normalized_record": schema.StringAttribute{
+				MarkdownDescription: "The record in canonical form, with single spaces between terms, an explicit qualifier on every mechanism, " +
+					"lowercase mechanism types, and modifiers last. Mechanism order is preserved. Useful for diff-stable records",
+				Computed: true,
+			},
+			"optimization_hints": schema.ListAttribute{
+				MarkdownDescription: "Advisory suggestions for making the record cheaper to evaluate",
+				Computed:            true,
+				ElementType:         types.StringType,
+			},
+			"combined_lookup_count": schema.Int64Attribute{
+				MarkdownDescription: "Total DNS lookups including those made while evaluating `include` and `redirect` targets (SPF allows max 10). Only set when `resolve` is `true`.",
+				Computed:            true,
+			},
+			"max_include_depth": schema.Int64Attribute{
+				MarkdownDescription: "How deeply `include` mechanisms nest: 0 for a record without includes, 1 when the included records have no includes of their own, and so on. " +
+					"Only set when `resolve` is `true`.",
+				Computed: true,
+			},
+			"void_lookup_count": schema.Int64Attribute{
+				MarkdownDescription: "Number of `a`, `mx`, and `exists` mechanisms that resolve to no records (SPF allows max 2). Only set when `resolve` is `true`.",
+				Computed:            true,
+			},
+			"lookup_breakdown": schema.ListNestedAttribute{
+				MarkdownDescription: "Per-term DNS lookup cost, one entry per mechanism and redirect modifier",
+				Computed:            true,
 				NestedObject: schema.NestedAttributeObject{
 					Attributes: map[string]schema.Attribute{
-						"qualifier": schema.StringAttribute{
-							MarkdownDescription: "The qualifier (+ for pass, - for fail, ~ for softfail, ? for neutral)",
+						"mechanism": schema.StringAttribute{
+							MarkdownDescription: "The mechanism or modifier as written in the record",
 							Computed:            true,
 						},
-						"type": schema.StringAttribute{
-							MarkdownDescription: "The mechanism type (all, include, a, mx, ip4, ip6, exists, ptr)",
+						"costs_lookup": schema.BoolAttribute{
+							MarkdownDescription: "True if the term counts against the 10-lookup limit (include, a, mx, ptr, exists, redirect)",
 							Computed:            true,
 						},
-						"value": schema.StringAttribute{
-							MarkdownDescription: "The mechanism value (domain, IP range, etc.)",
-							Computed:            true,
+						"nested_lookups": schema.Int64Attribute{
+							MarkdownDescription: "Additional lookups triggered by evaluating the target record of an `include` or `redirect`. " +
+								"Only known when `resolve` is `true`; zero for all other terms.",
+							Computed: true,
 						},
 					},
 				},
 			},
-			"redirect": schema.StringAttribute{
-				MarkdownDescription: "The redirect modifier value, if present",
-				Computed:            true,
-			},
-			"dns_lookup_count": schema.Int64Attribute{
-				MarkdownDescription: "Number of mechanisms that require DNS lookups (SPF allows max 10)",
-				Computed:            true,
-			},
 		},
 	}
 }
 
+func (d *SPFDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	d.providerData = configureProviderData(req, resp)
+}
+
 func (d *SPFDataSource) ValidateConfig(ctx context.Context, req datasource.ValidateConfigRequest, resp *datasource.ValidateConfigResponse) {
+	defer d.providerData.applyWarningSettings(&resp.Diagnostics)
+
 	var data SPFDataSourceModel
 
 	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
@@ -95,23 +267,46 @@ func (d *SPFDataSource) ValidateConfig(ctx context.Context, req datasource.Valid
 		return
 	}
 
-	// Skip validation if record is unknown (e.g., depends on another resource)
-	if data.Record.IsUnknown() {
+	if !data.MaxRedirectDepth.IsNull() && !data.MaxRedirectDepth.IsUnknown() && data.MaxRedirectDepth.ValueInt64() < 1 {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("max_redirect_depth"),
+			summarySPFInvalidMaxRedirectDepth,
+			fmt.Sprintf("max_redirect_depth must be at least 1, got %d.", data.MaxRedirectDepth.ValueInt64()),
+		)
+	}
+	if !data.MaxDepth.IsNull() && !data.MaxDepth.IsUnknown() && data.MaxDepth.ValueInt64() < 0 {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("max_depth"),
+			summarySPFInvalidMaxDepth,
+			fmt.Sprintf("max_depth must not be negative, got %d.", data.MaxDepth.ValueInt64()),
+		)
+	}
+
+	// The published record is only known once it is looked up in Read
+	if data.Lookup.IsUnknown() {
+		return
+	}
+	if data.Lookup.ValueBool() {
+		checkLookupInput(data.Record, data.RecordFile, data.Domain, &resp.Diagnostics)
 		return
 	}
 
-	// Validate the SPF record
-	record := data.Record.ValueString()
-	_, err := spf.ParseSPF(record)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Invalid SPF Record",
-			fmt.Sprintf("The SPF record is malformed: %s\n\nRecord: %s", err.Error(), record),
-		)
+	record := recordInput(data.Record, data.RecordFile, &resp.Diagnostics)
+
+	// Skip validation if record is unknown (e.g., depends on another resource)
+	// or could not be determined
+	if record.IsUnknown() || record.IsNull() {
+		return
 	}
+
+	var checkDiags diag.Diagnostics
+	validateSPFRecord(record.ValueString(), &checkDiags)
+	d.providerData.routeDiagnostics(checkDiags, &resp.Diagnostics)
 }
 
 func (d *SPFDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	defer d.providerData.applyWarningSettings(&resp.Diagnostics)
+
 	var data SPFDataSourceModel
 
 	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
@@ -119,49 +314,187 @@ func (d *SPFDataSource) Read(ctx context.Context, req datasource.ReadRequest, re
 		return
 	}
 
+	data.ChangeTicket = d.providerData.checkChangeTicket(data.ChangeTicket, &resp.Diagnostics)
+
+	validate := func(diags *diag.Diagnostics) { validateSPFRecord(data.Record.ValueString(), diags) }
+	read := func(diags *diag.Diagnostics) { d.read(ctx, &data, diags) }
+	if data.Lookup.ValueBool() {
+		// ValidateConfig has not seen the published record, so it is
+		// validated along with the read
+		validate = func(*diag.Diagnostics) {}
+		read = func(diags *diag.Diagnostics) {
+			domain := strings.ToLower(strings.TrimSuffix(data.Domain.ValueString(), "."))
+			record, err := findSPFRecord(ctx, d.providerData.dnsResolver(), domain)
+			if err != nil {
+				diags.AddError(lookupFailure(summarySPFLookupFailed, err.Error(), err))
+				return
+			}
+			data.Record = types.StringValue(record)
+			validateSPFRecord(record, diags)
+			if !diags.HasError() {
+				d.read(ctx, &data, diags)
+			}
+		}
+	} else {
+		data.Record = recordInput(data.Record, data.RecordFile, &resp.Diagnostics)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	data.Valid, data.Warnings = d.providerData.checkRecord(validate, read, &resp.Diagnostics)
+	if !data.Valid.ValueBool() {
+		resp.Diagnostics.Append(setInvalidRecordState(ctx, req.Config, &resp.State, data.ChangeTicket, data.Valid, data.Warnings)...)
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("record"), data.Record)...)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// read parses the record in data and fills in the computed attributes,
+// performing live lookups when resolve is set.
+func (d *SPFDataSource) read(ctx context.Context, data *SPFDataSourceModel, diags *diag.Diagnostics) {
 	record := data.Record.ValueString()
 	parsed, err := spf.ParseSPF(record)
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Invalid SPF Record",
-			fmt.Sprintf("The SPF record is malformed: %s", err.Error()),
-		)
+		diags.AddError(summarySPFParseError, spfParseErrorDetail(record, err))
 		return
 	}
+	data.ParsedJSON = parsedRecordJSON("spf", record, diags)
+
+	resolve := data.Resolve.ValueBool()
+	resolver := newTXTCachingResolver(d.providerData.dnsResolver())
 
-	// Count DNS lookup mechanisms
-	dnsLookupCount := 0
+	// Mechanisms without a domain-spec refer to the domain the record is
+	// published at, which is only known when the record was looked up
+	domain := ""
+	if data.Lookup.ValueBool() {
+		domain = strings.ToLower(strings.TrimSuffix(data.Domain.ValueString(), "."))
+	}
+
+	hasAll := false
+	voidLookups := 0
 	mechanismValues := make([]attr.Value, 0, len(parsed.Mechanisms))
+	mechanismCounts := make(map[string]int64)
+	terms := alignedMechanismTerms(record, parsed)
 
-	for _, m := range parsed.Mechanisms {
+	for i, m := range parsed.Mechanisms {
 		qualifier, mechType, value := parseMechanism(m)
+		explicitQualifier := types.BoolNull()
+		ip4CIDR, ip6CIDR := types.Int64Null(), types.Int64Null()
+		if terms != nil {
+			explicitQualifier = types.BoolValue(strings.ContainsAny(terms[i][:1], "+-~?"))
+			// Malformed lengths were already reported by validateSPFRecord
+			if ip4, ip6, err := spfDualCIDR(terms[i]); err == nil {
+				if ip4 >= 0 {
+					ip4CIDR = types.Int64Value(int64(ip4))
+				}
+				if ip6 >= 0 {
+					ip6CIDR = types.Int64Value(int64(ip6))
+				}
+			}
+		}
+		mechanismCounts[mechType]++
+
+		if mechType == "all" {
+			hasAll = true
+		}
 
-		// Count mechanisms that require DNS lookups
-		switch mechType {
-		case "include", "a", "mx", "ptr", "exists":
-			dnsLookupCount++
+		resolvedCount := types.Int64Null()
+		if resolve {
+			count, resolved, err := resolveMechanism(ctx, resolver, m, domain)
+			switch {
+			case err != nil:
+				diags.AddWarning(lookupFailure(
+					summarySPFMechanismLookupFailed,
+					fmt.Sprintf("Unable to resolve the mechanism %q: %s", m.String(), err.Error()),
+					err,
+				))
+			case resolved:
+				resolvedCount = types.Int64Value(int64(count))
+				if count == 0 {
+					voidLookups++
+				}
+			}
+			if mx, isMX := m.(spf.MechanismMX); isMX && resolved && count > maxSPFMXRecords {
+				diags.AddError(
+					summarySPFMXLimitExceeded,
+					fmt.Sprintf("The mechanism %q resolves to %d MX records at %s, but RFC 7208 section 4.6.4 allows at most %d. "+
+						"Receivers will return a permerror for this record.", m.String(), count, mechanismTarget(mx.DomainSpec, domain), maxSPFMXRecords),
+				)
+			}
 		}
 
-		mechObj, diags := types.ObjectValue(
+		mechObj, objDiags := types.ObjectValue(
 			mechanismObjectType.AttrTypes,
 			map[string]attr.Value{
-				"qualifier": types.StringValue(qualifier),
-				"type":      types.StringValue(mechType),
-				"value":     types.StringValue(value),
+				"qualifier":          types.StringValue(qualifier),
+				"type":               types.StringValue(mechType),
+				"value":              types.StringValue(value),
+				"explicit_qualifier": explicitQualifier,
+				"resolved_count":     resolvedCount,
+				"raw":                types.StringValue(m.String()),
+				"ip4_cidr":           ip4CIDR,
+				"ip6_cidr":           ip6CIDR,
 			},
 		)
-		resp.Diagnostics.Append(diags...)
+		diags.Append(objDiags...)
 		mechanismValues = append(mechanismValues, mechObj)
 	}
 
-	// Include redirect in DNS lookup count
-	if parsed.Redirect != "" {
-		dnsLookupCount++
+	// RFC 7208 section 6.1: redirect is ignored when the record contains an "all" mechanism
+	if parsed.Redirect != "" && hasAll {
+		diags.AddError(
+			summarySPFRedirectWithAll,
+			fmt.Sprintf("The SPF record contains both an \"all\" mechanism and redirect=%s. "+
+				"Per RFC 7208 section 6.1 the \"all\" mechanism always wins and the redirect is never evaluated. "+
+				"Remove the \"all\" mechanism to delegate to the redirect target, or remove the redirect modifier.", parsed.Redirect),
+		)
+	}
+
+	// Without "all" or redirect, unmatched senders get the default neutral result
+	if parsed.Redirect == "" && !hasAll {
+		diags.AddWarning(
+			summarySPFNoTerminalMechanism,
+			"The SPF record ends without an \"all\" mechanism or a redirect modifier, so senders that match nothing get a neutral result. "+
+				"This is rarely intended and often means the record was truncated. Add an explicit ~all or -all.",
+		)
+	}
+
+	if include, ok := soleIncludeWithFailAll(parsed); ok {
+		diags.AddWarning(
+			summarySPFIncludeShouldBeRedirect,
+			fmt.Sprintf("The SPF record only includes %s and then fails everything else. "+
+				"redirect=%s expresses this more directly: it hands the whole evaluation, including the final result, to %s, "+
+				"so senders it does not authorize get the result it chooses.", include, include, include),
+		)
+	}
+
+	for _, overlap := range findOverlappingNetworks(parsed.Mechanisms) {
+		diags.AddWarning(
+			summarySPFRedundantIPRange,
+			fmt.Sprintf("The SPF network %s is already contained in %s, so it is redundant. "+
+				"Consider removing it to keep the record short.", overlap[1], overlap[0]),
+		)
 	}
 
-	mechList, diags := types.ListValue(mechanismObjectType, mechanismValues)
-	resp.Diagnostics.Append(diags...)
+	if implicit := implicitDomainMechanisms(parsed); len(implicit) > 0 {
+		diags.AddWarning(
+			summarySPFImplicitDomain,
+			fmt.Sprintf("The SPF record uses %s without a domain, which matches against the domain being checked rather than a fixed one. "+
+				"When this record is included from another domain's record, it is checked against that domain instead. "+
+				"If the mechanism is meant for this domain's own hosts, name the domain explicitly (e.g., a:example.com).",
+				strings.Join(implicit, ", ")),
+		)
+	}
+
+	mechList, listDiags := types.ListValue(mechanismObjectType, mechanismValues)
+	diags.Append(listDiags...)
 	data.Mechanisms = mechList
+	counts, mapDiags := types.MapValueFrom(ctx, types.Int64Type, mechanismCounts)
+	diags.Append(mapDiags...)
+	data.MechanismCounts = counts
 
 	if parsed.Redirect != "" {
 		data.Redirect = types.StringValue(parsed.Redirect)
@@ -169,9 +502,650 @@ func (d *SPFDataSource) Read(ctx context.Context, req datasource.ReadRequest, re
 		data.Redirect = types.StringNull()
 	}
 
-	data.DNSLookupCount = types.Int64Value(int64(dnsLookupCount))
+	data.DNSLookupCount = types.Int64Value(int64(countSPFLookups(parsed)))
+	data.NormalizedRecord = types.StringValue(normalizeSPFRecord(parsed))
 
-	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+	data.RedirectTarget = types.StringNull()
+	if resolve && parsed.Redirect != "" {
+		maxDepth := defaultMaxRedirectDepth
+		if !data.MaxRedirectDepth.IsNull() {
+			maxDepth = int(data.MaxRedirectDepth.ValueInt64())
+		}
+
+		records, err := followSPFRedirects(ctx, resolver, parsed.Redirect, maxDepth)
+		switch {
+		case errors.Is(err, errSPFRedirectChain):
+			diags.AddError(
+				summarySPFInvalidRedirectChain,
+				fmt.Sprintf("Following redirect=%s failed: %s", parsed.Redirect, err.Error()),
+			)
+		case err != nil:
+			diags.AddWarning(lookupFailure(
+				summarySPFRedirectLookupFailed,
+				fmt.Sprintf("Unable to follow redirect=%s: %s", parsed.Redirect, err.Error()),
+				err,
+			))
+		}
+		if len(records) > 0 {
+			data.RedirectTarget = types.StringValue(records[0])
+		}
+	}
+
+	data.CombinedLookups = types.Int64Null()
+	data.MaxIncludeDepth = types.Int64Null()
+	var walks []spfTargetWalk
+	if resolve {
+		walks = walkSPFTargets(ctx, resolver, parsed)
+		totals := sumSPFTargetWalks(parsed, walks)
+		loop := spfIncludeLoop(walks)
+		if loop != nil {
+			diags.AddError(
+				summarySPFIncludeLoop,
+				fmt.Sprintf("The include tree of this SPF record loops back on itself: %s", loop.Error()),
+			)
+		}
+
+		// A loop leaves the totals incomplete too, but is already reported
+		switch {
+		case totals.lookupsErr == nil:
+			data.CombinedLookups = types.Int64Value(int64(totals.lookups))
+		case loop == nil:
+			diags.AddWarning(lookupFailure(
+				summarySPFLookupCountIncomplete,
+				fmt.Sprintf("Unable to count lookups in include and redirect targets: %s", totals.lookupsErr.Error()),
+				totals.lookupsErr,
+			))
+		}
+
+		switch {
+		case totals.depthErr == nil:
+			data.MaxIncludeDepth = types.Int64Value(int64(totals.depth))
+			if !data.MaxDepth.IsNull() && int64(totals.depth) > data.MaxDepth.ValueInt64() {
+				diags.AddError(
+					summarySPFIncludeDepthExceeded,
+					fmt.Sprintf("The SPF include tree is %d levels deep, but max_depth is %d. "+
+						"Deeply nested includes are hard to audit and quickly use up the 10-lookup limit.", totals.depth, data.MaxDepth.ValueInt64()),
+				)
+			}
+		case loop == nil:
+			diags.AddWarning(lookupFailure(
+				summarySPFIncludeDepthIncomplete,
+				fmt.Sprintf("Unable to walk the include tree: %s", totals.depthErr.Error()),
+				totals.depthErr,
+			))
+		}
+	}
+
+	data.VoidLookupCount = types.Int64Null()
+	if resolve {
+		data.VoidLookupCount = types.Int64Value(int64(voidLookups))
+		if voidLookups > maxSPFVoidLookups {
+			diags.AddError(
+				summarySPFVoidLookupLimitExceeded,
+				fmt.Sprintf("%d mechanisms resolve to no records (NXDOMAIN or an empty answer), but RFC 7208 section 4.6.4 allows at most %d void lookups. "+
+					"Receivers will return a permerror for this record. Remove mechanisms that point at names without records.", voidLookups, maxSPFVoidLookups),
+			)
+		}
+	}
+	data.LookupBreakdown = lookupBreakdown(parsed, walks, resolve, diags)
+
+	var hints []string
+	if data.OrderingHints.ValueBool() {
+		if hint := orderingHint(parsed); hint != "" {
+			hints = append(hints, hint)
+		}
+	}
+	data.OptimizationHints = convertStringSliceToList(ctx, hints, diags)
+}
+
+// validateSPFRecord reports problems with an SPF record that can be found
+// without DNS lookups.
+func validateSPFRecord(record string, diags *diag.Diagnostics) {
+	if err := checkSPFCharacters(record); err != nil {
+		diags.AddError(
+			summarySPFParseError,
+			fmt.Sprintf("The SPF record contains invalid characters: %s\n\nRecord: %q", err.Error(), record),
+		)
+		return
+	}
+
+	if trimmed := strings.Trim(record, " "); trimmed != record {
+		diags.AddWarning(
+			summarySPFSurroundingSpaces,
+			"The SPF record has leading or trailing spaces. They are ignored during validation, but should be removed before publishing.",
+		)
+		record = trimmed
+	}
+
+	if err := checkSPFVersion(record); err != nil {
+		diags.AddError(
+			summarySPFParseError,
+			fmt.Sprintf("The SPF record is malformed: %s\n\nRecord: %s", err.Error(), record),
+		)
+		return
+	}
+
+	// The parser rejects these too, but only as an invalid domain-spec
+	if term, suggestion, ok := spfIPLiteralTarget(record); ok {
+		diags.AddError(
+			summarySPFIPLiteralTarget,
+			fmt.Sprintf("The SPF record uses %s, but include, exists, and redirect take a domain name, so receivers return a permerror. "+
+				"To authorize the address, use %s instead.\n\nRecord: %s", term, suggestion, record),
+		)
+		return
+	}
+
+	// The parser accepts some malformed lengths, such as a/024, and reports
+	// the rest without naming the term
+	for _, term := range spfMechanismTerms(record) {
+		if _, _, err := spfDualCIDR(term); err != nil {
+			diags.AddError(
+				summarySPFInvalidCIDRLength,
+				fmt.Sprintf("The mechanism %q has a malformed prefix length: %s. Receivers return a permerror for this record.\n\nRecord: %s",
+					term, err.Error(), record),
+			)
+			return
+		}
+	}
+
+	parsed, err := spf.ParseSPF(record)
+	if err != nil {
+		diags.AddError(summarySPFParseError, spfParseErrorDetail(record, err))
+		return
+	}
+
+	for _, m := range parsed.Mechanisms {
+		if _, mechType, _ := parseMechanism(m); mechType == "ptr" {
+			diags.AddWarning(
+				summarySPFPTRDeprecated,
+				"The SPF record uses the ptr mechanism, which RFC 7208 section 5.5 says should not be used because it is slow and unreliable, "+
+					"and some receivers ignore it. Use ip4, ip6, a, or include mechanisms instead.",
+			)
+			break
+		}
+	}
+}
+
+// lookupBreakdown builds the per-term lookup cost list. Nested lookups for
+// include and redirect targets come from walks, and are only set when resolve
+// is true.
+func lookupBreakdown(parsed *spf.SPFRecord, walks []spfTargetWalk, resolve bool, diags *diag.Diagnostics) types.List {
+	byTarget := make(map[string]spfTargetWalk, len(walks))
+	for _, w := range walks {
+		byTarget[w.target] = w
+	}
+
+	nested := func(target string) types.Int64 {
+		w, ok := byTarget[target]
+		if !resolve || !ok {
+			return types.Int64Null()
+		}
+		if w.err != nil {
+			// Include loops are reported once by read.
+			if !errors.Is(w.err, errSPFIncludeLoop) {
+				diags.AddWarning(lookupFailure(
+					summarySPFLookupBreakdownIncomplete,
+					fmt.Sprintf("Unable to count nested lookups for %s: %s", target, w.err.Error()),
+					w.err,
+				))
+			}
+			return types.Int64Null()
+		}
+		return types.Int64Value(int64(w.lookups))
+	}
+
+	values := make([]attr.Value, 0, len(parsed.Mechanisms)+1)
+	add := func(term string, costsLookup bool, nestedLookups types.Int64) {
+		obj, objDiags := types.ObjectValue(
+			lookupBreakdownObjectType.AttrTypes,
+			map[string]attr.Value{
+				"mechanism":      types.StringValue(term),
+				"costs_lookup":   types.BoolValue(costsLookup),
+				"nested_lookups": nestedLookups,
+			},
+		)
+		diags.Append(objDiags...)
+		values = append(values, obj)
+	}
+
+	for _, m := range parsed.Mechanisms {
+		if inc, ok := m.(spf.MechanismInclude); ok {
+			add(m.String(), true, nested(inc.DomainSpec))
+			continue
+		}
+		add(m.String(), requiresDNSLookup(m), types.Int64Value(0))
+	}
+	if parsed.Redirect != "" {
+		add("redirect="+parsed.Redirect, true, nested(parsed.Redirect))
+	}
+
+	list, listDiags := types.ListValue(lookupBreakdownObjectType, values)
+	diags.Append(listDiags...)
+	return list
+}
+
+// spfVersionRe matches the version term required at the start of an SPF
+// record. RFC 7208 section 4.5 makes the match case-insensitive.
+var spfVersionRe = regexp.MustCompile(`(?i)^v=spf1(?: |$)`)
+
+// spfIPLiteralTarget finds the first include, exists, or redirect term whose
+// target is an IP address or CIDR range rather than a domain, and returns it
+// with the equivalent ip4 or ip6 mechanism.
+func spfIPLiteralTarget(record string) (term, suggestion string, ok bool) {
+	for _, field := range strings.Fields(record)[1:] {
+		qualifier, body := "", field
+		if strings.ContainsAny(body[:1], "+-~?") {
+			qualifier, body = body[:1], body[1:]
+		}
+
+		var target string
+		lower := strings.ToLower(body)
+		switch {
+		case strings.HasPrefix(lower, "include:"), strings.HasPrefix(lower, "exists:"):
+			_, target, _ = strings.Cut(body, ":")
+		case strings.HasPrefix(lower, "redirect="):
+			_, target, _ = strings.Cut(body, "=")
+			qualifier = ""
+		default:
+			continue
+		}
+
+		addr, err := netip.ParseAddr(target)
+		if err != nil {
+			prefix, err := netip.ParsePrefix(target)
+			if err != nil {
+				continue
+			}
+			addr = prefix.Addr()
+		}
+		mechanism := "ip4:"
+		if !addr.Is4() {
+			mechanism = "ip6:"
+		}
+		return field, qualifier + mechanism + target, true
+	}
+	return "", "", false
+}
+
+// spfDualCIDRRe matches the dual-cidr-length of an a or mx mechanism
+// (RFC 7208 section 5.6): an optional IPv4 length, then an optional IPv6
+// length introduced by "//".
+var spfDualCIDRRe = regexp.MustCompile(`^(?:/(0|[1-9][0-9]*))?(?://(0|[1-9][0-9]*))?$`)
+
+// spfDualCIDR returns the IPv4 and IPv6 prefix lengths written on an a or mx
+// mechanism, such as a:example.com/24//64. A length that is not written is
+// returned as -1, as are both lengths for other mechanisms.
+func spfDualCIDR(term string) (ip4, ip6 int, err error) {
+	ip4, ip6 = -1, -1
+	body := strings.TrimLeft(term, "+-~?")
+	lower := strings.ToLower(body)
+	name := lower
+	if i := strings.IndexAny(lower, ":/"); i >= 0 {
+		name = lower[:i]
+	}
+	if name != "a" && name != "mx" {
+		return ip4, ip6, nil
+	}
+
+	// The lengths follow the domain-spec, which may itself contain "/" in a
+	// macro, so only the trailing run of slashes and digits is considered
+	rest := body[len(name):]
+	start := len(rest)
+	for start > 0 && strings.ContainsRune("/0123456789", rune(rest[start-1])) {
+		start--
+	}
+	slash := strings.IndexByte(rest[start:], '/')
+	if slash < 0 {
+		return ip4, ip6, nil
+	}
+	cidr := rest[start+slash:]
+
+	match := spfDualCIDRRe.FindStringSubmatch(cidr)
+	if match == nil {
+		return -1, -1, fmt.Errorf("%q is not a valid dual-cidr-length; expected /<ip4-length>, //<ip6-length>, or both (e.g., /24//64)", cidr)
+	}
+	if match[1] != "" {
+		if ip4, err = strconv.Atoi(match[1]); err != nil || ip4 > 32 {
+			return -1, -1, fmt.Errorf("the IPv4 prefix length /%s is out of range (0-32)", match[1])
+		}
+	}
+	if match[2] != "" {
+		if ip6, err = strconv.Atoi(match[2]); err != nil || ip6 > 128 {
+			return -1, -1, fmt.Errorf("the IPv6 prefix length //%s is out of range (0-128)", match[2])
+		}
+	}
+	return ip4, ip6, nil
+}
+
+// checkSPFVersion verifies that the record begins with exactly "v=spf1"
+// followed by a space or the end of the record.
+func checkSPFVersion(record string) error {
+	record = strings.TrimRight(record, " ")
+	switch {
+	case strings.HasPrefix(record, "\uFEFF"):
+		return errors.New("record begins with a byte order mark (BOM); remove it so the record starts with v=spf1")
+	case record != strings.TrimLeft(record, " \t\r\n"):
+		return errors.New("record begins with whitespace; it must start with v=spf1")
+	case !spfVersionRe.MatchString(record):
+		return errors.New("record must begin with v=spf1 followed by a space or the end of the record")
+	}
+	return nil
+}
+
+// orderingHint suggests moving ip4/ip6 mechanisms ahead of include mechanisms
+// in records ending in -all. Mechanisms are evaluated left to right, so a
+// sender matching a local range is accepted without any include lookups.
+func orderingHint(parsed *spf.SPFRecord) string {
+	n := len(parsed.Mechanisms)
+	if n == 0 {
+		return ""
+	}
+	if all, ok := parsed.Mechanisms[n-1].(spf.MechanismAll); !ok || all.Qualifier != spf.Fail {
+		return ""
+	}
+
+	var includes, ranges, pending []string
+	for _, m := range parsed.Mechanisms {
+		switch m.(type) {
+		case spf.MechanismInclude:
+			pending = append(pending, m.String())
+		case spf.MechanismIp4, spf.MechanismIp6:
+			// Only ranges that come after an include are worth moving
+			if len(pending) > 0 {
+				includes = append(includes, pending...)
+				pending = nil
+			}
+			if len(includes) > 0 {
+				ranges = append(ranges, m.String())
+			}
+		}
+	}
+	if len(ranges) == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("Consider moving %s ahead of %s: ip4/ip6 mechanisms need no DNS lookups, "+
+		"so senders in those ranges are matched before any include is evaluated.",
+		strings.Join(ranges, ", "), strings.Join(includes, ", "))
+}
+
+// spfModifierRe matches SPF modifier terms (name=value) as opposed to mechanisms.
+var spfModifierRe = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_.-]*=`)
+
+// spfMechanismTerms returns the mechanism terms of a record exactly as written,
+// in the same order as spf.ParseSPF returns them. The parsed mechanisms do not
+// preserve details such as an explicit "+" qualifier.
+func spfMechanismTerms(record string) []string {
+	fields := strings.Fields(record)
+	if len(fields) == 0 {
+		return nil
+	}
+
+	terms := make([]string, 0, len(fields)-1)
+	for _, field := range fields[1:] {
+		if !spfModifierRe.MatchString(field) {
+			terms = append(terms, field)
+		}
+	}
+	return terms
+}
+
+// alignedMechanismTerms returns the mechanism terms of a record as written,
+// one per parsed mechanism. The terms are split independently of the parser,
+// so nil is returned when the two disagree on the number of mechanisms rather
+// than attributing a term to the wrong mechanism.
+func alignedMechanismTerms(record string, parsed *spf.SPFRecord) []string {
+	terms := spfMechanismTerms(record)
+	if len(terms) != len(parsed.Mechanisms) {
+		return nil
+	}
+	return terms
+}
+
+// spfParseErrorDetail describes an spf.ParseSPF error, pointing at the term
+// that fails when one can be found, since the parser does not report where in
+// a long record the problem is.
+func spfParseErrorDetail(record string, err error) string {
+	detail := fmt.Sprintf("The SPF record is malformed: %s", err.Error())
+	if term, offset, ok := locateSPFParseError(record); ok {
+		detail += fmt.Sprintf("\n\nThe problem is near %q at byte offset %d.", term, offset)
+	}
+	return detail + "\n\nRecord: " + record
+}
+
+// locateSPFParseError returns the first term of record that spf.ParseSPF
+// rejects on its own, and its byte offset. Problems that only arise from
+// combining terms, such as a repeated modifier, are not located.
+func locateSPFParseError(record string) (string, int, bool) {
+	offset := 0
+	for i, field := range strings.Split(record, " ") {
+		start := offset
+		offset += len(field) + 1
+		// The first field is the version, which is checked separately
+		if i == 0 || field == "" {
+			continue
+		}
+		if _, err := spf.ParseSPF("v=spf1 " + field); err != nil {
+			return field, start, true
+		}
+	}
+	return "", 0, false
+}
+
+// checkSPFCharacters rejects control characters (including tabs and line
+// breaks) and non-ASCII whitespace such as non-breaking spaces, which are
+// often introduced by copying records from web consoles.
+func checkSPFCharacters(record string) error {
+	for offset, r := range record {
+		switch {
+		case r == utf8.RuneError:
+			return fmt.Errorf("invalid UTF-8 at byte offset %d", offset)
+		case unicode.IsControl(r):
+			return fmt.Errorf("control character %U at byte offset %d", r, offset)
+		case r > unicode.MaxASCII && (unicode.IsSpace(r) || r == '\uFEFF' || r == '\u200B'):
+			return fmt.Errorf("non-ASCII whitespace %U at byte offset %d; use a plain space instead", r, offset)
+		}
+	}
+	return nil
+}
+
+// countSPFLookups returns the number of DNS lookups the record's own terms
+// require, counting each include, a, mx, ptr and exists mechanism plus the
+// redirect modifier.
+func countSPFLookups(parsed *spf.SPFRecord) int {
+	count := 0
+	for _, m := range parsed.Mechanisms {
+		if requiresDNSLookup(m) {
+			count++
+		}
+	}
+	if parsed.Redirect != "" {
+		count++
+	}
+	return count
+}
+
+// normalizeSPFRecord returns the canonical form of a parsed SPF record: terms
+// separated by single spaces, every mechanism with an explicit qualifier and
+// a lowercase type, and the modifiers after the mechanisms. Mechanism order
+// is preserved, as it decides which mechanism matches first. Domain specs are
+// kept as written because macro letters are case-sensitive.
+func normalizeSPFRecord(parsed *spf.SPFRecord) string {
+	terms := []string{"v=spf1"}
+	for _, m := range parsed.Mechanisms {
+		qualifier, mechType, value := parseMechanism(m)
+		var mask4, mask6 net.IPMask
+		switch m := m.(type) {
+		case spf.MechanismA:
+			mask4, mask6 = m.Mask4, m.Mask6
+		case spf.MechanismMX:
+			mask4, mask6 = m.Mask4, m.Mask6
+		case spf.MechanismIp4, spf.MechanismIp6:
+			// Single addresses are written without a prefix length
+			if prefix, err := netip.ParsePrefix(value); err == nil && prefix.IsSingleIP() {
+				value = prefix.Addr().String()
+			}
+		}
+
+		term := qualifier + mechType
+		if value != "" {
+			term += ":" + value
+		}
+		if ones, bits := mask4.Size(); bits != 0 && ones != bits {
+			term += "/" + strconv.Itoa(ones)
+		}
+		if ones, bits := mask6.Size(); bits != 0 && ones != bits {
+			term += "//" + strconv.Itoa(ones)
+		}
+		terms = append(terms, term)
+	}
+
+	if parsed.Redirect != "" {
+		terms = append(terms, "redirect="+parsed.Redirect)
+	}
+	if parsed.Exp != "" {
+		terms = append(terms, "exp="+parsed.Exp)
+	}
+	for _, modifier := range parsed.OtherModifiers {
+		name, value, _ := strings.Cut(modifier, "=")
+		terms = append(terms, strings.ToLower(name)+"="+value)
+	}
+	return strings.Join(terms, " ")
+}
+
+// implicitDomainMechanisms returns the a and mx mechanisms that have no
+// domain-spec and so resolve against the current domain (RFC 7208 sections
+// 5.3 and 5.4), which is the including domain when the record is included.
+func implicitDomainMechanisms(parsed *spf.SPFRecord) []string {
+	var implicit []string
+	for _, m := range parsed.Mechanisms {
+		switch m := m.(type) {
+		case spf.MechanismA:
+			if m.DomainSpec == "" {
+				implicit = append(implicit, m.String())
+			}
+		case spf.MechanismMX:
+			if m.DomainSpec == "" {
+				implicit = append(implicit, m.String())
+			}
+		}
+	}
+	return implicit
+}
+
+// spfTargetDomains returns the domains an SPF record refers to: the targets
+// of its include, a, mx, exists, and ptr mechanisms and of redirect. Targets
+// with macros are left out, since they only expand while a message is checked.
+func spfTargetDomains(parsed *spf.SPFRecord) []string {
+	var domains []string
+	add := func(domainSpec string) {
+		if isResolvableDomainSpec(domainSpec) {
+			domains = append(domains, domainSpec)
+		}
+	}
+	for _, m := range parsed.Mechanisms {
+		switch m := m.(type) {
+		case spf.MechanismInclude:
+			add(m.DomainSpec)
+		case spf.MechanismA:
+			add(m.DomainSpec)
+		case spf.MechanismMX:
+			add(m.DomainSpec)
+		case spf.MechanismExists:
+			add(m.DomainSpec)
+		case spf.MechanismPTR:
+			add(m.DomainSpec)
+		}
+	}
+	add(parsed.Redirect)
+	return domains
+}
+
+// soleIncludeWithFailAll reports whether the record consists of a single
+// include followed by -all, the shape that is better written as a redirect,
+// and returns the include target.
+func soleIncludeWithFailAll(parsed *spf.SPFRecord) (string, bool) {
+	if parsed.Redirect != "" || len(parsed.Mechanisms) != 2 {
+		return "", false
+	}
+	include, ok := parsed.Mechanisms[0].(spf.MechanismInclude)
+	if !ok || include.Qualifier != spf.Pass {
+		return "", false
+	}
+	all, ok := parsed.Mechanisms[1].(spf.MechanismAll)
+	if !ok || all.Qualifier != spf.Fail {
+		return "", false
+	}
+	return include.DomainSpec, true
+}
+
+// requiresDNSLookup reports whether a mechanism counts against the SPF
+// 10-lookup limit.
+func requiresDNSLookup(m spf.Mechanism) bool {
+	switch m.(type) {
+	case spf.MechanismInclude, spf.MechanismA, spf.MechanismMX, spf.MechanismPTR, spf.MechanismExists:
+		return true
+	}
+	return false
+}
+
+// findOverlappingNetworks compares every ip4 and ip6 mechanism pairwise and
+// returns each pair where the first network contains the second.
+func findOverlappingNetworks(mechanisms []spf.Mechanism) [][2]netip.Prefix {
+	var prefixes []netip.Prefix
+	for _, m := range mechanisms {
+		var p netip.Prefix
+		var ok bool
+		switch m := m.(type) {
+		case spf.MechanismIp4:
+			p, ok = ipNetPrefix(m.Net, true)
+		case spf.MechanismIp6:
+			p, ok = ipNetPrefix(m.Net, false)
+		}
+		if ok {
+			prefixes = append(prefixes, p.Masked())
+		}
+	}
+
+	var overlaps [][2]netip.Prefix
+	for i := range prefixes {
+		for j := range prefixes {
+			if i == j {
+				continue
+			}
+			outer, inner := prefixes[i], prefixes[j]
+			// Report identical networks only once
+			if outer == inner && j < i {
+				continue
+			}
+			if outer.Bits() <= inner.Bits() && outer.Contains(inner.Addr()) {
+				overlaps = append(overlaps, [2]netip.Prefix{outer, inner})
+			}
+		}
+	}
+	return overlaps
+}
+
+// ipNetPrefix converts the network of an ip4 or ip6 mechanism to a prefix of
+// that family. An ip6 network written as an IPv4-mapped address stays IPv6, so
+// it is never compared with ip4 networks.
+func ipNetPrefix(n *net.IPNet, ip4 bool) (netip.Prefix, bool) {
+	if n == nil {
+		return netip.Prefix{}, false
+	}
+	addr, ok := netip.AddrFromSlice(n.IP)
+	if !ok {
+		return netip.Prefix{}, false
+	}
+	ones, bits := n.Mask.Size()
+	if ip4 {
+		addr = addr.Unmap()
+	} else if addr.Is4() {
+		addr = netip.AddrFrom16(addr.As16())
+		ones += 128 - bits
+	}
+	p := netip.PrefixFrom(addr, ones)
+	return p, p.IsValid()
 }
 
 // parseMechanism extracts the qualifier, type, and value from an SPF mechanism.
@@ -0,0 +1,771 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/wttw/spf"
+)
+
+func TestSPFCaseInsensitivity(t *testing.T) {
+	tests := []struct {
+		name      string
+		record    string
+		wantTypes []string
+	}{
+		{
+			name:      "uppercase version",
+			record:    "V=spf1 include:_spf.google.com -all",
+			wantTypes: []string{"include", "all"},
+		},
+		{
+			name:      "uppercase version value",
+			record:    "v=SPF1 ip4:192.0.2.0/24 -all",
+			wantTypes: []string{"ip4", "all"},
+		},
+		{
+			name:      "mixed case version",
+			record:    "V=Spf1 mx ~all",
+			wantTypes: []string{"mx", "all"},
+		},
+		{
+			name:      "mixed case mechanism types",
+			record:    "v=spf1 Include:_spf.google.com IP4:192.0.2.1 Ip6:2001:db8::/32 MX A:mail.example.com -ALL",
+			wantTypes: []string{"include", "ip4", "ip6", "mx", "a", "all"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkSPFVersion(tt.record); err != nil {
+				t.Fatalf("checkSPFVersion() error = %v", err)
+			}
+
+			parsed, err := spf.ParseSPF(tt.record)
+			if err != nil {
+				t.Fatalf("spf.ParseSPF() error = %v", err)
+			}
+
+			if len(parsed.Mechanisms) != len(tt.wantTypes) {
+				t.Fatalf("got %d mechanisms, want %d", len(parsed.Mechanisms), len(tt.wantTypes))
+			}
+			for i, m := range parsed.Mechanisms {
+				_, mechType, _ := parseMechanism(m)
+				if mechType != tt.wantTypes[i] {
+					t.Errorf("mechanism %d type = %q, want %q", i, mechType, tt.wantTypes[i])
+				}
+			}
+		})
+	}
+}
+
+func TestCheckSPFVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		record  string
+		wantErr bool
+	}{
+		{name: "valid", record: "v=spf1 -all"},
+		{name: "version only", record: "v=spf1"},
+		{name: "trailing space", record: "v=spf1 -all "},
+		{name: "leading whitespace", record: " v=spf1 -all", wantErr: true},
+		{name: "byte order mark", record: "\uFEFFv=spf1 -all", wantErr: true},
+		{name: "version suffix", record: "v=spf1.0 -all", wantErr: true},
+		{name: "wrong version", record: "v=spf2 -all", wantErr: true},
+		{name: "missing version", record: "include:_spf.google.com ~all", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkSPFVersion(tt.record)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("checkSPFVersion() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSPFMechanismTerms(t *testing.T) {
+	record := "v=spf1 +include:_spf.google.com ip4:192.0.2.0/24 redirect=_spf.example.com ~all"
+	want := []string{"+include:_spf.google.com", "ip4:192.0.2.0/24", "~all"}
+
+	got := spfMechanismTerms(record)
+	if len(got) != len(want) {
+		t.Fatalf("spfMechanismTerms() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("spfMechanismTerms()[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestAlignedMechanismTerms(t *testing.T) {
+	parsed := mustParseSPF(t, "v=spf1 a mx -all")
+
+	if got := alignedMechanismTerms("v=spf1 +a mx -all redirect=x.example.com", parsed); !reflect.DeepEqual(got, []string{"+a", "mx", "-all"}) {
+		t.Errorf("alignedMechanismTerms() = %q, want the terms of each mechanism", got)
+	}
+	// Terms that do not line up with the parsed mechanisms are not used
+	if got := alignedMechanismTerms("v=spf1 a -all", parsed); got != nil {
+		t.Errorf("alignedMechanismTerms() = %q, want nil", got)
+	}
+}
+
+func mustParseSPF(t *testing.T, record string) *spf.SPFRecord {
+	t.Helper()
+	parsed, err := spf.ParseSPF(record)
+	if err != nil {
+		t.Fatalf("spf.ParseSPF(%q) error = %v", record, err)
+	}
+	return parsed
+}
+
+func TestCheckSPFCharacters(t *testing.T) {
+	tests := []struct {
+		name    string
+		record  string
+		wantErr bool
+	}{
+		{name: "plain", record: "v=spf1 include:_spf.google.com ~all"},
+		{name: "surrounding spaces", record: " v=spf1 -all "},
+		{name: "tab", record: "v=spf1\tinclude:_spf.google.com ~all", wantErr: true},
+		{name: "trailing newline", record: "v=spf1 -all\n", wantErr: true},
+		{name: "carriage return", record: "v=spf1 -all\r", wantErr: true},
+		{name: "non-breaking space", record: "v=spf1\u00A0-all", wantErr: true},
+		{name: "zero width space", record: "v=spf1 \u200B-all", wantErr: true},
+		{name: "byte order mark", record: "\uFEFFv=spf1 -all", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkSPFCharacters(tt.record)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("checkSPFCharacters() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSoleIncludeWithFailAll(t *testing.T) {
+	tests := []struct {
+		name   string
+		record string
+		want   string
+	}{
+		{name: "include and fail all", record: "v=spf1 include:_spf.example.com -all", want: "_spf.example.com"},
+		{name: "explicit pass qualifier", record: "v=spf1 +include:_spf.example.com -all", want: "_spf.example.com"},
+		{name: "softfail all", record: "v=spf1 include:_spf.example.com ~all"},
+		{name: "local mechanisms", record: "v=spf1 ip4:192.0.2.0/24 include:_spf.example.com -all"},
+		{name: "two includes", record: "v=spf1 include:a.example include:b.example -all"},
+		{name: "already a redirect", record: "v=spf1 redirect=_spf.example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed, err := spf.ParseSPF(tt.record)
+			if err != nil {
+				t.Fatalf("spf.ParseSPF() error = %v", err)
+			}
+			got, ok := soleIncludeWithFailAll(parsed)
+			if ok != (tt.want != "") || got != tt.want {
+				t.Errorf("soleIncludeWithFailAll() = %q, %t, want %q", got, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestLocateSPFParseError(t *testing.T) {
+	tests := []struct {
+		name       string
+		record     string
+		wantTerm   string
+		wantOffset int
+		wantOK     bool
+	}{
+		{name: "invalid include", record: "v=spf1 ip4:192.0.2.0/24 include:=broken -all", wantTerm: "include:=broken", wantOffset: 24, wantOK: true},
+		{name: "unknown mechanism", record: "v=spf1 foo -all", wantTerm: "foo", wantOffset: 7, wantOK: true},
+		{name: "invalid modifier", record: "v=spf1 -all exp=", wantTerm: "exp=", wantOffset: 12, wantOK: true},
+		{name: "repeated spaces", record: "v=spf1  mx  a//200 -all", wantTerm: "a//200", wantOffset: 12, wantOK: true},
+		{name: "valid record", record: "v=spf1 include:_spf.google.com -all"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			term, offset, ok := locateSPFParseError(tt.record)
+			if term != tt.wantTerm || offset != tt.wantOffset || ok != tt.wantOK {
+				t.Errorf("locateSPFParseError() = %q, %d, %t, want %q, %d, %t", term, offset, ok, tt.wantTerm, tt.wantOffset, tt.wantOK)
+			}
+			if ok && tt.record[offset:offset+len(term)] != term {
+				t.Errorf("offset %d does not point at %q", offset, term)
+			}
+		})
+	}
+}
+
+func TestSPFIPLiteralTarget(t *testing.T) {
+	tests := []struct {
+		record         string
+		wantTerm       string
+		wantSuggestion string
+	}{
+		{record: "v=spf1 include:192.0.2.1 -all", wantTerm: "include:192.0.2.1", wantSuggestion: "ip4:192.0.2.1"},
+		{record: "v=spf1 ~include:192.0.2.0/24 -all", wantTerm: "~include:192.0.2.0/24", wantSuggestion: "~ip4:192.0.2.0/24"},
+		{record: "v=spf1 EXISTS:2001:db8::1 -all", wantTerm: "EXISTS:2001:db8::1", wantSuggestion: "ip6:2001:db8::1"},
+		{record: "v=spf1 ip4:192.0.2.1 redirect=198.51.100.7", wantTerm: "redirect=198.51.100.7", wantSuggestion: "ip4:198.51.100.7"},
+		{record: "v=spf1 include:_spf.google.com ip4:192.0.2.1 -all"},
+		{record: "v=spf1 include:192.0.2.1.example.com -all"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.record, func(t *testing.T) {
+			term, suggestion, ok := spfIPLiteralTarget(tt.record)
+			if ok != (tt.wantTerm != "") || term != tt.wantTerm || suggestion != tt.wantSuggestion {
+				t.Errorf("spfIPLiteralTarget() = %q, %q, %v, want %q, %q", term, suggestion, ok, tt.wantTerm, tt.wantSuggestion)
+			}
+		})
+	}
+}
+
+func TestSPFDualCIDR(t *testing.T) {
+	tests := []struct {
+		term    string
+		wantIP4 int
+		wantIP6 int
+		wantErr bool
+	}{
+		{term: "a", wantIP4: -1, wantIP6: -1},
+		{term: "a:example.com/24//64", wantIP4: 24, wantIP6: 64},
+		{term: "~MX/30", wantIP4: 30, wantIP6: -1},
+		{term: "mx:mail1.example.com//48", wantIP4: -1, wantIP6: 48},
+		{term: "a:host1", wantIP4: -1, wantIP6: -1},
+		{term: "a:%{d}/24", wantIP4: 24, wantIP6: -1},
+		{term: "ip4:192.0.2.0/24", wantIP4: -1, wantIP6: -1},
+		{term: "all", wantIP4: -1, wantIP6: -1},
+		{term: "a:example.com/33", wantErr: true},
+		{term: "mx//129", wantErr: true},
+		{term: "a/024", wantErr: true},
+		{term: "a:example.com/24/64", wantErr: true},
+		{term: "a:example.com/", wantErr: true},
+		{term: "a:example.com//", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.term, func(t *testing.T) {
+			ip4, ip6, err := spfDualCIDR(tt.term)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("spfDualCIDR() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && (ip4 != tt.wantIP4 || ip6 != tt.wantIP6) {
+				t.Errorf("spfDualCIDR() = %d, %d, want %d, %d", ip4, ip6, tt.wantIP4, tt.wantIP6)
+			}
+		})
+	}
+}
+
+func TestSPFMechanismCIDRAttributes(t *testing.T) {
+	data := SPFDataSourceModel{Record: types.StringValue("v=spf1 a/24//64 mx:example.com -all")}
+	var diags diag.Diagnostics
+	(&SPFDataSource{}).read(context.Background(), &data, &diags)
+	if diags.HasError() {
+		t.Fatalf("read() diagnostics = %v", diags)
+	}
+
+	mechanisms := data.Mechanisms.Elements()
+	a := mechanisms[0].(types.Object).Attributes()
+	if a["ip4_cidr"].(types.Int64).ValueInt64() != 24 || a["ip6_cidr"].(types.Int64).ValueInt64() != 64 {
+		t.Errorf("a mechanism = %v, want ip4_cidr 24 and ip6_cidr 64", a)
+	}
+	mx := mechanisms[1].(types.Object).Attributes()
+	if !mx["ip4_cidr"].IsNull() || !mx["ip6_cidr"].IsNull() {
+		t.Errorf("mx mechanism = %v, want null prefix lengths", mx)
+	}
+
+	diags = nil
+	validateSPFRecord("v=spf1 a:example.com/24/64 -all", &diags)
+	if !diags.HasError() || diags.Errors()[0].Summary() != "Invalid SPF CIDR Length" {
+		t.Errorf("validateSPFRecord() diagnostics = %v, want Invalid SPF CIDR Length", diags)
+	}
+}
+
+func TestSPFMechanismRaw(t *testing.T) {
+	data := SPFDataSourceModel{Record: types.StringValue("v=spf1 a/24 ip4:1.2.3.4 +mx ip6:2001:db8::/32 -all")}
+	var diags diag.Diagnostics
+	(&SPFDataSource{}).read(context.Background(), &data, &diags)
+	if diags.HasError() {
+		t.Fatalf("read() diagnostics = %v", diags)
+	}
+
+	// raw is the parser's form: ip4 gains its implied /32 and the default +
+	// qualifier is dropped, while an explicit - is kept
+	want := []string{"a/24", "ip4:1.2.3.4/32", "mx", "ip6:2001:db8::/32", "-all"}
+	mechanisms := data.Mechanisms.Elements()
+	if len(mechanisms) != len(want) {
+		t.Fatalf("mechanisms = %v, want %d entries", mechanisms, len(want))
+	}
+	for i, m := range mechanisms {
+		if got := m.(types.Object).Attributes()["raw"].(types.String).ValueString(); got != want[i] {
+			t.Errorf("mechanisms[%d].raw = %q, want %q", i, got, want[i])
+		}
+	}
+}
+
+func TestImplicitDomainMechanisms(t *testing.T) {
+	tests := []struct {
+		record string
+		want   []string
+	}{
+		{record: "v=spf1 a mx -all", want: []string{"a", "mx"}},
+		{record: "v=spf1 ~a/24 mx:example.com -all", want: []string{"~a/24"}},
+		{record: "v=spf1 a:mail.example.com mx:example.com include:_spf.google.com -all"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.record, func(t *testing.T) {
+			parsed, err := spf.ParseSPF(tt.record)
+			if err != nil {
+				t.Fatalf("ParseSPF() error = %v", err)
+			}
+			if got := implicitDomainMechanisms(parsed); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("implicitDomainMechanisms() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSPFMXRecordLimit(t *testing.T) {
+	mxs := func(n int) []*net.MX {
+		hosts := make([]*net.MX, n)
+		for i := range hosts {
+			hosts[i] = &net.MX{Host: fmt.Sprintf("mx%d.example.com.", i), Pref: 10}
+		}
+		return hosts
+	}
+	d := &SPFDataSource{providerData: &providerData{resolver: &fakeResolver{
+		txt: map[string][]string{"example.com": {"v=spf1 mx -all"}},
+		mx: map[string][]*net.MX{
+			"example.com":       mxs(11),
+			"small.example.com": mxs(10),
+		},
+	}}}
+
+	tests := []struct {
+		name    string
+		data    SPFDataSourceModel
+		wantErr string
+	}{
+		{
+			name:    "explicit domain",
+			data:    SPFDataSourceModel{Record: types.StringValue("v=spf1 mx:example.com -all")},
+			wantErr: `"mx:example.com" resolves to 11 MX records at example.com`,
+		},
+		{
+			name:    "current domain",
+			data:    SPFDataSourceModel{Record: types.StringValue("v=spf1 mx -all"), Domain: types.StringValue("Example.com."), Lookup: types.BoolValue(true)},
+			wantErr: `"mx" resolves to 11 MX records at example.com`,
+		},
+		{
+			name: "at the limit",
+			data: SPFDataSourceModel{Record: types.StringValue("v=spf1 mx:small.example.com -all")},
+		},
+		{
+			// Without lookup the current domain is unknown
+			name: "unknown current domain",
+			data: SPFDataSourceModel{Record: types.StringValue("v=spf1 mx -all")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := tt.data
+			data.Resolve = types.BoolValue(true)
+			var diags diag.Diagnostics
+			d.read(context.Background(), &data, &diags)
+
+			if tt.wantErr == "" {
+				if diags.HasError() {
+					t.Fatalf("read() diagnostics = %v", diags)
+				}
+				return
+			}
+			if !diags.HasError() || diags.Errors()[0].Summary() != "SPF MX Record Limit Exceeded" || !strings.Contains(diags.Errors()[0].Detail(), tt.wantErr) {
+				t.Errorf("read() diagnostics = %v, want SPF MX Record Limit Exceeded mentioning %s", diags, tt.wantErr)
+			}
+		})
+	}
+}
+
+// countingResolver counts the TXT queries that reach the wrapped resolver.
+type countingResolver struct {
+	dnsResolver
+	txtQueries map[string]int
+}
+
+func (r *countingResolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	r.txtQueries[name]++
+	return r.dnsResolver.LookupTXT(ctx, name)
+}
+
+func TestSPFReadWalksIncludeTreeOnce(t *testing.T) {
+	resolver := &countingResolver{
+		dnsResolver: &fakeResolver{txt: map[string][]string{
+			"_spf.example.com":  {"v=spf1 include:_spf2.example.com -all"},
+			"_spf2.example.com": {"v=spf1 ip4:192.0.2.1 -all"},
+			"next.example.com":  {"v=spf1 include:_spf2.example.com -all"},
+			"loop.example.com":  {"v=spf1 include:loop.example.com -all"},
+		}},
+		txtQueries: make(map[string]int),
+	}
+	d := &SPFDataSource{providerData: &providerData{resolver: resolver}}
+
+	data := SPFDataSourceModel{
+		Record:  types.StringValue("v=spf1 include:_spf.example.com redirect=next.example.com"),
+		Resolve: types.BoolValue(true),
+	}
+	var diags diag.Diagnostics
+	d.read(context.Background(), &data, &diags)
+	if diags.HasError() {
+		t.Fatalf("read() diagnostics = %v", diags)
+	}
+	for name, n := range resolver.txtQueries {
+		if n != 1 {
+			t.Errorf("TXT %s queried %d times, want 1", name, n)
+		}
+	}
+	if got := data.CombinedLookups.ValueInt64(); got != 4 {
+		t.Errorf("combined_lookups = %d, want 4", got)
+	}
+	if got := data.MaxIncludeDepth.ValueInt64(); got != 2 {
+		t.Errorf("max_include_depth = %d, want 2", got)
+	}
+
+	data = SPFDataSourceModel{
+		Record:  types.StringValue("v=spf1 ip4:192.0.2.1 include:loop.example.com -all"),
+		Resolve: types.BoolValue(true),
+	}
+	diags = nil
+	d.read(context.Background(), &data, &diags)
+	if len(diags) != 1 || diags[0].Summary() != "SPF Include Loop" {
+		t.Errorf("read() diagnostics = %v, want a single SPF Include Loop error", diags)
+	}
+}
+
+func TestSPFRedirectAndAll(t *testing.T) {
+	tests := []struct {
+		name     string
+		record   string
+		wantErr  bool
+		redirect string
+	}{
+		{name: "all before redirect", record: "v=spf1 -all redirect=_spf.example.com", wantErr: true, redirect: "_spf.example.com"},
+		{name: "redirect without all", record: "v=spf1 ip4:192.0.2.0/24 redirect=_spf.example.com", redirect: "_spf.example.com"},
+		{name: "all without redirect", record: "v=spf1 ip4:192.0.2.0/24 -all"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := SPFDataSourceModel{Record: types.StringValue(tt.record)}
+			var diags diag.Diagnostics
+			(&SPFDataSource{}).read(context.Background(), &data, &diags)
+
+			if got := hasDiagnostic(diags, diag.SeverityError, "Conflicting SPF Redirect and All"); got != tt.wantErr {
+				t.Errorf("read() diagnostics = %v, want Conflicting SPF Redirect and All %v", diags, tt.wantErr)
+			}
+			if hasDiagnostic(diags, diag.SeverityWarning, "SPF Record Has No Terminal Mechanism") {
+				t.Errorf("read() warned about a missing terminal mechanism: %v", diags)
+			}
+			if got := data.Redirect.ValueString(); got != tt.redirect {
+				t.Errorf("redirect = %q, want %q", got, tt.redirect)
+			}
+		})
+	}
+}
+
+func TestFindOverlappingNetworks(t *testing.T) {
+	tests := []struct {
+		name   string
+		record string
+		want   []string
+	}{
+		{name: "disjoint", record: "v=spf1 ip4:192.0.2.0/24 ip4:198.51.100.0/24 -all"},
+		{name: "contained ip4", record: "v=spf1 ip4:192.0.2.0/24 ip4:192.0.2.10 -all", want: []string{"192.0.2.0/24 > 192.0.2.10/32"}},
+		{name: "contained ip4 listed first", record: "v=spf1 ip4:192.0.2.128/25 ip4:192.0.2.0/24 -all", want: []string{"192.0.2.0/24 > 192.0.2.128/25"}},
+		{name: "contained ip6", record: "v=spf1 ip6:2001:db8::/32 ip6:2001:db8:1::/48 -all", want: []string{"2001:db8::/32 > 2001:db8:1::/48"}},
+		{name: "mixed families", record: "v=spf1 ip4:0.0.0.0/0 ip6:::ffff:192.0.2.1 -all"},
+		{name: "identical ranges", record: "v=spf1 ip4:192.0.2.0/24 ip4:192.0.2.0/24 -all", want: []string{"192.0.2.0/24 > 192.0.2.0/24"}},
+		{name: "unmasked range", record: "v=spf1 ip4:192.0.2.0/24 ip4:192.0.2.5/24 -all", want: []string{"192.0.2.0/24 > 192.0.2.0/24"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, overlap := range findOverlappingNetworks(mustParseSPF(t, tt.record).Mechanisms) {
+				got = append(got, overlap[0].String()+" > "+overlap[1].String())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("findOverlappingNetworks() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSPFRedundantIPRangeWarning(t *testing.T) {
+	data := SPFDataSourceModel{Record: types.StringValue("v=spf1 ip4:192.0.2.0/24 ip4:192.0.2.10 -all")}
+	var diags diag.Diagnostics
+	(&SPFDataSource{}).read(context.Background(), &data, &diags)
+	if !hasDiagnostic(diags, diag.SeverityWarning, "Redundant SPF IP Range") {
+		t.Errorf("read() diagnostics = %v, want Redundant SPF IP Range", diags)
+	}
+}
+
+func TestSPFVoidLookupCount(t *testing.T) {
+	d := &SPFDataSource{providerData: &providerData{resolver: &fakeResolver{
+		addr: map[string][]net.IPAddr{
+			"v6only.example.com": {{IP: net.ParseIP("2001:db8::1")}},
+			"both.example.com":   {{IP: net.ParseIP("192.0.2.1")}, {IP: net.ParseIP("2001:db8::1")}},
+		},
+	}}}
+
+	tests := []struct {
+		name       string
+		record     string
+		wantVoid   int64
+		wantCounts []int64
+		wantErr    bool
+	}{
+		{
+			// exists only looks up A records, so an IPv6-only name is void for it
+			name:       "exists is A only",
+			record:     "v=spf1 a:v6only.example.com exists:v6only.example.com exists:both.example.com -all",
+			wantVoid:   1,
+			wantCounts: []int64{1, 0, 1},
+		},
+		{
+			name:       "limit exceeded",
+			record:     "v=spf1 exists:v6only.example.com exists:missing.example.com a:missing.example.com -all",
+			wantVoid:   3,
+			wantCounts: []int64{0, 0, 0},
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := SPFDataSourceModel{Record: types.StringValue(tt.record), Resolve: types.BoolValue(true)}
+			var diags diag.Diagnostics
+			d.read(context.Background(), &data, &diags)
+
+			if got := hasDiagnostic(diags, diag.SeverityError, "SPF Void Lookup Limit Exceeded"); got != tt.wantErr {
+				t.Errorf("read() diagnostics = %v, want SPF Void Lookup Limit Exceeded %v", diags, tt.wantErr)
+			}
+			if got := data.VoidLookupCount.ValueInt64(); got != tt.wantVoid {
+				t.Errorf("void_lookup_count = %d, want %d", got, tt.wantVoid)
+			}
+			for i, want := range tt.wantCounts {
+				mech := data.Mechanisms.Elements()[i].(types.Object)
+				if got := mech.Attributes()["resolved_count"].(types.Int64).ValueInt64(); got != want {
+					t.Errorf("mechanisms[%d].resolved_count = %d, want %d", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestOrderingHint(t *testing.T) {
+	tests := []struct {
+		name   string
+		record string
+		want   string
+	}{
+		{name: "ranges first", record: "v=spf1 ip4:192.0.2.0/24 include:_spf.example.com -all"},
+		{name: "no ranges", record: "v=spf1 include:_spf.example.com -all"},
+		{name: "softfail", record: "v=spf1 include:_spf.example.com ip4:192.0.2.0/24 ~all"},
+		{name: "redirect", record: "v=spf1 include:_spf.example.com ip4:192.0.2.0/24 redirect=_spf2.example.com"},
+		{
+			name:   "range after include",
+			record: "v=spf1 include:_spf.example.com ip4:192.0.2.0/24 -all",
+			want:   "Consider moving ip4:192.0.2.0/24 ahead of include:_spf.example.com",
+		},
+		{
+			// Includes after the last range are not mentioned
+			name:   "mixed",
+			record: "v=spf1 ip4:192.0.2.0/24 include:a.example.com ip6:2001:db8::/32 include:b.example.com -all",
+			want:   "Consider moving ip6:2001:db8::/32 ahead of include:a.example.com:",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := orderingHint(mustParseSPF(t, tt.record))
+			if tt.want == "" {
+				if got != "" {
+					t.Errorf("orderingHint() = %q, want no hint", got)
+				}
+				return
+			}
+			if !strings.HasPrefix(got, tt.want) {
+				t.Errorf("orderingHint() = %q, want prefix %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSPFNoTerminalMechanism(t *testing.T) {
+	tests := []struct {
+		name        string
+		record      string
+		wantWarning bool
+	}{
+		{name: "no all or redirect", record: "v=spf1 ip4:192.0.2.0/24 include:_spf.example.com", wantWarning: true},
+		{name: "version only", record: "v=spf1", wantWarning: true},
+		{name: "fail all", record: "v=spf1 ip4:192.0.2.0/24 -all"},
+		{name: "neutral all", record: "v=spf1 ip4:192.0.2.0/24 ?all"},
+		{name: "redirect", record: "v=spf1 ip4:192.0.2.0/24 redirect=_spf.example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := SPFDataSourceModel{Record: types.StringValue(tt.record)}
+			var diags diag.Diagnostics
+			(&SPFDataSource{}).read(context.Background(), &data, &diags)
+			if got := hasDiagnostic(diags, diag.SeverityWarning, "SPF Record Has No Terminal Mechanism"); got != tt.wantWarning {
+				t.Errorf("read() diagnostics = %v, want SPF Record Has No Terminal Mechanism %v", diags, tt.wantWarning)
+			}
+		})
+	}
+}
+
+func TestLookupBreakdown(t *testing.T) {
+	type entry struct {
+		mechanism     string
+		costsLookup   bool
+		nestedLookups int64 // -1 for null
+	}
+	loop := fmt.Errorf("%w: loop.example.com -> loop.example.com", errSPFIncludeLoop)
+
+	tests := []struct {
+		name        string
+		record      string
+		walks       []spfTargetWalk
+		resolve     bool
+		want        []entry
+		wantWarning bool
+	}{
+		{
+			name:   "not resolved",
+			record: "v=spf1 ip4:192.0.2.0/24 a include:_spf.example.com redirect=next.example.com",
+			walks:  []spfTargetWalk{{target: "_spf.example.com", include: true, lookups: 3}},
+			want: []entry{
+				{mechanism: "ip4:192.0.2.0/24", nestedLookups: 0},
+				{mechanism: "a", costsLookup: true, nestedLookups: 0},
+				{mechanism: "include:_spf.example.com", costsLookup: true, nestedLookups: -1},
+				{mechanism: "redirect=next.example.com", costsLookup: true, nestedLookups: -1},
+			},
+		},
+		{
+			name:   "resolved",
+			record: "v=spf1 mx include:_spf.example.com redirect=next.example.com",
+			walks: []spfTargetWalk{
+				{target: "_spf.example.com", include: true, lookups: 3},
+				{target: "next.example.com", lookups: 2},
+			},
+			resolve: true,
+			want: []entry{
+				{mechanism: "mx", costsLookup: true, nestedLookups: 0},
+				{mechanism: "include:_spf.example.com", costsLookup: true, nestedLookups: 3},
+				{mechanism: "redirect=next.example.com", costsLookup: true, nestedLookups: 2},
+			},
+		},
+		{
+			// Targets using macros are not walked
+			name:    "missing walk",
+			record:  "v=spf1 include:%{d}.example.com -all",
+			resolve: true,
+			want: []entry{
+				{mechanism: "include:%{d}.example.com", costsLookup: true, nestedLookups: -1},
+				{mechanism: "-all", nestedLookups: 0},
+			},
+		},
+		{
+			name:    "failed walk",
+			record:  "v=spf1 include:missing.example.com -all",
+			walks:   []spfTargetWalk{{target: "missing.example.com", include: true, err: errors.New("no SPF record found at missing.example.com")}},
+			resolve: true,
+			want: []entry{
+				{mechanism: "include:missing.example.com", costsLookup: true, nestedLookups: -1},
+				{mechanism: "-all", nestedLookups: 0},
+			},
+			wantWarning: true,
+		},
+		{
+			// read reports the loop itself
+			name:    "include loop",
+			record:  "v=spf1 include:loop.example.com -all",
+			walks:   []spfTargetWalk{{target: "loop.example.com", include: true, err: loop}},
+			resolve: true,
+			want: []entry{
+				{mechanism: "include:loop.example.com", costsLookup: true, nestedLookups: -1},
+				{mechanism: "-all", nestedLookups: 0},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var diags diag.Diagnostics
+			list := lookupBreakdown(mustParseSPF(t, tt.record), tt.walks, tt.resolve, &diags)
+
+			wantDiags := 0
+			if tt.wantWarning {
+				wantDiags = 1
+			}
+			if len(diags) != wantDiags || (tt.wantWarning && !hasDiagnostic(diags, diag.SeverityWarning, "SPF Lookup Breakdown Incomplete")) {
+				t.Errorf("lookupBreakdown() diagnostics = %v, want SPF Lookup Breakdown Incomplete %v", diags, tt.wantWarning)
+			}
+
+			var got []entry
+			for _, elem := range list.Elements() {
+				attrs := elem.(types.Object).Attributes()
+				e := entry{
+					mechanism:     attrs["mechanism"].(types.String).ValueString(),
+					costsLookup:   attrs["costs_lookup"].(types.Bool).ValueBool(),
+					nestedLookups: -1,
+				}
+				if nested := attrs["nested_lookups"].(types.Int64); !nested.IsNull() {
+					e.nestedLookups = nested.ValueInt64()
+				}
+				got = append(got, e)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("lookupBreakdown() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSPFMechanismCounts(t *testing.T) {
+	data := SPFDataSourceModel{Record: types.StringValue("v=spf1 ip4:192.0.2.0/24 include:_spf.google.com include:amazonses.com ~mx -all")}
+	var diags diag.Diagnostics
+	(&SPFDataSource{}).read(context.Background(), &data, &diags)
+	if diags.HasError() {
+		t.Fatalf("read() diagnostics = %v", diags)
+	}
+
+	want := types.MapValueMust(types.Int64Type, map[string]attr.Value{
+		"ip4":     types.Int64Value(1),
+		"include": types.Int64Value(2),
+		"mx":      types.Int64Value(1),
+		"all":     types.Int64Value(1),
+	})
+	if !data.MechanismCounts.Equal(want) {
+		t.Errorf("mechanism_counts = %v, want %v", data.MechanismCounts, want)
+	}
+}
@@ -0,0 +1,62 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/wttw/spf"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &SPFIncludesFunction{}
+
+func NewSPFIncludesFunction() function.Function {
+	return &SPFIncludesFunction{}
+}
+
+// SPFIncludesFunction defines the function implementation.
+type SPFIncludesFunction struct{}
+
+func (f *SPFIncludesFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "spf_includes"
+}
+
+func (f *SPFIncludesFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "List the include targets of an SPF record",
+		MarkdownDescription: "Returns the domain specs of the `include` mechanisms of an SPF record, in record order, without any DNS lookups. " +
+			"The records of the included domains are not fetched, so their own includes are not listed. " +
+			"Returns an empty list for a record without includes and fails if the record is not a valid SPF record.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "record",
+				MarkdownDescription: "The SPF record content (e.g., `v=spf1 include:_spf.google.com ~all`)",
+			},
+		},
+		Return: function.ListReturn{ElementType: types.StringType},
+	}
+}
+
+func (f *SPFIncludesFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var record string
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &record))
+	if resp.Error != nil {
+		return
+	}
+
+	parsed, err := parseSPFArgument(record)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, "Invalid SPF record: "+err.Error())
+		return
+	}
+
+	includes := []string{}
+	for _, m := range parsed.Mechanisms {
+		if include, ok := m.(spf.MechanismInclude); ok {
+			includes = append(includes, include.DomainSpec)
+		}
+	}
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, includes))
+}
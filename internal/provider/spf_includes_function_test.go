@@ -0,0 +1,42 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestSPFIncludesFunction(t *testing.T) {
+	tests := []struct {
+		name    string
+		record  string
+		want    []string
+		wantErr bool
+	}{
+		{name: "includes", record: "v=spf1 include:_spf.google.com ip4:192.0.2.0/24 -include:mailgun.org ~all", want: []string{"_spf.google.com", "mailgun.org"}},
+		{name: "no includes", record: "v=spf1 mx redirect=_spf.example.com", want: []string{}},
+		{name: "invalid", record: "v=spf1 include", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := function.RunRequest{Arguments: function.NewArgumentsData([]attr.Value{types.StringValue(tt.record)})}
+			resp := function.RunResponse{Result: function.NewResultData(types.ListUnknown(types.StringType))}
+			NewSPFIncludesFunction().Run(context.Background(), req, &resp)
+
+			if (resp.Error != nil) != tt.wantErr {
+				t.Fatalf("Run() error = %v, wantErr %v", resp.Error, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			want, _ := types.ListValueFrom(context.Background(), types.StringType, tt.want)
+			if got := resp.Result.Value(); !got.Equal(want) {
+				t.Errorf("Run() = %v, want %v", got, want)
+			}
+		})
+	}
+}
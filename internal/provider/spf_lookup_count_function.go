@@ -0,0 +1,69 @@
+package provider
+
+import (
+	"context"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/wttw/spf"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &SPFLookupCountFunction{}
+
+func NewSPFLookupCountFunction() function.Function {
+	return &SPFLookupCountFunction{}
+}
+
+// SPFLookupCountFunction defines the function implementation.
+type SPFLookupCountFunction struct{}
+
+func (f *SPFLookupCountFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "spf_lookup_count"
+}
+
+func (f *SPFLookupCountFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Count the DNS lookups of an SPF record",
+		MarkdownDescription: "Returns the number of DNS lookups an SPF record requires, counting each `include`, `a`, `mx`, `ptr`, and `exists` " +
+			"mechanism plus the `redirect` modifier, the same way as the `dns_lookup_count` attribute of the `emaildns_spf` data source. " +
+			"Lookups made by included records are not counted. Fails if the record is not a valid SPF record.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "record",
+				MarkdownDescription: "The SPF record content (e.g., `v=spf1 include:_spf.google.com ~all`)",
+			},
+		},
+		Return: function.Int64Return{},
+	}
+}
+
+func (f *SPFLookupCountFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var record string
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &record))
+	if resp.Error != nil {
+		return
+	}
+
+	parsed, err := parseSPFArgument(record)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, "Invalid SPF record: "+err.Error())
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, int64(countSPFLookups(parsed))))
+}
+
+// parseSPFArgument parses an SPF record passed to a function, applying the
+// syntax checks of validateSPFRecord that reject a record.
+func parseSPFArgument(record string) (*spf.SPFRecord, error) {
+	if err := checkSPFCharacters(record); err != nil {
+		return nil, err
+	}
+	record = strings.Trim(record, " ")
+	if err := checkSPFVersion(record); err != nil {
+		return nil, err
+	}
+	return spf.ParseSPF(record)
+}
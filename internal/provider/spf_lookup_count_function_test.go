@@ -0,0 +1,45 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestSPFLookupCountFunction(t *testing.T) {
+	tests := []struct {
+		name    string
+		record  string
+		want    int64
+		wantErr bool
+	}{
+		{name: "no lookups", record: "v=spf1 ip4:192.0.2.0/24 -all", want: 0},
+		{name: "lookups", record: "v=spf1 include:_spf.google.com a mx ptr exists:%{i}.example.com ip4:192.0.2.1 -all", want: 5},
+		{name: "redirect", record: "v=spf1 mx redirect=_spf.example.com", want: 2},
+		{name: "surrounding spaces", record: " v=spf1 a -all ", want: 1},
+		{name: "invalid mechanism", record: "v=spf1 bogus -all", wantErr: true},
+		{name: "wrong version", record: "v=spf10 -all", wantErr: true},
+		{name: "control character", record: "v=spf1\ta -all", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := function.RunRequest{Arguments: function.NewArgumentsData([]attr.Value{types.StringValue(tt.record)})}
+			resp := function.RunResponse{Result: function.NewResultData(types.Int64Unknown())}
+			NewSPFLookupCountFunction().Run(context.Background(), req, &resp)
+
+			if (resp.Error != nil) != tt.wantErr {
+				t.Fatalf("Run() error = %v, wantErr %v", resp.Error, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := resp.Result.Value(); !got.Equal(types.Int64Value(tt.want)) {
+				t.Errorf("Run() = %v, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,228 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/wttw/spf"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var (
+	_ datasource.DataSource                   = &SPFMergeDataSource{}
+	_ datasource.DataSourceWithValidateConfig = &SPFMergeDataSource{}
+	_ datasource.DataSourceWithConfigure      = &SPFMergeDataSource{}
+)
+
+// maxSPFLookups is the RFC 7208 section 4.6.4 limit on DNS-querying terms.
+const maxSPFLookups = 10
+
+// maxTXTStringLength is the maximum length of a single TXT character-string.
+const maxTXTStringLength = 255
+
+func NewSPFMergeDataSource() datasource.DataSource {
+	return &SPFMergeDataSource{}
+}
+
+// SPFMergeDataSource defines the data source implementation.
+type SPFMergeDataSource struct {
+	providerData *providerData
+}
+
+// SPFMergeDataSourceModel describes the data source data model.
+type SPFMergeDataSourceModel struct {
+	Records        types.List   `tfsdk:"records"`
+	ChangeTicket   types.String `tfsdk:"change_ticket"`
+	MergedRecord   types.String `tfsdk:"merged_record"`
+	DNSLookupCount types.Int64  `tfsdk:"dns_lookup_count"`
+}
+
+func (d *SPFMergeDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_spf_merge"
+}
+
+func (d *SPFMergeDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Merges several SPF record fragments into a single SPF DNS TXT record. " +
+			"If the fragments are invalid or the merged record exceeds the SPF lookup limit, terraform plan will fail with a specific error message.",
+
+		Attributes: map[string]schema.Attribute{
+			"records": schema.ListAttribute{
+				MarkdownDescription: "The SPF TXT record fragments to merge (e.g., `[\"v=spf1 include:_spf.google.com ~all\", \"v=spf1 ip4:192.0.2.0/24 -all\"]`)",
+				Required:            true,
+				ElementType:         types.StringType,
+			},
+			"change_ticket": changeTicketAttribute(),
+			"merged_record": schema.StringAttribute{
+				MarkdownDescription: "The merged SPF record, with duplicate mechanisms removed and a single terminal `all` using the strictest qualifier present",
+				Computed:            true,
+			},
+			"dns_lookup_count": schema.Int64Attribute{
+				MarkdownDescription: "Number of mechanisms in the merged record that require DNS lookups (SPF allows max 10)",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (d *SPFMergeDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	d.providerData = configureProviderData(req, resp)
+}
+
+func (d *SPFMergeDataSource) ValidateConfig(ctx context.Context, req datasource.ValidateConfigRequest, resp *datasource.ValidateConfigResponse) {
+	defer d.providerData.applyWarningSettings(&resp.Diagnostics)
+
+	var data SPFMergeDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Skip validation if records are unknown (e.g., depend on another resource)
+	if data.Records.IsUnknown() {
+		return
+	}
+
+	var records []types.String
+	resp.Diagnostics.Append(data.Records.ElementsAs(ctx, &records, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Validate each SPF fragment
+	for i, r := range records {
+		if r.IsUnknown() || r.IsNull() {
+			continue
+		}
+		record := r.ValueString()
+		if _, err := spf.ParseSPF(record); err != nil {
+			resp.Diagnostics.AddError(
+				summarySPFParseError,
+				fmt.Sprintf("SPF record %d is malformed: %s\n\nRecord: %s", i, err.Error(), record),
+			)
+		}
+	}
+}
+
+func (d *SPFMergeDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	defer d.providerData.applyWarningSettings(&resp.Diagnostics)
+
+	var data SPFMergeDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data.ChangeTicket = d.providerData.checkChangeTicket(data.ChangeTicket, &resp.Diagnostics)
+
+	var records []string
+	resp.Diagnostics.Append(data.Records.ElementsAs(ctx, &records, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	merged, lookups, err := mergeSPFRecords(records)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			summarySPFMergeFailed,
+			err.Error(),
+		)
+		return
+	}
+
+	if lookups > maxSPFLookups {
+		resp.Diagnostics.AddError(
+			summarySPFLookupLimitExceeded,
+			fmt.Sprintf("The merged SPF record requires %d DNS lookups, but RFC 7208 allows at most %d.\n\nRecord: %s", lookups, maxSPFLookups, merged),
+		)
+		return
+	}
+
+	if len(merged) > maxTXTStringLength {
+		resp.Diagnostics.AddWarning(
+			summarySPFTXTStringLength,
+			fmt.Sprintf("The merged SPF record is %d bytes, longer than the %d-byte limit for a single TXT string. "+
+				"It must be published as multiple strings, which some DNS providers do not handle automatically.", len(merged), maxTXTStringLength),
+		)
+	}
+
+	data.MergedRecord = types.StringValue(merged)
+	data.DNSLookupCount = types.Int64Value(int64(lookups))
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// mergeSPFRecords combines SPF record fragments into a single record. Mechanisms
+// keep their first-seen order and exact duplicates are dropped. All "all"
+// mechanisms are collapsed into one terminal "all" using the strictest
+// qualifier found. It returns the merged record and its DNS lookup count.
+func mergeSPFRecords(records []string) (string, int, error) {
+	if len(records) == 0 {
+		return "", 0, errors.New("at least one SPF record is required")
+	}
+
+	var terms []string
+	seen := make(map[string]bool)
+	lookups := 0
+	hasAll := false
+	allQualifier := spf.Pass
+
+	for i, record := range records {
+		parsed, err := spf.ParseSPF(record)
+		if err != nil {
+			return "", 0, fmt.Errorf("SPF record %d is malformed: %w", i, err)
+		}
+		if parsed.Redirect != "" || parsed.Exp != "" || len(parsed.OtherModifiers) > 0 {
+			return "", 0, fmt.Errorf("SPF record %d contains modifiers, which cannot be merged; use include: instead of redirect=", i)
+		}
+
+		for _, m := range parsed.Mechanisms {
+			if all, ok := m.(spf.MechanismAll); ok {
+				if !hasAll || qualifierStrictness(all.Qualifier) > qualifierStrictness(allQualifier) {
+					allQualifier = all.Qualifier
+				}
+				hasAll = true
+				continue
+			}
+
+			key := strings.ToLower(m.String())
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			if requiresDNSLookup(m) {
+				lookups++
+			}
+			terms = append(terms, m.String())
+		}
+	}
+
+	if hasAll {
+		terms = append(terms, spf.MechanismAll{Qualifier: allQualifier}.String())
+	}
+
+	return strings.Join(append([]string{"v=spf1"}, terms...), " "), lookups, nil
+}
+
+// qualifierStrictness ranks SPF qualifiers from most permissive (pass) to
+// strictest (fail).
+func qualifierStrictness(q spf.ResultType) int {
+	switch q {
+	case spf.Fail:
+		return 3
+	case spf.Softfail:
+		return 2
+	case spf.Neutral:
+		return 1
+	default:
+		return 0
+	}
+}
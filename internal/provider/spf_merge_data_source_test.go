@@ -0,0 +1,81 @@
+package provider
+
+import (
+	"testing"
+)
+
+func TestMergeSPFRecords(t *testing.T) {
+	tests := []struct {
+		name        string
+		records     []string
+		wantRecord  string
+		wantLookups int
+		wantErr     bool
+	}{
+		{
+			name:        "single record",
+			records:     []string{"v=spf1 include:_spf.google.com ~all"},
+			wantRecord:  "v=spf1 include:_spf.google.com ~all",
+			wantLookups: 1,
+		},
+		{
+			name: "duplicates removed",
+			records: []string{
+				"v=spf1 ip4:192.0.2.0/24 include:_spf.google.com ~all",
+				"v=spf1 ip4:192.0.2.0/24 include:amazonses.com include:_spf.google.com ~all",
+			},
+			wantRecord:  "v=spf1 ip4:192.0.2.0/24 include:_spf.google.com include:amazonses.com ~all",
+			wantLookups: 2,
+		},
+		{
+			name: "strictest all wins",
+			records: []string{
+				"v=spf1 ip4:192.0.2.1 ?all",
+				"v=spf1 ip6:2001:db8::/32 -all",
+				"v=spf1 a ~all",
+			},
+			wantRecord:  "v=spf1 ip4:192.0.2.1/32 ip6:2001:db8::/32 a -all",
+			wantLookups: 1,
+		},
+		{
+			name:        "no all mechanism",
+			records:     []string{"v=spf1 mx", "v=spf1 a"},
+			wantRecord:  "v=spf1 mx a",
+			wantLookups: 2,
+		},
+		{
+			name:    "redirect cannot be merged",
+			records: []string{"v=spf1 redirect=_spf.example.com", "v=spf1 a -all"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid fragment",
+			records: []string{"v=spf1 include:_spf.google.com ~all", "include:x -all"},
+			wantErr: true,
+		},
+		{
+			name:    "no records",
+			records: nil,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			record, lookups, err := mergeSPFRecords(tt.records)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("mergeSPFRecords() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if record != tt.wantRecord {
+				t.Errorf("mergeSPFRecords() record = %q, want %q", record, tt.wantRecord)
+			}
+			if lookups != tt.wantLookups {
+				t.Errorf("mergeSPFRecords() lookups = %d, want %d", lookups, tt.wantLookups)
+			}
+		})
+	}
+}
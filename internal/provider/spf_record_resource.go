@@ -0,0 +1,350 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/wttw/spf"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var (
+	_ resource.Resource                   = &SPFRecordResource{}
+	_ resource.ResourceWithValidateConfig = &SPFRecordResource{}
+	_ resource.ResourceWithModifyPlan     = &SPFRecordResource{}
+)
+
+// spfRecordMechanisms lists the list attributes of emaildns_spf_record and
+// the mechanisms they become, in the order they are written to the record:
+// address ranges first, since they need no DNS lookups, then a and mx, and
+// includes last.
+var spfRecordMechanisms = []struct {
+	attribute string
+	mechanism string
+}{
+	{attribute: "ip4", mechanism: "ip4"},
+	{attribute: "ip6", mechanism: "ip6"},
+	{attribute: "a", mechanism: "a"},
+	{attribute: "mx", mechanism: "mx"},
+	{attribute: "includes", mechanism: "include"},
+}
+
+func NewSPFRecordResource() resource.Resource {
+	return &SPFRecordResource{}
+}
+
+// SPFRecordResource assembles an SPF record from its mechanisms.
+type SPFRecordResource struct{}
+
+// SPFRecordResourceModel describes the resource data model.
+type SPFRecordResourceModel struct {
+	ID             types.String `tfsdk:"id"`
+	Includes       types.List   `tfsdk:"includes"`
+	IP4            types.List   `tfsdk:"ip4"`
+	IP6            types.List   `tfsdk:"ip6"`
+	A              types.List   `tfsdk:"a"`
+	MX             types.List   `tfsdk:"mx"`
+	All            types.String `tfsdk:"all"`
+	Record         types.String `tfsdk:"record"`
+	DNSLookupCount types.Int64  `tfsdk:"dns_lookup_count"`
+}
+
+// list returns the value of one of the list attributes in
+// spfRecordMechanisms.
+func (m *SPFRecordResourceModel) list(attribute string) types.List {
+	switch attribute {
+	case "includes":
+		return m.Includes
+	case "ip4":
+		return m.IP4
+	case "ip6":
+		return m.IP6
+	case "a":
+		return m.A
+	default:
+		return m.MX
+	}
+}
+
+func (r *SPFRecordResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_spf_record"
+}
+
+func (r *SPFRecordResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Assembles an SPF record from its mechanisms, so records can be written declaratively instead of by joining strings. " +
+			"The record is known at plan time when every input is, and terraform plan fails if it is invalid, needs more than 10 DNS lookups, " +
+			"or is longer than a single 255-byte TXT string.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				MarkdownDescription: "The assembled record",
+				Computed:            true,
+			},
+			"includes": schema.ListAttribute{
+				MarkdownDescription: "Domains whose SPF records are included (e.g., `[\"_spf.google.com\"]`). Each costs one DNS lookup",
+				Optional:            true,
+				ElementType:         types.StringType,
+			},
+			"ip4": schema.ListAttribute{
+				MarkdownDescription: "IPv4 addresses or networks allowed to send (e.g., `[\"192.0.2.0/24\"]`)",
+				Optional:            true,
+				ElementType:         types.StringType,
+			},
+			"ip6": schema.ListAttribute{
+				MarkdownDescription: "IPv6 addresses or networks allowed to send (e.g., `[\"2001:db8::/32\"]`)",
+				Optional:            true,
+				ElementType:         types.StringType,
+			},
+			"a": schema.ListAttribute{
+				MarkdownDescription: "Domains whose A and AAAA records are allowed to send, optionally with a prefix length (e.g., `[\"mail.example.com\"]`). " +
+					"An empty string adds a bare `a` for the domain the record is published at. Each costs one DNS lookup",
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+			"mx": schema.ListAttribute{
+				MarkdownDescription: "Domains whose MX hosts are allowed to send (e.g., `[\"example.com\"]`). " +
+					"An empty string adds a bare `mx` for the domain the record is published at. Each costs one DNS lookup",
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+			"all": schema.StringAttribute{
+				MarkdownDescription: "Qualifier of the terminal `all` mechanism: `-` (fail), `~` (softfail), or `?` (neutral). Defaults to `~`",
+				Optional:            true,
+				Computed:            true,
+				Default:             stringdefault.StaticString("~"),
+			},
+			"record": schema.StringAttribute{
+				MarkdownDescription: "The assembled SPF record, with mechanisms in the order `ip4`, `ip6`, `a`, `mx`, `include`, then `all` " +
+					"(e.g., `v=spf1 ip4:192.0.2.0/24 include:_spf.google.com ~all`)",
+				Computed: true,
+			},
+			"dns_lookup_count": schema.Int64Attribute{
+				MarkdownDescription: "Number of mechanisms in the record that require DNS lookups (SPF allows max 10)",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (r *SPFRecordResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	defer addDiagnosticCodes(&resp.Diagnostics)
+
+	var data SPFRecordResourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// all is null in the configuration when the default applies
+	if data.All.IsNull() {
+		data.All = types.StringValue("~")
+	}
+	assembleSPFRecord(&data, &resp.Diagnostics)
+}
+
+// ModifyPlan fills in the record during planning, so that it shows in the
+// plan and can be used by other resources before apply.
+func (r *SPFRecordResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	defer addDiagnosticCodes(&resp.Diagnostics)
+
+	// Nothing to do when the resource is being destroyed
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var data SPFRecordResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// ValidateConfig has already reported any problem with the inputs
+	var diags diag.Diagnostics
+	if !assembleSPFRecord(&data, &diags) {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.Plan.Set(ctx, &data)...)
+}
+
+func (r *SPFRecordResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	defer addDiagnosticCodes(&resp.Diagnostics)
+
+	var data SPFRecordResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !assembleSPFRecord(&data, &resp.Diagnostics) {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// Read keeps the stored record, since it only depends on the configuration.
+func (r *SPFRecordResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data SPFRecordResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *SPFRecordResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	defer addDiagnosticCodes(&resp.Diagnostics)
+
+	var data SPFRecordResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !assembleSPFRecord(&data, &resp.Diagnostics) {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// Delete only removes the record from state.
+func (r *SPFRecordResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+}
+
+// assembleSPFRecord builds the record from the inputs in data and sets the
+// computed attributes. Invalid values are reported against their attribute.
+// It returns false when the record is invalid or an input is not yet known.
+func assembleSPFRecord(data *SPFRecordResourceModel, diags *diag.Diagnostics) bool {
+	terms := []string{"v=spf1"}
+	known := true
+	valid := true
+
+	for _, m := range spfRecordMechanisms {
+		list := data.list(m.attribute)
+		if list.IsUnknown() {
+			known = false
+			continue
+		}
+		for i, element := range list.Elements() {
+			value, ok := element.(types.String)
+			if !ok || value.IsUnknown() {
+				known = false
+				continue
+			}
+			term, err := spfRecordTerm(m.mechanism, value)
+			if err != nil {
+				diags.AddAttributeError(
+					path.Root(m.attribute).AtListIndex(i),
+					summarySPFInvalidMechanism,
+					fmt.Sprintf("The %s value is invalid: %s", m.attribute, err.Error()),
+				)
+				valid = false
+				continue
+			}
+			terms = append(terms, term)
+		}
+	}
+
+	if data.All.IsUnknown() {
+		known = false
+	} else if qualifier := data.All.ValueString(); qualifier != "-" && qualifier != "~" && qualifier != "?" {
+		diags.AddAttributeError(
+			path.Root("all"),
+			summarySPFInvalidAllQualifier,
+			fmt.Sprintf("all must be -, ~, or ?, got %q. A + qualifier would allow every host on the internet to send mail for the domain.", qualifier),
+		)
+		valid = false
+	} else {
+		terms = append(terms, qualifier+"all")
+	}
+
+	if !known || !valid {
+		return false
+	}
+
+	record := strings.Join(terms, " ")
+	parsed, err := spf.ParseSPF(record)
+	if err != nil {
+		diags.AddError(
+			summarySPFParseError,
+			fmt.Sprintf("The assembled SPF record is malformed: %s\n\nRecord: %s", err.Error(), record),
+		)
+		return false
+	}
+
+	lookups := countSPFLookups(parsed)
+	if lookups > maxSPFLookups {
+		diags.AddError(
+			summarySPFLookupLimitExceeded,
+			fmt.Sprintf("The assembled SPF record requires %d DNS lookups, but RFC 7208 allows at most %d. "+
+				"Replace a, mx, or includes with ip4 and ip6 ranges.\n\nRecord: %s", lookups, maxSPFLookups, record),
+		)
+		return false
+	}
+	if len(record) > maxTXTStringLength {
+		diags.AddError(
+			summarySPFRecordTooLong,
+			fmt.Sprintf("The assembled SPF record is %d bytes, longer than the %d-byte limit for a single TXT string. "+
+				"Combine address ranges or move mechanisms into an included record.\n\nRecord: %s", len(record), maxTXTStringLength, record),
+		)
+		return false
+	}
+
+	data.ID = types.StringValue(record)
+	data.Record = types.StringValue(record)
+	data.DNSLookupCount = types.Int64Value(int64(lookups))
+	return true
+}
+
+// spfRecordTerm returns the mechanism term for a value of one of the list
+// attributes of emaildns_spf_record.
+func spfRecordTerm(mechanism string, value types.String) (string, error) {
+	if value.IsNull() {
+		return "", fmt.Errorf("must not be null")
+	}
+	v := strings.TrimSpace(value.ValueString())
+
+	switch mechanism {
+	case "ip4", "ip6":
+		addr, err := netip.ParseAddr(v)
+		if prefix, perr := netip.ParsePrefix(v); perr == nil {
+			addr, err = prefix.Addr(), nil
+		}
+		if err != nil || addr.Is4() != (mechanism == "ip4") {
+			return "", fmt.Errorf("%q is not an IPv%s address or network", v, mechanism[2:])
+		}
+	case "include":
+		if v == "" {
+			return "", fmt.Errorf("must not be empty")
+		}
+	}
+
+	term := mechanism
+	if v != "" {
+		term += ":" + v
+	}
+	if _, _, err := spfDualCIDR(term); err != nil {
+		return "", err
+	}
+	if _, err := spf.ParseSPF("v=spf1 " + term); err != nil {
+		return "", err
+	}
+	return term, nil
+}
@@ -0,0 +1,121 @@
+package provider
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestAssembleSPFRecord(t *testing.T) {
+	list := func(values ...string) types.List {
+		elements := make([]attr.Value, len(values))
+		for i, v := range values {
+			elements[i] = types.StringValue(v)
+		}
+		return types.ListValueMust(types.StringType, elements)
+	}
+	empty := types.ListNull(types.StringType)
+
+	tests := []struct {
+		name        string
+		data        SPFRecordResourceModel
+		want        string
+		wantLookups int64
+		wantErr     string
+	}{
+		{
+			name: "all mechanisms",
+			data: SPFRecordResourceModel{
+				Includes: list("_spf.google.com"), IP4: list("192.0.2.0/24", "198.51.100.7"), IP6: list("2001:db8::/32"),
+				A: list("", "mail.example.com"), MX: list("example.com"), All: types.StringValue("-"),
+			},
+			want:        "v=spf1 ip4:192.0.2.0/24 ip4:198.51.100.7 ip6:2001:db8::/32 a a:mail.example.com mx:example.com include:_spf.google.com -all",
+			wantLookups: 4,
+		},
+		{
+			name: "only all",
+			data: SPFRecordResourceModel{Includes: empty, IP4: empty, IP6: empty, A: empty, MX: empty, All: types.StringValue("~")},
+			want: "v=spf1 ~all",
+		},
+		{
+			name:    "ipv6 in ip4",
+			data:    SPFRecordResourceModel{Includes: empty, IP4: list("2001:db8::1"), IP6: empty, A: empty, MX: empty, All: types.StringValue("-")},
+			wantErr: "Invalid SPF Mechanism",
+		},
+		{
+			name:    "empty include",
+			data:    SPFRecordResourceModel{Includes: list(""), IP4: empty, IP6: empty, A: empty, MX: empty, All: types.StringValue("-")},
+			wantErr: "Invalid SPF Mechanism",
+		},
+		{
+			name:    "pass all",
+			data:    SPFRecordResourceModel{Includes: empty, IP4: empty, IP6: empty, A: empty, MX: empty, All: types.StringValue("+")},
+			wantErr: "Invalid SPF All Qualifier",
+		},
+		{
+			name: "too many lookups",
+			data: SPFRecordResourceModel{
+				Includes: list("a.example", "b.example", "c.example", "d.example", "e.example", "f.example", "g.example", "h.example", "i.example", "j.example", "k.example"),
+				IP4:      empty, IP6: empty, A: empty, MX: empty, All: types.StringValue("-"),
+			},
+			wantErr: "SPF Lookup Limit Exceeded",
+		},
+		{
+			name: "too long",
+			data: SPFRecordResourceModel{
+				Includes: empty, IP6: empty, A: empty, MX: empty, All: types.StringValue("-"),
+				IP4: func() types.List {
+					var networks []string
+					for i := 0; i < 20; i++ {
+						networks = append(networks, fmt.Sprintf("192.0.2.%d/32", i*8))
+					}
+					return list(networks...)
+				}(),
+			},
+			wantErr: "SPF Record Too Long",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var diags diag.Diagnostics
+			ok := assembleSPFRecord(&tt.data, &diags)
+
+			if tt.wantErr != "" {
+				if ok || !diags.HasError() || diags.Errors()[0].Summary() != tt.wantErr {
+					t.Fatalf("assembleSPFRecord() = %t, diagnostics %v, want %q", ok, diags, tt.wantErr)
+				}
+				return
+			}
+			if !ok || diags.HasError() {
+				t.Fatalf("assembleSPFRecord() = %t, diagnostics %v", ok, diags)
+			}
+			if got := tt.data.Record.ValueString(); got != tt.want {
+				t.Errorf("record = %q, want %q", got, tt.want)
+			}
+			if got := tt.data.DNSLookupCount.ValueInt64(); got != tt.wantLookups {
+				t.Errorf("dns_lookup_count = %d, want %d", got, tt.wantLookups)
+			}
+		})
+	}
+}
+
+func TestAssembleSPFRecordUnknown(t *testing.T) {
+	data := SPFRecordResourceModel{
+		Includes: types.ListUnknown(types.StringType),
+		IP4:      types.ListNull(types.StringType), IP6: types.ListNull(types.StringType),
+		A: types.ListNull(types.StringType), MX: types.ListNull(types.StringType),
+		All: types.StringValue("-"),
+	}
+
+	var diags diag.Diagnostics
+	if assembleSPFRecord(&data, &diags) || diags.HasError() {
+		t.Errorf("assembleSPFRecord() with unknown includes = true or diagnostics %v", diags)
+	}
+	if !data.Record.IsNull() {
+		t.Errorf("record = %v, want it left unset", data.Record)
+	}
+}
@@ -0,0 +1,327 @@
+package provider
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/wttw/spf"
+)
+
+// maxSPFMXRecords is the RFC 7208 section 4.6.4 limit on the number of MX
+// records a single mx mechanism may process.
+const maxSPFMXRecords = 10
+
+// maxSPFVoidLookups is the RFC 7208 section 4.6.4 limit on lookups that
+// return no records.
+const maxSPFVoidLookups = 2
+
+// defaultMaxRedirectDepth is the number of redirect hops followed when the
+// data source does not set max_redirect_depth.
+const defaultMaxRedirectDepth = 10
+
+// isResolvableDomainSpec reports whether a domain-spec can be looked up
+// without evaluation context. Empty domain-specs refer to the domain being
+// checked and macros depend on the message, so neither can be resolved.
+func isResolvableDomainSpec(domainSpec string) bool {
+	return domainSpec != "" && !strings.Contains(domainSpec, "%")
+}
+
+// countMXHosts returns the number of MX records published for a domain.
+// A domain without MX records yields zero rather than an error.
+func countMXHosts(ctx context.Context, resolver dnsResolver, domain string) (int, error) {
+	mxs, err := resolver.LookupMX(ctx, domain)
+	if err != nil {
+		if isNotFound(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return len(mxs), nil
+}
+
+// countAddresses returns the number of A and AAAA records published for a
+// host. A host without addresses yields zero rather than an error.
+func countAddresses(ctx context.Context, resolver dnsResolver, host string) (int, error) {
+	addrs, err := resolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		if isNotFound(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return len(addrs), nil
+}
+
+// countIPv4Addresses returns the number of A records published for a host.
+// RFC 7208 section 5.7 has exists look up A records only, even when the
+// sender connects over IPv6. A host without A records yields zero rather than
+// an error.
+func countIPv4Addresses(ctx context.Context, resolver dnsResolver, host string) (int, error) {
+	ips, err := resolver.LookupIP(ctx, "ip4", host)
+	if err != nil {
+		if isNotFound(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return len(ips), nil
+}
+
+// resolveMechanism looks up the target of an a, mx, or exists mechanism and
+// returns the number of records found. An a or mx mechanism without a
+// domain-spec is looked up at domain, the domain the record is published at,
+// which is empty when unknown. resolved is false for mechanisms that are not
+// looked up directly or whose target cannot be resolved statically.
+func resolveMechanism(ctx context.Context, resolver dnsResolver, m spf.Mechanism, domain string) (count int, resolved bool, err error) {
+	switch m := m.(type) {
+	case spf.MechanismMX:
+		if target := mechanismTarget(m.DomainSpec, domain); isResolvableDomainSpec(target) {
+			count, err = countMXHosts(ctx, resolver, target)
+			return count, err == nil, err
+		}
+	case spf.MechanismA:
+		if target := mechanismTarget(m.DomainSpec, domain); isResolvableDomainSpec(target) {
+			count, err = countAddresses(ctx, resolver, target)
+			return count, err == nil, err
+		}
+	case spf.MechanismExists:
+		if isResolvableDomainSpec(m.DomainSpec) {
+			count, err = countIPv4Addresses(ctx, resolver, m.DomainSpec)
+			return count, err == nil, err
+		}
+	}
+	return 0, false, nil
+}
+
+// mechanismTarget returns the name an a or mx mechanism looks up: its
+// domain-spec, or the current domain when it has none (RFC 7208 sections 5.3
+// and 5.4).
+func mechanismTarget(domainSpec, domain string) string {
+	if domainSpec == "" {
+		return domain
+	}
+	return domainSpec
+}
+
+// isNotFound reports whether a lookup error means the name or record type
+// does not exist, as opposed to a transient or configuration failure.
+func isNotFound(err error) bool {
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr) && dnsErr.IsNotFound
+}
+
+// lookupSPFRecord fetches and parses the SPF record published at a domain.
+func lookupSPFRecord(ctx context.Context, resolver dnsResolver, domain string) (string, *spf.SPFRecord, error) {
+	record, err := findSPFRecord(ctx, resolver, domain)
+	if err != nil {
+		return "", nil, err
+	}
+
+	parsed, err := spf.ParseSPF(record)
+	if err != nil {
+		return "", nil, fmt.Errorf("SPF record at %s is malformed: %w", domain, err)
+	}
+	return record, parsed, nil
+}
+
+// findSPFRecord returns the SPF record published at a domain without parsing
+// it. RFC 7208 section 4.5 requires exactly one record beginning with
+// "v=spf1"; several are a permerror.
+func findSPFRecord(ctx context.Context, resolver dnsResolver, domain string) (string, error) {
+	txts, err := resolver.LookupTXT(ctx, domain)
+	if err != nil {
+		if isNotFound(err) {
+			return "", fmt.Errorf("no SPF record found at %s", domain)
+		}
+		return "", err
+	}
+
+	var records []string
+	for _, txt := range txts {
+		fields := strings.Fields(txt)
+		if len(fields) > 0 && strings.EqualFold(fields[0], "v=spf1") {
+			records = append(records, txt)
+		}
+	}
+
+	switch len(records) {
+	case 0:
+		return "", fmt.Errorf("no SPF record found at %s", domain)
+	case 1:
+		return records[0], nil
+	default:
+		return "", fmt.Errorf("%d SPF records found at %s, but only one is allowed; receivers treat this as a permerror", len(records), domain)
+	}
+}
+
+// errSPFIncludeLoop marks include trees in which a record includes itself,
+// directly or through other records.
+var errSPFIncludeLoop = errors.New("SPF include loop detected")
+
+// extendIncludeChain appends domain to the chain of records being evaluated,
+// failing with the offending chain if domain is already part of it.
+func extendIncludeChain(chain []string, domain string) ([]string, error) {
+	for _, d := range chain {
+		if strings.EqualFold(strings.TrimSuffix(d, "."), strings.TrimSuffix(domain, ".")) {
+			return nil, fmt.Errorf("%w: %s", errSPFIncludeLoop, strings.Join(append(chain, domain), " -> "))
+		}
+	}
+	return append(chain[:len(chain):len(chain)], domain), nil
+}
+
+// spfTargetWalk is the result of walking the tree below one include or
+// redirect target of a record.
+type spfTargetWalk struct {
+	target  string
+	include bool
+	lookups int
+	depth   int
+	err     error
+}
+
+// walkSPFTargets walks the tree below each include and redirect target of a
+// record once, so that the combined lookup count, the include depth and the
+// lookup breakdown are all derived from the same lookups. Targets that use
+// macros cannot be resolved and are skipped.
+func walkSPFTargets(ctx context.Context, resolver dnsResolver, parsed *spf.SPFRecord) []spfTargetWalk {
+	var walks []spfTargetWalk
+	walk := func(target string, include bool) {
+		if !isResolvableDomainSpec(target) {
+			return
+		}
+		lookups, depth, err := walkSPFTree(ctx, resolver, target, nil)
+		walks = append(walks, spfTargetWalk{target: target, include: include, lookups: lookups, depth: depth, err: err})
+	}
+
+	for _, m := range parsed.Mechanisms {
+		if inc, ok := m.(spf.MechanismInclude); ok {
+			walk(inc.DomainSpec, true)
+		}
+	}
+	if parsed.Redirect != "" {
+		walk(parsed.Redirect, false)
+	}
+	return walks
+}
+
+// walkSPFTree evaluates the SPF record at domain, following include and
+// redirect targets recursively. It returns the number of DNS lookups performed
+// and how deeply includes nest below the record: zero when the record has no
+// includes, one when its includes have none of their own, and so on. chain
+// holds the domains already being evaluated and is used to detect include
+// loops.
+func walkSPFTree(ctx context.Context, resolver dnsResolver, domain string, chain []string) (lookups, depth int, err error) {
+	chain, err = extendIncludeChain(chain, domain)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	_, parsed, err := lookupSPFRecord(ctx, resolver, domain)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	lookups = countSPFLookups(parsed)
+	for _, m := range parsed.Mechanisms {
+		inc, ok := m.(spf.MechanismInclude)
+		if !ok || !isResolvableDomainSpec(inc.DomainSpec) {
+			continue
+		}
+		n, d, err := walkSPFTree(ctx, resolver, inc.DomainSpec, chain)
+		if err != nil {
+			return 0, 0, err
+		}
+		lookups += n
+		depth = max(depth, d+1)
+	}
+	if isResolvableDomainSpec(parsed.Redirect) {
+		n, _, err := walkSPFTree(ctx, resolver, parsed.Redirect, chain)
+		if err != nil {
+			return 0, 0, err
+		}
+		lookups += n
+	}
+	return lookups, depth, nil
+}
+
+// spfTreeTotals sums the walks of a record's targets. lookups is the number of
+// lookups the whole tree requires and depth the depth of its include tree;
+// each is incomplete when the matching error is set.
+type spfTreeTotals struct {
+	lookups    int
+	lookupsErr error
+	depth      int
+	depthErr   error
+}
+
+// sumSPFTargetWalks adds the walks of a record's targets to the lookups
+// required by the record's own terms. Only include targets count towards the
+// depth.
+func sumSPFTargetWalks(parsed *spf.SPFRecord, walks []spfTargetWalk) spfTreeTotals {
+	totals := spfTreeTotals{lookups: countSPFLookups(parsed)}
+	for _, w := range walks {
+		if w.err != nil {
+			totals.lookupsErr = cmp.Or(totals.lookupsErr, w.err)
+			if w.include {
+				totals.depthErr = cmp.Or(totals.depthErr, w.err)
+			}
+			continue
+		}
+		totals.lookups += w.lookups
+		if w.include {
+			totals.depth = max(totals.depth, w.depth+1)
+		}
+	}
+	return totals
+}
+
+// spfIncludeLoop returns the first include loop found by a walk, or nil.
+func spfIncludeLoop(walks []spfTargetWalk) error {
+	for _, w := range walks {
+		if errors.Is(w.err, errSPFIncludeLoop) {
+			return w.err
+		}
+	}
+	return nil
+}
+
+// errSPFRedirectChain marks redirect chains that loop or are too deep, as
+// opposed to lookup failures along the chain.
+var errSPFRedirectChain = errors.New("invalid SPF redirect chain")
+
+// followSPFRedirects follows a chain of redirect modifiers starting at target
+// and returns the SPF record found at each hop. It fails when the chain visits
+// a domain twice or is longer than maxDepth.
+func followSPFRedirects(ctx context.Context, resolver dnsResolver, target string, maxDepth int) ([]string, error) {
+	var records []string
+	var chain []string
+	visited := make(map[string]bool)
+
+	for target != "" {
+		key := strings.ToLower(strings.TrimSuffix(target, "."))
+		chain = append(chain, target)
+		if visited[key] {
+			return records, fmt.Errorf("%w: redirect loop %s", errSPFRedirectChain, strings.Join(chain, " -> "))
+		}
+		if len(records) >= maxDepth {
+			return records, fmt.Errorf("%w: more than %d redirects (%s)", errSPFRedirectChain, maxDepth, strings.Join(chain, " -> "))
+		}
+		visited[key] = true
+
+		if !isResolvableDomainSpec(target) {
+			return records, fmt.Errorf("redirect target %s uses macros and cannot be resolved", target)
+		}
+
+		record, parsed, err := lookupSPFRecord(ctx, resolver, target)
+		if err != nil {
+			return records, err
+		}
+		records = append(records, record)
+		target = parsed.Redirect
+	}
+	return records, nil
+}
@@ -0,0 +1,207 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+)
+
+// fakeResolver serves DNS answers from in-memory maps. Names without an
+// entry return a not-found error.
+type fakeResolver struct {
+	txt  map[string][]string
+	mx   map[string][]*net.MX
+	addr map[string][]net.IPAddr
+	ptr  map[string][]string
+}
+
+func (r *fakeResolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	return fakeAnswer(r.txt, name)
+}
+
+func (r *fakeResolver) LookupMX(ctx context.Context, name string) ([]*net.MX, error) {
+	return fakeAnswer(r.mx, name)
+}
+
+func (r *fakeResolver) LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error) {
+	return fakeAnswer(r.addr, host)
+}
+
+func (r *fakeResolver) LookupIP(ctx context.Context, network, host string) ([]net.IP, error) {
+	addrs, err := fakeAnswer(r.addr, host)
+	if err != nil {
+		return nil, err
+	}
+
+	var ips []net.IP
+	for _, addr := range addrs {
+		if is4 := addr.IP.To4() != nil; network == "ip" || is4 == (network == "ip4") {
+			ips = append(ips, addr.IP)
+		}
+	}
+	if len(ips) == 0 {
+		return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+	return ips, nil
+}
+
+func (r *fakeResolver) LookupAddr(ctx context.Context, addr string) ([]string, error) {
+	return fakeAnswer(r.ptr, addr)
+}
+
+func fakeAnswer[T any](answers map[string][]T, name string) ([]T, error) {
+	if v, ok := answers[name]; ok {
+		return v, nil
+	}
+	return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+}
+
+func TestFindSPFRecord(t *testing.T) {
+	resolver := &fakeResolver{txt: map[string][]string{
+		"example.com": {"google-site-verification=abc", "v=spf1 -all"},
+		"example.net": {"v=spf1 include:_spf.google.com -all", "V=SPF1 ~all"},
+		"example.org": {"v=spf10 -all"},
+	}}
+
+	tests := []struct {
+		domain  string
+		want    string
+		wantErr string
+	}{
+		{domain: "example.com", want: "v=spf1 -all"},
+		{domain: "example.net", wantErr: "2 SPF records found at example.net"},
+		{domain: "example.org", wantErr: "no SPF record found at example.org"},
+		{domain: "example.edu", wantErr: "no SPF record found at example.edu"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.domain, func(t *testing.T) {
+			got, err := findSPFRecord(context.Background(), resolver, tt.domain)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("findSPFRecord() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("findSPFRecord() = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestFollowSPFRedirects(t *testing.T) {
+	resolver := &fakeResolver{txt: map[string][]string{
+		"a.example.com":     {"v=spf1 redirect=b.example.com"},
+		"b.example.com":     {"v=spf1 ip4:192.0.2.0/24 -all"},
+		"loop1.example.com": {"v=spf1 redirect=loop2.example.com"},
+		"loop2.example.com": {"v=spf1 redirect=loop1.example.com"},
+		"multi.example.com": {"v=spf1 -all", "v=spf1 ~all"},
+	}}
+
+	tests := []struct {
+		name        string
+		target      string
+		maxDepth    int
+		wantRecords int
+		wantChain   bool
+		wantErr     bool
+	}{
+		{name: "two hops", target: "a.example.com", maxDepth: 10, wantRecords: 2},
+		{name: "depth exceeded", target: "a.example.com", maxDepth: 1, wantRecords: 1, wantChain: true, wantErr: true},
+		{name: "loop", target: "loop1.example.com", maxDepth: 10, wantRecords: 2, wantChain: true, wantErr: true},
+		{name: "missing record", target: "missing.example.com", maxDepth: 10, wantErr: true},
+		{name: "multiple records", target: "multi.example.com", maxDepth: 10, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records, err := followSPFRedirects(context.Background(), resolver, tt.target, tt.maxDepth)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("followSPFRedirects() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if errors.Is(err, errSPFRedirectChain) != tt.wantChain {
+				t.Errorf("followSPFRedirects() error = %v, want redirect chain error %v", err, tt.wantChain)
+			}
+			if len(records) != tt.wantRecords {
+				t.Errorf("followSPFRedirects() returned %d records, want %d", len(records), tt.wantRecords)
+			}
+		})
+	}
+}
+
+func TestSumSPFTargetWalks(t *testing.T) {
+	resolver := &fakeResolver{txt: map[string][]string{
+		"_spf.example.com":  {"v=spf1 include:_spf2.example.com mx -all"},
+		"_spf2.example.com": {"v=spf1 a exists:x.example.com -all"},
+		"loop.example.com":  {"v=spf1 include:loop.example.com -all"},
+		"redir.example.com": {"v=spf1 redirect=loop.example.com"},
+	}}
+
+	parsed := mustParseSPF(t, "v=spf1 include:_spf.example.com ip4:192.0.2.1 -all")
+	totals := sumSPFTargetWalks(parsed, walkSPFTargets(context.Background(), resolver, parsed))
+	if totals.lookupsErr != nil {
+		t.Fatalf("sumSPFTargetWalks() lookups error = %v", totals.lookupsErr)
+	}
+	// 1 top-level include + (include + mx) + (a + exists)
+	if totals.lookups != 5 {
+		t.Errorf("sumSPFTargetWalks() lookups = %d, want 5", totals.lookups)
+	}
+
+	parsed = mustParseSPF(t, "v=spf1 include:loop.example.com -all")
+	walks := walkSPFTargets(context.Background(), resolver, parsed)
+	if totals := sumSPFTargetWalks(parsed, walks); totals.lookupsErr == nil {
+		t.Error("sumSPFTargetWalks() expected include loop error")
+	}
+	if spfIncludeLoop(walks) == nil {
+		t.Error("spfIncludeLoop() = nil, want include loop")
+	}
+
+	// Only includes count towards the depth, so a loop behind the redirect
+	// leaves it complete
+	parsed = mustParseSPF(t, "v=spf1 include:_spf2.example.com redirect=redir.example.com")
+	totals = sumSPFTargetWalks(parsed, walkSPFTargets(context.Background(), resolver, parsed))
+	if !errors.Is(totals.lookupsErr, errSPFIncludeLoop) {
+		t.Errorf("sumSPFTargetWalks() lookups error = %v, want include loop", totals.lookupsErr)
+	}
+	if totals.depthErr != nil || totals.depth != 1 {
+		t.Errorf("sumSPFTargetWalks() depth = %d, %v, want 1", totals.depth, totals.depthErr)
+	}
+}
+
+func TestSPFIncludeDepth(t *testing.T) {
+	resolver := &fakeResolver{txt: map[string][]string{
+		"one.example.com":   {"v=spf1 include:two.example.com -all"},
+		"two.example.com":   {"v=spf1 include:three.example.com -all"},
+		"three.example.com": {"v=spf1 ip4:192.0.2.1 -all"},
+		"loopa.example.com": {"v=spf1 include:loopb.example.com -all"},
+		"loopb.example.com": {"v=spf1 include:loopa.example.com -all"},
+	}}
+
+	tests := []struct {
+		name      string
+		record    string
+		wantDepth int
+		wantLoop  bool
+	}{
+		{name: "no includes", record: "v=spf1 ip4:192.0.2.1 -all", wantDepth: 0},
+		{name: "single level", record: "v=spf1 include:three.example.com -all", wantDepth: 1},
+		{name: "nested", record: "v=spf1 include:three.example.com include:one.example.com -all", wantDepth: 3},
+		{name: "loop", record: "v=spf1 include:loopa.example.com -all", wantLoop: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed := mustParseSPF(t, tt.record)
+			totals := sumSPFTargetWalks(parsed, walkSPFTargets(context.Background(), resolver, parsed))
+			if errors.Is(totals.depthErr, errSPFIncludeLoop) != tt.wantLoop {
+				t.Fatalf("sumSPFTargetWalks() depth error = %v, wantLoop %v", totals.depthErr, tt.wantLoop)
+			}
+			if !tt.wantLoop && totals.depth != tt.wantDepth {
+				t.Errorf("sumSPFTargetWalks() depth = %d, want %d", totals.depth, tt.wantDepth)
+			}
+		})
+	}
+}
@@ -0,0 +1,289 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var (
+	_ datasource.DataSource                   = &SRVDataSource{}
+	_ datasource.DataSourceWithValidateConfig = &SRVDataSource{}
+	_ datasource.DataSourceWithConfigure      = &SRVDataSource{}
+)
+
+func NewSRVDataSource() datasource.DataSource {
+	return &SRVDataSource{}
+}
+
+// SRVDataSource defines the data source implementation.
+type SRVDataSource struct {
+	providerData *providerData
+}
+
+// SRVDataSourceModel describes the data source data model.
+type SRVDataSourceModel struct {
+	Name         types.String `tfsdk:"name"`
+	Records      types.List   `tfsdk:"records"`
+	ChangeTicket types.String `tfsdk:"change_ticket"`
+	Parsed       types.List   `tfsdk:"parsed"`
+}
+
+// srvObjectType defines the Terraform object type for a parsed SRV record.
+var srvObjectType = types.ObjectType{
+	AttrTypes: map[string]attr.Type{
+		"priority": types.Int64Type,
+		"weight":   types.Int64Type,
+		"port":     types.Int64Type,
+		"target":   types.StringType,
+	},
+}
+
+// srvRecord is a single parsed SRV record.
+type srvRecord struct {
+	Priority int64
+	Weight   int64
+	Port     int64
+	Target   string
+}
+
+func (d *SRVDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_srv"
+}
+
+func (d *SRVDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Validates SRV (RFC 2782) records, such as the `_autodiscover._tcp` and `_submission._tcp` records used for " +
+			"mail client configuration. If a record is invalid, terraform plan will fail with a specific error message.",
+
+		Attributes: map[string]schema.Attribute{
+			"name": schema.StringAttribute{
+				MarkdownDescription: "The owner name of the records, starting with the service and protocol labels (e.g., `_submission._tcp` or `_imaps._tcp.example.com`)",
+				Optional:            true,
+			},
+			"records": schema.ListAttribute{
+				MarkdownDescription: "The SRV records to validate, as `<priority> <weight> <port> <target>` (e.g., `0 1 587 mail.example.com.`)",
+				Required:            true,
+				ElementType:         types.StringType,
+			},
+			"change_ticket": changeTicketAttribute(),
+			"parsed": schema.ListNestedAttribute{
+				MarkdownDescription: "The parsed records, in the order given",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"priority": schema.Int64Attribute{
+							MarkdownDescription: "The priority (0-65535). Lower values are tried first",
+							Computed:            true,
+						},
+						"weight": schema.Int64Attribute{
+							MarkdownDescription: "The weight (0-65535) used to choose between records of the same priority",
+							Computed:            true,
+						},
+						"port": schema.Int64Attribute{
+							MarkdownDescription: "The port (0-65535) of the service",
+							Computed:            true,
+						},
+						"target": schema.StringAttribute{
+							MarkdownDescription: "The host providing the service, or `.` when the service is not available",
+							Computed:            true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *SRVDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	d.providerData = configureProviderData(req, resp)
+}
+
+func (d *SRVDataSource) ValidateConfig(ctx context.Context, req datasource.ValidateConfigRequest, resp *datasource.ValidateConfigResponse) {
+	defer d.providerData.applyWarningSettings(&resp.Diagnostics)
+
+	var data SRVDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Values that are unknown (e.g., depend on another resource) are skipped
+	if !data.Name.IsUnknown() && !data.Name.IsNull() {
+		if err := checkSRVName(data.Name.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("name"),
+				summarySRVInvalidName,
+				fmt.Sprintf("The SRV owner name is invalid: %s", err.Error()),
+			)
+		}
+	}
+
+	if data.Records.IsUnknown() || data.Records.IsNull() {
+		return
+	}
+	for i, value := range data.Records.Elements() {
+		record, ok := value.(types.String)
+		if !ok || record.IsNull() || record.IsUnknown() {
+			continue
+		}
+		if _, err := parseSRVRecord(record.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("records").AtListIndex(i),
+				summarySRVInvalidRecord,
+				fmt.Sprintf("The SRV record is malformed: %s\n\nRecord: %s", err.Error(), record.ValueString()),
+			)
+		}
+	}
+}
+
+func (d *SRVDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	defer d.providerData.applyWarningSettings(&resp.Diagnostics)
+
+	var data SRVDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data.ChangeTicket = d.providerData.checkChangeTicket(data.ChangeTicket, &resp.Diagnostics)
+
+	if !data.Name.IsNull() {
+		if err := checkSRVName(data.Name.ValueString()); err != nil {
+			resp.Diagnostics.AddError(
+				summarySRVInvalidName,
+				fmt.Sprintf("The SRV owner name is invalid: %s", err.Error()),
+			)
+			return
+		}
+	}
+
+	var raw []string
+	resp.Diagnostics.Append(data.Records.ElementsAs(ctx, &raw, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	records := make([]srvRecord, len(raw))
+	for i, r := range raw {
+		rec, err := parseSRVRecord(r)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				summarySRVInvalidRecord,
+				fmt.Sprintf("The SRV record %q is malformed: %s", r, err.Error()),
+			)
+			return
+		}
+		records[i] = *rec
+	}
+
+	checkSRVRecords(records, &resp.Diagnostics)
+
+	values := make([]attr.Value, len(records))
+	for i, r := range records {
+		values[i] = types.ObjectValueMust(srvObjectType.AttrTypes, map[string]attr.Value{
+			"priority": types.Int64Value(r.Priority),
+			"weight":   types.Int64Value(r.Weight),
+			"port":     types.Int64Value(r.Port),
+			"target":   types.StringValue(r.Target),
+		})
+	}
+	list, diags := types.ListValue(srvObjectType, values)
+	resp.Diagnostics.Append(diags...)
+	data.Parsed = list
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// checkSRVRecords reports problems with a set of SRV records as a whole.
+func checkSRVRecords(records []srvRecord, diags *diag.Diagnostics) {
+	perPriority := make(map[int64]int)
+	for _, r := range records {
+		perPriority[r.Priority]++
+	}
+
+	for _, r := range records {
+		if r.Target == "." && len(records) > 1 {
+			diags.AddError(
+				summarySRVInvalidNotAvailable,
+				"A record with target \".\" declares that the service is not available and must be the only record (RFC 2782).",
+			)
+			return
+		}
+	}
+
+	for _, r := range records {
+		if r.Target != "." && r.Weight == 0 && perPriority[r.Priority] == 1 {
+			diags.AddWarning(
+				summarySRVZeroWeight,
+				fmt.Sprintf("The record for %s is the only one at priority %d but has weight 0. "+
+					"Weight 0 is meant for records that should rarely be selected, and some clients skip such records; use a weight of 1 or more.",
+					r.Target, r.Priority),
+			)
+		}
+	}
+}
+
+// parseSRVRecord parses the data of an SRV record in presentation format:
+// priority, weight, port, and target.
+func parseSRVRecord(record string) (*srvRecord, error) {
+	fields := strings.Fields(record)
+	if len(fields) != 4 {
+		return nil, fmt.Errorf("expected <priority> <weight> <port> <target>")
+	}
+
+	var numbers [3]int64
+	for i, name := range []string{"priority", "weight", "port"} {
+		n, err := strconv.ParseInt(fields[i], 10, 64)
+		if err != nil || n < 0 || n > 65535 {
+			return nil, fmt.Errorf("%s must be a number from 0 to 65535, got %q", name, fields[i])
+		}
+		numbers[i] = n
+	}
+
+	target := fields[3]
+	if target != "." {
+		if err := checkHostname(target); err != nil {
+			return nil, fmt.Errorf("invalid target: %w", err)
+		}
+	}
+
+	return &srvRecord{Priority: numbers[0], Weight: numbers[1], Port: numbers[2], Target: target}, nil
+}
+
+// checkSRVName validates an SRV owner name: a service label and a protocol
+// label, both starting with an underscore, followed by an optional domain.
+func checkSRVName(name string) error {
+	labels := strings.SplitN(strings.TrimSuffix(name, "."), ".", 3)
+	if len(labels) < 2 {
+		return fmt.Errorf("%q must start with _<service>._<protocol>", name)
+	}
+
+	service, proto := labels[0], labels[1]
+	if len(service) < 2 || service[0] != '_' || checkHostnameLabel(service[1:]) != nil {
+		return fmt.Errorf("%q must start with an underscore and a service name such as _submission", service)
+	}
+	switch strings.ToLower(proto) {
+	case "_tcp", "_udp", "_sctp":
+	default:
+		return fmt.Errorf("protocol label must be _tcp, _udp, or _sctp, got %q", proto)
+	}
+
+	if len(labels) == 3 {
+		if err := checkHostname(labels[2]); err != nil {
+			return fmt.Errorf("invalid domain: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+func TestParseSRVRecord(t *testing.T) {
+	tests := []struct {
+		name    string
+		record  string
+		want    *srvRecord
+		wantErr bool
+	}{
+		{name: "valid", record: "0 1 587 mail.example.com.", want: &srvRecord{Priority: 0, Weight: 1, Port: 587, Target: "mail.example.com."}},
+		{name: "not available", record: "0 0 0 .", want: &srvRecord{Target: "."}},
+		{name: "maximum values", record: "65535 65535 65535 mail.example.com", want: &srvRecord{Priority: 65535, Weight: 65535, Port: 65535, Target: "mail.example.com"}},
+		{name: "priority too large", record: "65536 1 587 mail.example.com.", wantErr: true},
+		{name: "negative weight", record: "0 -1 587 mail.example.com.", wantErr: true},
+		{name: "port not a number", record: "0 1 smtp mail.example.com.", wantErr: true},
+		{name: "invalid target", record: "0 1 587 mail_example.com.", wantErr: true},
+		{name: "ip target", record: "0 1 587 192.0.2.25", wantErr: true},
+		{name: "missing target", record: "0 1 587", wantErr: true},
+		{name: "extra field", record: "0 1 587 mail.example.com. x", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseSRVRecord(tt.record)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseSRVRecord() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseSRVRecord() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckSRVName(t *testing.T) {
+	tests := []struct {
+		name    string
+		wantErr bool
+	}{
+		{name: "_submission._tcp"},
+		{name: "_autodiscover._tcp.example.com."},
+		{name: "_imaps._TCP.example.com"},
+		{name: "submission._tcp", wantErr: true},
+		{name: "_submission", wantErr: true},
+		{name: "_submission._http", wantErr: true},
+		{name: "_._tcp", wantErr: true},
+		{name: "_submission._tcp.exa mple.com", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkSRVName(tt.name); (err != nil) != tt.wantErr {
+				t.Errorf("checkSRVName() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckSRVRecords(t *testing.T) {
+	tests := []struct {
+		name    string
+		records []srvRecord
+		want    []string
+	}{
+		{
+			name:    "weighted",
+			records: []srvRecord{{Priority: 0, Weight: 5, Port: 587, Target: "a.example.com."}, {Priority: 0, Weight: 0, Port: 587, Target: "b.example.com."}},
+		},
+		{
+			name:    "zero weight alone at priority",
+			records: []srvRecord{{Priority: 0, Weight: 1, Port: 587, Target: "a.example.com."}, {Priority: 10, Weight: 0, Port: 587, Target: "b.example.com."}},
+			want:    []string{"Zero SRV Weight"},
+		},
+		{
+			name:    "not available",
+			records: []srvRecord{{Target: "."}},
+		},
+		{
+			name:    "not available with others",
+			records: []srvRecord{{Target: "."}, {Priority: 10, Weight: 1, Port: 587, Target: "a.example.com."}},
+			want:    []string{"Invalid SRV Service Not Available"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var diags diag.Diagnostics
+			checkSRVRecords(tt.records, &diags)
+
+			var got []string
+			for _, d := range diags {
+				got = append(got, d.Summary())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("checkSRVRecords() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
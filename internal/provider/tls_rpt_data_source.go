@@ -0,0 +1,201 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var (
+	_ datasource.DataSource                   = &TLSRPTDataSource{}
+	_ datasource.DataSourceWithValidateConfig = &TLSRPTDataSource{}
+	_ datasource.DataSourceWithConfigure      = &TLSRPTDataSource{}
+)
+
+func NewTLSRPTDataSource() datasource.DataSource {
+	return &TLSRPTDataSource{}
+}
+
+// TLSRPTDataSource defines the data source implementation.
+type TLSRPTDataSource struct {
+	providerData *providerData
+}
+
+// TLSRPTDataSourceModel describes the data source data model.
+type TLSRPTDataSourceModel struct {
+	Record       types.String `tfsdk:"record"`
+	ChangeTicket types.String `tfsdk:"change_ticket"`
+	RUA          types.List   `tfsdk:"rua"`
+}
+
+// tlsRPTURIObjectType defines the Terraform object type for a TLS-RPT report
+// destination. Unlike DMARC, TLS-RPT has no size limit suffix.
+var tlsRPTURIObjectType = types.ObjectType{
+	AttrTypes: map[string]attr.Type{
+		"scheme":  types.StringType,
+		"address": types.StringType,
+	},
+}
+
+func (d *TLSRPTDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_tls_rpt"
+}
+
+func (d *TLSRPTDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Validates an SMTP TLS Reporting (RFC 8460) TXT record published at `_smtp._tls.<domain>`. " +
+			"If the record is invalid, terraform plan will fail with a specific error message.",
+
+		Attributes: map[string]schema.Attribute{
+			"record": schema.StringAttribute{
+				MarkdownDescription: "The TLS-RPT TXT record content to validate (e.g., `v=TLSRPTv1; rua=mailto:tlsrpt@example.com`)",
+				Required:            true,
+			},
+			"change_ticket": changeTicketAttribute(),
+			"rua": schema.ListNestedAttribute{
+				MarkdownDescription: "Parsed report destinations (rua field). Null when the field is absent",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"scheme": schema.StringAttribute{
+							MarkdownDescription: "The URI scheme (mailto or https)",
+							Computed:            true,
+						},
+						"address": schema.StringAttribute{
+							MarkdownDescription: "The destination email address (e.g., `tlsrpt@example.com`), or the full URL for https destinations",
+							Computed:            true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *TLSRPTDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	d.providerData = configureProviderData(req, resp)
+}
+
+func (d *TLSRPTDataSource) ValidateConfig(ctx context.Context, req datasource.ValidateConfigRequest, resp *datasource.ValidateConfigResponse) {
+	defer d.providerData.applyWarningSettings(&resp.Diagnostics)
+
+	var data TLSRPTDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Skip validation if record is unknown (e.g., depends on another resource)
+	if data.Record.IsUnknown() {
+		return
+	}
+
+	if _, err := parseTLSRPTRecord(data.Record.ValueString()); err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("record"),
+			summaryTLSRPTInvalidRecord,
+			fmt.Sprintf("The TLS-RPT record is malformed: %s\n\nRecord: %s", err.Error(), data.Record.ValueString()),
+		)
+	}
+}
+
+func (d *TLSRPTDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	defer d.providerData.applyWarningSettings(&resp.Diagnostics)
+
+	var data TLSRPTDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data.ChangeTicket = d.providerData.checkChangeTicket(data.ChangeTicket, &resp.Diagnostics)
+
+	uris, err := parseTLSRPTRecord(data.Record.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			summaryTLSRPTInvalidRecord,
+			fmt.Sprintf("The TLS-RPT record is malformed: %s", err.Error()),
+		)
+		return
+	}
+
+	data.RUA = types.ListNull(tlsRPTURIObjectType)
+	if len(uris) == 0 {
+		resp.Diagnostics.AddWarning(
+			summaryTLSRPTNoRUA,
+			"The TLS-RPT record has no rua field, so no sender can deliver TLS reports and the record has no effect. "+
+				"Add rua=mailto:<address> or rua=https://<url>.",
+		)
+	} else {
+		values := make([]attr.Value, len(uris))
+		for i, uri := range uris {
+			values[i] = types.ObjectValueMust(tlsRPTURIObjectType.AttrTypes, map[string]attr.Value{
+				"scheme":  types.StringValue(uri.Scheme),
+				"address": types.StringValue(uri.Address),
+			})
+		}
+		list, diags := types.ListValue(tlsRPTURIObjectType, values)
+		resp.Diagnostics.Append(diags...)
+		data.RUA = list
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// parseTLSRPTRecord parses a TLS-RPT record as described in RFC 8460 section
+// 3 and returns its report destinations. Extension fields are ignored.
+func parseTLSRPTRecord(record string) ([]dmarcReportURI, error) {
+	fields := strings.Split(record, ";")
+	// A trailing separator is allowed
+	if strings.TrimSpace(fields[len(fields)-1]) == "" {
+		fields = fields[:len(fields)-1]
+	}
+
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("record must begin with v=TLSRPTv1")
+	}
+	if version := strings.TrimSpace(fields[0]); version != "v=TLSRPTv1" {
+		if strings.HasPrefix(version, "v=") {
+			return nil, fmt.Errorf("unrecognized version %q (expected v=TLSRPTv1)", strings.TrimPrefix(version, "v="))
+		}
+		return nil, fmt.Errorf("record must begin with v=TLSRPTv1")
+	}
+
+	var uris []dmarcReportURI
+	seenRUA := false
+	for _, field := range fields[1:] {
+		name, value, found := strings.Cut(strings.TrimSpace(field), "=")
+		if !found {
+			return nil, fmt.Errorf("invalid field %q (missing '=')", strings.TrimSpace(field))
+		}
+		if name != "rua" {
+			continue
+		}
+		if seenRUA {
+			return nil, fmt.Errorf("duplicate rua field")
+		}
+		seenRUA = true
+
+		for _, raw := range strings.Split(value, ",") {
+			raw = strings.TrimSpace(raw)
+			uri, err := parseReportURI(raw)
+			if err != nil {
+				return nil, fmt.Errorf("invalid rua destination %q: %w", raw, err)
+			}
+			if uri.MaxSize != nil {
+				return nil, fmt.Errorf("invalid rua destination %q: TLS-RPT does not support size limits", raw)
+			}
+			uris = append(uris, uri)
+		}
+	}
+	return uris, nil
+}
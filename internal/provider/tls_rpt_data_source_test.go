@@ -0,0 +1,50 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTLSRPTRecord(t *testing.T) {
+	tests := []struct {
+		name    string
+		record  string
+		want    []dmarcReportURI
+		wantErr bool
+	}{
+		{
+			name:   "mailto",
+			record: "v=TLSRPTv1; rua=mailto:tlsrpt@example.com",
+			want:   []dmarcReportURI{{Scheme: "mailto", Address: "tlsrpt@example.com"}},
+		},
+		{
+			name:   "mailto and https",
+			record: "v=TLSRPTv1;rua=mailto:tlsrpt@example.com, https://reports.example.net/tlsrpt;",
+			want: []dmarcReportURI{
+				{Scheme: "mailto", Address: "tlsrpt@example.com"},
+				{Scheme: "https", Address: "https://reports.example.net/tlsrpt"},
+			},
+		},
+		{name: "no rua", record: "v=TLSRPTv1"},
+		{name: "extension field", record: "v=TLSRPTv1; ext=1; rua=mailto:a@example.com", want: []dmarcReportURI{{Scheme: "mailto", Address: "a@example.com"}}},
+		{name: "unrecognized version", record: "v=TLSRPTv2; rua=mailto:a@example.com", wantErr: true},
+		{name: "missing version", record: "rua=mailto:a@example.com", wantErr: true},
+		{name: "missing scheme", record: "v=TLSRPTv1; rua=a@example.com", wantErr: true},
+		{name: "http", record: "v=TLSRPTv1; rua=http://reports.example.net/", wantErr: true},
+		{name: "size limit", record: "v=TLSRPTv1; rua=mailto:a@example.com!10m", wantErr: true},
+		{name: "duplicate rua", record: "v=TLSRPTv1; rua=mailto:a@example.com; rua=mailto:b@example.com", wantErr: true},
+		{name: "empty", record: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseTLSRPTRecord(tt.record)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseTLSRPTRecord() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseTLSRPTRecord() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,326 @@
+package provider
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// TLSA field values from RFC 6698 section 7.
+const (
+	tlsaUsagePKIXTA = 0
+	tlsaUsagePKIXEE = 1
+	tlsaUsageDANETA = 2
+	tlsaUsageDANEEE = 3
+
+	tlsaSelectorCert = 0
+	tlsaSelectorSPKI = 1
+
+	tlsaMatchingFull   = 0
+	tlsaMatchingSHA256 = 1
+	tlsaMatchingSHA512 = 2
+)
+
+// tlsaDigestLengths maps the digest matching types to their length in bytes.
+var tlsaDigestLengths = map[int64]int{
+	tlsaMatchingSHA256: 32,
+	tlsaMatchingSHA512: 64,
+}
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var (
+	_ datasource.DataSource                   = &TLSADataSource{}
+	_ datasource.DataSourceWithValidateConfig = &TLSADataSource{}
+	_ datasource.DataSourceWithConfigure      = &TLSADataSource{}
+)
+
+func NewTLSADataSource() datasource.DataSource {
+	return &TLSADataSource{}
+}
+
+// TLSADataSource defines the data source implementation.
+type TLSADataSource struct {
+	providerData *providerData
+}
+
+// TLSADataSourceModel describes the data source data model.
+type TLSADataSourceModel struct {
+	Name         types.String `tfsdk:"name"`
+	Records      types.List   `tfsdk:"records"`
+	ChangeTicket types.String `tfsdk:"change_ticket"`
+	Parsed       types.List   `tfsdk:"parsed"`
+}
+
+// tlsaObjectType defines the Terraform object type for a parsed TLSA record.
+var tlsaObjectType = types.ObjectType{
+	AttrTypes: map[string]attr.Type{
+		"usage":         types.Int64Type,
+		"selector":      types.Int64Type,
+		"matching_type": types.Int64Type,
+		"data":          types.StringType,
+	},
+}
+
+// tlsaRecord is a single parsed TLSA record. Data is lowercase hex.
+type tlsaRecord struct {
+	Usage        int64
+	Selector     int64
+	MatchingType int64
+	Data         string
+}
+
+func (d *TLSADataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_tlsa"
+}
+
+func (d *TLSADataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Validates TLSA (RFC 6698) records published at `_25._tcp.<mailhost>` to authenticate mail servers with DANE (RFC 7672). " +
+			"If a record is invalid, terraform plan will fail with a specific error message.",
+
+		Attributes: map[string]schema.Attribute{
+			"name": schema.StringAttribute{
+				MarkdownDescription: "The owner name of the records, starting with the port and protocol labels (e.g., `_25._tcp.mail.example.com`)",
+				Optional:            true,
+			},
+			"records": schema.ListAttribute{
+				MarkdownDescription: "The TLSA records to validate, as `<usage> <selector> <matching type> <data>` with the data in hex (e.g., `3 1 1 0c72ac70...`)",
+				Required:            true,
+				ElementType:         types.StringType,
+			},
+			"change_ticket": changeTicketAttribute(),
+			"parsed": schema.ListNestedAttribute{
+				MarkdownDescription: "The parsed records, in the order given",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"usage": schema.Int64Attribute{
+							MarkdownDescription: "The certificate usage: `0` PKIX-TA, `1` PKIX-EE, `2` DANE-TA, or `3` DANE-EE",
+							Computed:            true,
+						},
+						"selector": schema.Int64Attribute{
+							MarkdownDescription: "The selector: `0` for the full certificate or `1` for its SubjectPublicKeyInfo",
+							Computed:            true,
+						},
+						"matching_type": schema.Int64Attribute{
+							MarkdownDescription: "The matching type: `0` for the exact data, `1` for SHA-256, or `2` for SHA-512",
+							Computed:            true,
+						},
+						"data": schema.StringAttribute{
+							MarkdownDescription: "The certificate association data, in lowercase hex",
+							Computed:            true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *TLSADataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	d.providerData = configureProviderData(req, resp)
+}
+
+func (d *TLSADataSource) ValidateConfig(ctx context.Context, req datasource.ValidateConfigRequest, resp *datasource.ValidateConfigResponse) {
+	defer d.providerData.applyWarningSettings(&resp.Diagnostics)
+
+	var data TLSADataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Values that are unknown (e.g., depend on another resource) are skipped
+	if !data.Name.IsUnknown() && !data.Name.IsNull() {
+		if err := checkTLSAName(data.Name.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("name"),
+				summaryTLSAInvalidName,
+				fmt.Sprintf("The TLSA owner name is invalid: %s", err.Error()),
+			)
+		}
+	}
+
+	if data.Records.IsUnknown() || data.Records.IsNull() {
+		return
+	}
+	for i, value := range data.Records.Elements() {
+		record, ok := value.(types.String)
+		if !ok || record.IsNull() || record.IsUnknown() {
+			continue
+		}
+		if _, err := parseTLSARecord(record.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("records").AtListIndex(i),
+				summaryTLSAInvalidRecord,
+				fmt.Sprintf("The TLSA record is malformed: %s\n\nRecord: %s", err.Error(), record.ValueString()),
+			)
+		}
+	}
+}
+
+func (d *TLSADataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	defer d.providerData.applyWarningSettings(&resp.Diagnostics)
+
+	var data TLSADataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data.ChangeTicket = d.providerData.checkChangeTicket(data.ChangeTicket, &resp.Diagnostics)
+
+	if !data.Name.IsNull() {
+		if err := checkTLSAName(data.Name.ValueString()); err != nil {
+			resp.Diagnostics.AddError(
+				summaryTLSAInvalidName,
+				fmt.Sprintf("The TLSA owner name is invalid: %s", err.Error()),
+			)
+			return
+		}
+	}
+
+	var raw []string
+	resp.Diagnostics.Append(data.Records.ElementsAs(ctx, &raw, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	records := make([]tlsaRecord, len(raw))
+	for i, r := range raw {
+		rec, err := parseTLSARecord(r)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				summaryTLSAInvalidRecord,
+				fmt.Sprintf("The TLSA record %q is malformed: %s", r, err.Error()),
+			)
+			return
+		}
+		records[i] = *rec
+	}
+
+	checkTLSAUsages(records, &resp.Diagnostics)
+
+	values := make([]attr.Value, len(records))
+	for i, r := range records {
+		values[i] = types.ObjectValueMust(tlsaObjectType.AttrTypes, map[string]attr.Value{
+			"usage":         types.Int64Value(r.Usage),
+			"selector":      types.Int64Value(r.Selector),
+			"matching_type": types.Int64Value(r.MatchingType),
+			"data":          types.StringValue(r.Data),
+		})
+	}
+	list, diags := types.ListValue(tlsaObjectType, values)
+	resp.Diagnostics.Append(diags...)
+	data.Parsed = list
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// checkTLSAUsages warns about PKIX usages, which SMTP clients ignore. RFC 7672
+// section 3.1.3 explains that there is no agreed set of trust anchors for
+// SMTP, so only the DANE-TA and DANE-EE usages are used.
+func checkTLSAUsages(records []tlsaRecord, diags *diag.Diagnostics) {
+	for _, r := range records {
+		if r.Usage == tlsaUsagePKIXTA || r.Usage == tlsaUsagePKIXEE {
+			name := "TA"
+			if r.Usage == tlsaUsagePKIXEE {
+				name = "EE"
+			}
+			diags.AddWarning(
+				summaryTLSAUsageUnsupported,
+				fmt.Sprintf("A TLSA record uses certificate usage %d (PKIX-%s), which SMTP clients treat as unusable (RFC 7672). "+
+					"Use usage 3 (DANE-EE) or 2 (DANE-TA), such as \"3 1 1 <SHA-256 of the public key>\".",
+					r.Usage, name),
+			)
+		}
+	}
+}
+
+// parseTLSARecord parses the data of a TLSA record in presentation format.
+// The association data may be split by whitespace.
+func parseTLSARecord(record string) (*tlsaRecord, error) {
+	fields := strings.Fields(record)
+	if len(fields) < 4 {
+		return nil, fmt.Errorf("expected <usage> <selector> <matching type> <data>")
+	}
+
+	var numbers [3]int64
+	for i, field := range []struct {
+		name string
+		max  int64
+	}{{"usage", tlsaUsageDANEEE}, {"selector", tlsaSelectorSPKI}, {"matching type", tlsaMatchingSHA512}} {
+		n, err := strconv.ParseInt(fields[i], 10, 64)
+		if err != nil || n < 0 || n > field.max {
+			return nil, fmt.Errorf("%s must be a number from 0 to %d, got %q", field.name, field.max, fields[i])
+		}
+		numbers[i] = n
+	}
+	rec := &tlsaRecord{Usage: numbers[0], Selector: numbers[1], MatchingType: numbers[2]}
+
+	data, err := hex.DecodeString(strings.Join(fields[3:], ""))
+	if err != nil {
+		return nil, fmt.Errorf("certificate association data must be hex: %w", err)
+	}
+	if want, ok := tlsaDigestLengths[rec.MatchingType]; ok && len(data) != want {
+		return nil, fmt.Errorf("matching type %d needs %d bytes of data, got %d", rec.MatchingType, want, len(data))
+	}
+	if rec.MatchingType == tlsaMatchingFull {
+		if err := checkTLSAFullData(rec.Selector, data); err != nil {
+			return nil, err
+		}
+	}
+
+	rec.Data = hex.EncodeToString(data)
+	return rec, nil
+}
+
+// checkTLSAFullData checks that exact-match data is the DER encoding of what
+// the selector names.
+func checkTLSAFullData(selector int64, data []byte) error {
+	if selector == tlsaSelectorCert {
+		if _, err := x509.ParseCertificate(data); err != nil {
+			return fmt.Errorf("selector 0 with matching type 0 needs a DER certificate: %w", err)
+		}
+		return nil
+	}
+	if _, err := x509.ParsePKIXPublicKey(data); err != nil {
+		return fmt.Errorf("selector 1 with matching type 0 needs a DER SubjectPublicKeyInfo: %w", err)
+	}
+	return nil
+}
+
+// checkTLSAName validates a TLSA owner name: a port label and a protocol
+// label, both starting with an underscore, followed by the host name.
+func checkTLSAName(name string) error {
+	labels := strings.SplitN(strings.TrimSuffix(name, "."), ".", 3)
+	if len(labels) < 3 {
+		return fmt.Errorf("%q must be _<port>._<protocol>.<host>", name)
+	}
+
+	port, err := strconv.ParseUint(strings.TrimPrefix(labels[0], "_"), 10, 16)
+	if !strings.HasPrefix(labels[0], "_") || err != nil || port == 0 {
+		return fmt.Errorf("%q must be an underscore and a port number, such as _25", labels[0])
+	}
+	switch strings.ToLower(labels[1]) {
+	case "_tcp", "_udp", "_sctp":
+	default:
+		return fmt.Errorf("protocol label must be _tcp, _udp, or _sctp, got %q", labels[1])
+	}
+	if err := checkHostname(labels[2]); err != nil {
+		return fmt.Errorf("invalid host: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package provider
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/hex"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+func TestParseTLSARecord(t *testing.T) {
+	pub, _, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("ed25519.GenerateKey() error = %v", err)
+	}
+	spki, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		t.Fatalf("x509.MarshalPKIXPublicKey() error = %v", err)
+	}
+	spkiHex := hex.EncodeToString(spki)
+	sha256Hex := strings.Repeat("ab", 32)
+	sha512Hex := strings.Repeat("cd", 64)
+
+	tests := []struct {
+		name    string
+		record  string
+		want    *tlsaRecord
+		wantErr bool
+	}{
+		{name: "dane-ee sha256", record: "3 1 1 " + sha256Hex, want: &tlsaRecord{Usage: 3, Selector: 1, MatchingType: 1, Data: sha256Hex}},
+		{name: "dane-ta sha512", record: "2 0 2 " + sha512Hex, want: &tlsaRecord{Usage: 2, Selector: 0, MatchingType: 2, Data: sha512Hex}},
+		{name: "uppercase split data", record: "3 1 1 " + strings.ToUpper(sha256Hex[:32]) + " " + sha256Hex[32:], want: &tlsaRecord{Usage: 3, Selector: 1, MatchingType: 1, Data: sha256Hex}},
+		{name: "full public key", record: "3 1 0 " + spkiHex, want: &tlsaRecord{Usage: 3, Selector: 1, MatchingType: 0, Data: spkiHex}},
+		{name: "full certificate not der", record: "3 0 0 " + spkiHex, wantErr: true},
+		{name: "full public key not der", record: "3 1 0 abcd", wantErr: true},
+		{name: "sha256 too short", record: "3 1 1 " + sha256Hex[2:], wantErr: true},
+		{name: "sha512 with sha256 length", record: "3 1 2 " + sha256Hex, wantErr: true},
+		{name: "usage out of range", record: "4 1 1 " + sha256Hex, wantErr: true},
+		{name: "selector out of range", record: "3 2 1 " + sha256Hex, wantErr: true},
+		{name: "matching type out of range", record: "3 1 3 " + sha256Hex, wantErr: true},
+		{name: "not hex", record: "3 1 1 " + strings.Repeat("zz", 32), wantErr: true},
+		{name: "missing data", record: "3 1 1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseTLSARecord(tt.record)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseTLSARecord() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseTLSARecord() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckTLSAName(t *testing.T) {
+	tests := []struct {
+		name    string
+		wantErr bool
+	}{
+		{name: "_25._tcp.mail.example.com"},
+		{name: "_465._tcp.mail.example.com."},
+		{name: "_25._tcp", wantErr: true},
+		{name: "25._tcp.mail.example.com", wantErr: true},
+		{name: "_smtp._tcp.mail.example.com", wantErr: true},
+		{name: "_0._tcp.mail.example.com", wantErr: true},
+		{name: "_25._http.mail.example.com", wantErr: true},
+		{name: "_25._tcp.mail_example.com", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkTLSAName(tt.name); (err != nil) != tt.wantErr {
+				t.Errorf("checkTLSAName() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckTLSAUsages(t *testing.T) {
+	var diags diag.Diagnostics
+	checkTLSAUsages([]tlsaRecord{{Usage: 3}, {Usage: 2}, {Usage: 1}, {Usage: 0}}, &diags)
+
+	if len(diags) != 2 || diags.HasError() {
+		t.Fatalf("checkTLSAUsages() = %v, want two warnings", diags)
+	}
+	for _, d := range diags {
+		if d.Summary() != "TLSA Usage Unsupported for SMTP" {
+			t.Errorf("checkTLSAUsages() summary = %q", d.Summary())
+		}
+	}
+}
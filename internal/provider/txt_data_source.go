@@ -0,0 +1,239 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/wttw/spf"
+)
+
+// txtRecordTypes maps the values of the type attribute to the names used in
+// diagnostics.
+var txtRecordTypes = map[string]string{
+	"spf":     "SPF",
+	"dmarc":   "DMARC",
+	"dkim":    "DKIM",
+	"mta-sts": "MTA-STS",
+	"tls-rpt": "TLS-RPT",
+	"bimi":    "BIMI",
+}
+
+// txtRecordTypeList is the documented order of the type values.
+var txtRecordTypeList = []string{"spf", "dmarc", "dkim", "mta-sts", "tls-rpt", "bimi"}
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var (
+	_ datasource.DataSource                   = &TXTDataSource{}
+	_ datasource.DataSourceWithValidateConfig = &TXTDataSource{}
+	_ datasource.DataSourceWithConfigure      = &TXTDataSource{}
+)
+
+func NewTXTDataSource() datasource.DataSource {
+	return &TXTDataSource{}
+}
+
+// TXTDataSource defines the data source implementation.
+type TXTDataSource struct {
+	providerData *providerData
+}
+
+// TXTDataSourceModel describes the data source data model.
+type TXTDataSourceModel struct {
+	Record       types.String `tfsdk:"record"`
+	Type         types.String `tfsdk:"type"`
+	ChangeTicket types.String `tfsdk:"change_ticket"`
+	Valid        types.Bool   `tfsdk:"valid"`
+	Warnings     types.List   `tfsdk:"warnings"`
+	ParsedJSON   types.String `tfsdk:"parsed_json"`
+}
+
+func (d *TXTDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_txt"
+}
+
+func (d *TXTDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Validates the syntax of an email-related TXT record of any supported type, which makes it easy to check " +
+			"every record of a zone with a single `for_each`. Use the data source for the record type for the full set of checks.",
+
+		Attributes: map[string]schema.Attribute{
+			"record": schema.StringAttribute{
+				MarkdownDescription: "The TXT record content to validate",
+				Required:            true,
+			},
+			"type": schema.StringAttribute{
+				MarkdownDescription: "The record type: one of `" + strings.Join(txtRecordTypeList, "`, `") + "`",
+				Required:            true,
+			},
+			"change_ticket": changeTicketAttribute(),
+			"valid":         validAttribute(),
+			"warnings":      warningsAttribute(),
+			"parsed_json": schema.StringAttribute{
+				MarkdownDescription: "The parsed record as a JSON object, for use with `jsondecode`. SPF records have `mechanisms`, " +
+					"`redirect`, and `exp` keys; other types map each tag name to its value. Null when the record is invalid",
+				Computed: true,
+			},
+		},
+	}
+}
+
+func (d *TXTDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	d.providerData = configureProviderData(req, resp)
+}
+
+func (d *TXTDataSource) ValidateConfig(ctx context.Context, req datasource.ValidateConfigRequest, resp *datasource.ValidateConfigResponse) {
+	defer d.providerData.applyWarningSettings(&resp.Diagnostics)
+
+	var data TXTDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if data.Type.IsUnknown() {
+		return
+	}
+	if _, ok := txtRecordTypes[data.Type.ValueString()]; !ok {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("type"),
+			summaryTXTInvalidRecordType,
+			fmt.Sprintf("type must be one of %s, got %q.", strings.Join(txtRecordTypeList, ", "), data.Type.ValueString()),
+		)
+		return
+	}
+
+	// Skip validation if record is unknown (e.g., depends on another resource)
+	if data.Record.IsUnknown() {
+		return
+	}
+
+	var checkDiags diag.Diagnostics
+	validateTXTRecord(data.Type.ValueString(), data.Record.ValueString(), &checkDiags)
+	d.providerData.routeDiagnostics(checkDiags, &resp.Diagnostics)
+}
+
+func (d *TXTDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	defer d.providerData.applyWarningSettings(&resp.Diagnostics)
+
+	var data TXTDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data.ChangeTicket = d.providerData.checkChangeTicket(data.ChangeTicket, &resp.Diagnostics)
+
+	recordType, record := data.Type.ValueString(), data.Record.ValueString()
+	data.Valid, data.Warnings = d.providerData.checkRecord(
+		func(diags *diag.Diagnostics) { validateTXTRecord(recordType, record, diags) },
+		func(diags *diag.Diagnostics) { data.ParsedJSON = parsedRecordJSON(recordType, record, diags) },
+		&resp.Diagnostics,
+	)
+	if !data.Valid.ValueBool() {
+		resp.Diagnostics.Append(setInvalidRecordState(ctx, req.Config, &resp.State, data.ChangeTicket, data.Valid, data.Warnings)...)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// validateTXTRecord reports syntax problems with a record of the given type.
+// SPF records get the checks of the SPF data source that need no DNS
+// lookups; other types are checked by their parser.
+func validateTXTRecord(recordType, record string, diags *diag.Diagnostics) {
+	name, ok := txtRecordTypes[recordType]
+	if !ok {
+		diags.AddError(
+			summaryTXTInvalidRecordType,
+			fmt.Sprintf("type must be one of %s, got %q.", strings.Join(txtRecordTypeList, ", "), recordType),
+		)
+		return
+	}
+
+	if recordType == "spf" {
+		validateSPFRecord(record, diags)
+		return
+	}
+
+	if _, err := parseTXTRecord(recordType, record); err != nil {
+		diags.AddError(
+			fmt.Sprintf("Invalid %s Record", name),
+			fmt.Sprintf("The %s record is malformed: %s\n\nRecord: %s", name, err.Error(), record),
+		)
+	}
+}
+
+// parsedRecordJSON returns the parsed_json attribute value for a record of the
+// given type. encoding/json sorts map keys, so the same record always encodes
+// to the same string.
+func parsedRecordJSON(recordType, record string, diags *diag.Diagnostics) types.String {
+	parsed, err := parseTXTRecord(recordType, record)
+	if err != nil {
+		diags.AddError(
+			fmt.Sprintf("Invalid %s Record", txtRecordTypes[recordType]),
+			fmt.Sprintf("The %s record is malformed: %s", txtRecordTypes[recordType], err.Error()),
+		)
+		return types.StringNull()
+	}
+	encoded, err := json.Marshal(parsed)
+	if err != nil {
+		diags.AddError(
+			summaryTXTEncodeFailed,
+			fmt.Sprintf("Encoding the parsed record as JSON failed: %s", err.Error()),
+		)
+		return types.StringNull()
+	}
+	return types.StringValue(string(encoded))
+}
+
+// parseTXTRecord parses a record with the parser for its type and returns a
+// JSON-encodable representation of it.
+func parseTXTRecord(recordType, record string) (any, error) {
+	switch recordType {
+	case "spf":
+		parsed, err := spf.ParseSPF(strings.Trim(record, " "))
+		if err != nil {
+			return nil, err
+		}
+		mechanisms := make([]map[string]string, len(parsed.Mechanisms))
+		for i, m := range parsed.Mechanisms {
+			qualifier, mechType, value := parseMechanism(m)
+			mechanisms[i] = map[string]string{"qualifier": qualifier, "type": mechType, "value": value}
+		}
+		return map[string]any{"mechanisms": mechanisms, "redirect": parsed.Redirect, "exp": parsed.Exp}, nil
+	case "dmarc":
+		if _, err := parseDMARCRecord(record); err != nil {
+			return nil, err
+		}
+	case "dkim":
+		parsed, err := ParseDKIM(record)
+		if err != nil {
+			return nil, err
+		}
+		return parsed.Tags, nil
+	case "mta-sts":
+		if _, err := parseMTASTSRecord(record); err != nil {
+			return nil, err
+		}
+	case "tls-rpt":
+		if _, err := parseTLSRPTRecord(record); err != nil {
+			return nil, err
+		}
+	case "bimi":
+		if _, err := parseBIMIRecord(record); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported record type %q", recordType)
+	}
+	return dmarcRawTags(splitDMARCTags(record)), nil
+}
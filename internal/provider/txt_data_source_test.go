@@ -0,0 +1,129 @@
+package provider
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+func TestParseTXTRecord(t *testing.T) {
+	tests := []struct {
+		name       string
+		recordType string
+		record     string
+		want       string
+		wantErr    bool
+	}{
+		{
+			name:       "spf",
+			recordType: "spf",
+			record:     "v=spf1 ip4:192.0.2.0/24 -all",
+			want:       `{"exp":"","mechanisms":[{"qualifier":"+","type":"ip4","value":"192.0.2.0/24"},{"qualifier":"-","type":"all","value":""}],"redirect":""}`,
+		},
+		{
+			name:       "dmarc",
+			recordType: "dmarc",
+			record:     "v=DMARC1; p=reject; rua=mailto:dmarc@example.com",
+			want:       `{"p":"reject","rua":"mailto:dmarc@example.com","v":"DMARC1"}`,
+		},
+		{
+			name:       "dkim",
+			recordType: "dkim",
+			record:     testDKIMEd25519,
+			want:       `{"k":"ed25519","p":"11qYAYKxCrfVS/7TyWQHOg7hcvPapiMlrwIaaPcHURo=","v":"DKIM1"}`,
+		},
+		{
+			name:       "mta-sts",
+			recordType: "mta-sts",
+			record:     "v=STSv1; id=20240101T000000",
+			want:       `{"id":"20240101T000000","v":"STSv1"}`,
+		},
+		{
+			name:       "tls-rpt",
+			recordType: "tls-rpt",
+			record:     "v=TLSRPTv1; rua=mailto:tlsrpt@example.com",
+			want:       `{"rua":"mailto:tlsrpt@example.com","v":"TLSRPTv1"}`,
+		},
+		{
+			name:       "bimi",
+			recordType: "bimi",
+			record:     "v=BIMI1; l=https://example.com/logo.svg",
+			want:       `{"l":"https://example.com/logo.svg","v":"BIMI1"}`,
+		},
+		{name: "invalid spf", recordType: "spf", record: "v=spf1 include -all", wantErr: true},
+		{name: "invalid dmarc", recordType: "dmarc", record: "v=DMARC1; p=block", wantErr: true},
+		{name: "invalid dkim", recordType: "dkim", record: "v=DKIM1; k=dsa; p=abc", wantErr: true},
+		{name: "invalid mta-sts", recordType: "mta-sts", record: "v=STSv1", wantErr: true},
+		{name: "invalid tls-rpt", recordType: "tls-rpt", record: "v=TLSRPTv2; rua=mailto:a@example.com", wantErr: true},
+		{name: "invalid bimi", recordType: "bimi", record: "v=BIMI1; l=http://example.com/logo.svg", wantErr: true},
+		{name: "wrong type", recordType: "dmarc", record: "v=spf1 -all", wantErr: true},
+		{name: "unknown type", recordType: "arc", record: "v=1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed, err := parseTXTRecord(tt.recordType, tt.record)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseTXTRecord() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			got, err := json.Marshal(parsed)
+			if err != nil {
+				t.Fatalf("json.Marshal() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("parseTXTRecord() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateTXTRecord(t *testing.T) {
+	tests := []struct {
+		name       string
+		recordType string
+		record     string
+		want       string
+	}{
+		{name: "valid", recordType: "dmarc", record: "v=DMARC1; p=none"},
+		{name: "invalid", recordType: "bimi", record: "v=BIMI2", want: "Invalid BIMI Record"},
+		{name: "spf checks", recordType: "spf", record: "v=spf1 -all ", want: "SPF Record Has Surrounding Spaces"},
+		{name: "spf ptr", recordType: "spf", record: "v=spf1 ptr -all", want: "Deprecated SPF ptr Mechanism"},
+		{name: "unknown type", recordType: "arc", record: "v=1", want: "Invalid TXT Record Type"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var diags diag.Diagnostics
+			validateTXTRecord(tt.recordType, tt.record, &diags)
+
+			got := ""
+			if len(diags) > 0 {
+				got = diags[0].Summary()
+			}
+			if got != tt.want {
+				t.Errorf("validateTXTRecord() first diagnostic = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsedRecordJSON(t *testing.T) {
+	var diags diag.Diagnostics
+	first := parsedRecordJSON("dmarc", "v=DMARC1; p=reject; rua=mailto:dmarc@example.com; adkim=s", &diags)
+	second := parsedRecordJSON("dmarc", "v=DMARC1; adkim=s; rua=mailto:dmarc@example.com; p=reject", &diags)
+	if diags.HasError() {
+		t.Fatalf("parsedRecordJSON() diagnostics = %v", diags)
+	}
+	if !first.Equal(second) {
+		t.Errorf("tag order changed the encoding: %s and %s", first, second)
+	}
+
+	invalid := parsedRecordJSON("spf", "v=spf1 include -all", &diags)
+	if !invalid.IsNull() || !diags.HasError() {
+		t.Errorf("parsedRecordJSON() of an invalid record = %s, diagnostics %v", invalid, diags)
+	}
+}